@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/adapter/cli"
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/kerimovkk/currency-conversion-utility/internal/infrastructure/config"
+	"github.com/kerimovkk/currency-conversion-utility/internal/usecase"
+)
+
+// shutdownTimeout bounds how long the server waits for open requests on exit
+const shutdownTimeout = 10 * time.Second
+
+// runBatch converts every line of the batch input and presents the results in
+// input order, numbered with their input lines. Lines that fail are reported
+// on stderr without stopping the others; the exit code is 1 when any line
+// failed.
+func runBatch(ctx context.Context, uc *usecase.ConvertCurrencyUseCase, presenter *cli.Presenter, args *cli.Args) int {
+	input := os.Stdin
+	if args.BatchFile != "" && args.BatchFile != "-" {
+		file, err := os.Open(args.BatchFile)
+		if err != nil {
+			presenter.PresentError(err)
+			return cli.ExitFailure
+		}
+		defer file.Close()
+		input = file
+	}
+
+	lines, err := cli.ReadBatch(input)
+	if err != nil {
+		presenter.PresentError(err)
+		return cli.ExitFailure
+	}
+
+	items := make([]usecase.BatchItem, 0, len(lines))
+	for _, line := range lines {
+		if line.Err == nil {
+			items = append(items, usecase.BatchItem{
+				Amount:     line.Amount,
+				FromSymbol: line.FromCurrency,
+				ToSymbol:   line.ToCurrency,
+			})
+		}
+	}
+	converted := uc.ExecuteBatch(ctx, items, args.Workers, usecase.WithAsOf(args.At))
+
+	results := make([]*domain.ConversionResult, 0, len(converted))
+	numbers := make([]int, 0, len(converted))
+	failed := false
+	next := 0
+	for _, line := range lines {
+		err := line.Err
+		if err == nil {
+			outcome := converted[next]
+			next++
+			if outcome.Err == nil {
+				results = append(results, outcome.Result)
+				numbers = append(numbers, line.Number)
+				continue
+			}
+			err = outcome.Err
+		}
+		presenter.PresentLineError(line.Number, err)
+		failed = true
+	}
+
+	presenter.PresentBatch(results, numbers)
+	if failed {
+		return cli.ExitFailure
+	}
+	return cli.ExitOK
+}
+
+// runHistory converts one unit of the source currency at every point in time
+// of the requested range
+func runHistory(ctx context.Context, uc *usecase.ConvertCurrencyUseCase, presenter *cli.Presenter, args *cli.Args) int {
+	var results []*domain.ConversionResult
+	for _, at := range args.HistoryTimes() {
+		points, err := uc.ExecuteMulti(ctx, args.Amount, args.FromCurrency, args.ToCurrencies, usecase.WithAsOf(at))
+		if err != nil {
+			presenter.PresentError(fmt.Errorf("at %s: %w", at.Format(time.RFC3339), err))
+			return cli.ExitFailure
+		}
+		results = append(results, points...)
+	}
+
+	presenter.PresentResults(results)
+	return cli.ExitOK
+}
+
+// runExpression values a sum of amounts in different currencies in each
+// target currency
+func runExpression(ctx context.Context, uc *usecase.ConvertCurrencyUseCase, presenter *cli.Presenter, args *cli.Args) int {
+	totals, err := cli.Evaluate(ctx, uc, args.Expression, args.Rounding, args.Workers, usecase.WithAsOf(args.At))
+	if err != nil {
+		presenter.PresentError(err)
+		return cli.ExitFailure
+	}
+
+	presenter.PresentTotals(totals)
+	return cli.ExitOK
+}
+
+// runServe serves conversions over HTTP until interrupted
+func runServe(uc *usecase.ConvertCurrencyUseCase, presenter *cli.Presenter, args *cli.Args) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{
+		Addr:              args.Addr,
+		Handler:           cli.NewHandler(uc, conversionTimeout),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+	fmt.Fprintf(os.Stderr, "Listening on http://%s\n", args.Addr)
+
+	select {
+	case err := <-serveErr:
+		presenter.PresentError(err)
+		return cli.ExitFailure
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		presenter.PresentError(err)
+		return cli.ExitFailure
+	}
+	return cli.ExitOK
+}
+
+// runList presents the currencies of the built-in registry
+func runList(presenter *cli.Presenter, args *cli.Args) int {
+	var currencies []*domain.Currency
+	for _, currency := range domain.DefaultRegistry().All() {
+		if args.Kind == "" || currency.Kind == args.Kind {
+			currencies = append(currencies, currency)
+		}
+	}
+
+	presenter.PresentCurrencies(currencies)
+	return cli.ExitOK
+}
+
+// runCache lists or clears the files this tool keeps in the cache directory;
+// any other files there are left alone. Clearing keeps the CoinMarketCap
+// credit ledger, which tracks spending against the budgets.
+func runCache(cfg *config.Config, presenter *cli.Presenter, args *cli.Args) int {
+	if cfg.CacheDir == "" {
+		presenter.PresentCache("", nil)
+		return cli.ExitOK
+	}
+
+	entries, err := os.ReadDir(cfg.CacheDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		presenter.PresentError(err)
+		return cli.ExitFailure
+	}
+
+	if args.CacheAction == cli.CacheClear {
+		ledger := filepath.Base(cfg.ProviderConfig(config.DefaultProvider).CreditLedger)
+		var removed []string
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || !config.IsCacheFile(entry.Name()) || entry.Name() == ledger {
+				continue
+			}
+			if err := os.Remove(filepath.Join(cfg.CacheDir, entry.Name())); err != nil {
+				presenter.PresentError(err)
+				return cli.ExitFailure
+			}
+			removed = append(removed, entry.Name())
+		}
+		presenter.PresentCacheCleared(cfg.CacheDir, removed)
+		return cli.ExitOK
+	}
+
+	var files []cli.CacheFile
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || !config.IsCacheFile(entry.Name()) {
+			continue
+		}
+		files = append(files, cli.CacheFile{Name: entry.Name(), Size: info.Size(), Modified: info.ModTime()})
+	}
+	presenter.PresentCache(cfg.CacheDir, files)
+	return cli.ExitOK
+}
+
+// configSettings lists the effective configuration under the names of the
+// environment variables that set it
+func configSettings(cfg *config.Config) []cli.Setting {
+	cmc := cfg.ProviderConfig(config.DefaultProvider)
+	settings := []cli.Setting{
+		{Name: "PROVIDER", Value: cfg.Provider},
+		{Name: "PROVIDER_FALLBACK", Value: strings.Join(cfg.FallbackProviders, ",")},
+		{Name: "FAILOVER_ON", Value: strings.Join(cfg.FailoverOn, ",")},
+		{Name: "PROVIDER_COOLDOWN", Value: cfg.ProviderCooldown.String()},
+		{Name: "CMC_API_KEY", Value: cfg.APIKey, Secret: true},
+		{Name: "CMC_API_URL", Value: cfg.APIURL},
+		{Name: "CMC_REQUESTS_PER_MINUTE", Value: strconv.Itoa(cmc.RequestsPerMinute)},
+		{Name: "CMC_DAILY_CREDIT_BUDGET", Value: strconv.Itoa(cmc.DailyCreditBudget)},
+		{Name: "CMC_MONTHLY_CREDIT_BUDGET", Value: strconv.Itoa(cmc.MonthlyCreditBudget)},
+	}
+
+	names := make([]string, 0, len(cfg.Providers))
+	for name := range cfg.Providers {
+		if name != config.DefaultProvider {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		provider := cfg.ProviderConfig(name)
+		prefix := "PROVIDER_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		settings = append(settings,
+			cli.Setting{Name: prefix + "_API_KEY", Value: provider.APIKey, Secret: true},
+			cli.Setting{Name: prefix + "_API_URL", Value: provider.APIURL},
+		)
+	}
+
+	settings = append(settings,
+		cli.Setting{Name: "CONSENSUS_PROVIDERS", Value: strings.Join(cfg.ConsensusProviders, ",")},
+		cli.Setting{Name: "CONSENSUS_METHOD", Value: cfg.ConsensusMethod},
+		cli.Setting{Name: "CONSENSUS_TOLERANCE", Value: cfg.ConsensusTolerance.String()},
+		cli.Setting{Name: "CONSENSUS_MIN_QUOTES", Value: strconv.Itoa(cfg.ConsensusMinQuotes)},
+		cli.Setting{Name: "BRIDGE_CURRENCIES", Value: strings.Join(cfg.BridgeCurrencies, ",")},
+		cli.Setting{Name: "CACHE_DIR", Value: cfg.CacheDir},
+		cli.Setting{Name: "SYMBOL_MAP_TTL", Value: cfg.SymbolMapTTL.String()},
+		cli.Setting{Name: "RATE_CACHE_TTL", Value: cfg.RateCacheTTL.String()},
+		cli.Setting{Name: "RATE_CACHE_STALE_TTL", Value: cfg.RateCacheStaleTTL.String()},
+		cli.Setting{Name: "RATE_CACHE_PERSIST", Value: strconv.FormatBool(cfg.RateCachePersist)},
+	)
+
+	templates := make([]string, 0, len(cfg.Templates))
+	for name := range cfg.Templates {
+		templates = append(templates, name)
+	}
+	sort.Strings(templates)
+	for _, name := range templates {
+		settings = append(settings, cli.Setting{
+			Name:  "TEMPLATE_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")),
+			Value: cfg.Templates[name],
+		})
+	}
+	return settings
+}
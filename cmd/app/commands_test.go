@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/adapter/cli"
+	"github.com/kerimovkk/currency-conversion-utility/internal/infrastructure/config"
+)
+
+func TestRunCache_ClearKeepsForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"cmc-symbols.json", "rates-coinmarketcap.json", "provider-health.json", "cmc-credits.json", "notes.txt", "rates.csv"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o644))
+	}
+	cfg := &config.Config{
+		CacheDir: dir,
+		Providers: map[string]config.ProviderConfig{
+			config.DefaultProvider: {CreditLedger: filepath.Join(dir, config.CreditLedgerFile)},
+		},
+	}
+
+	var out bytes.Buffer
+	presenter := cli.NewPresenter(false, cli.WithWriters(&out, &bytes.Buffer{}))
+	code := runCache(cfg, presenter, &cli.Args{CacheAction: cli.CacheClear})
+	require.Equal(t, cli.ExitOK, code)
+
+	remaining, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range remaining {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"cmc-credits.json", "notes.txt", "rates.csv"}, names)
+}
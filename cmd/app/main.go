@@ -2,15 +2,27 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/kerimovkk/currency-conversion-utility/internal/adapter/cli"
 	"github.com/kerimovkk/currency-conversion-utility/internal/adapter/repository"
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
 	"github.com/kerimovkk/currency-conversion-utility/internal/infrastructure/config"
 	infrahttp "github.com/kerimovkk/currency-conversion-utility/internal/infrastructure/http"
 	"github.com/kerimovkk/currency-conversion-utility/internal/usecase"
+	"github.com/kerimovkk/currency-conversion-utility/pkg/retry"
+)
+
+const (
+	// conversionTimeout bounds a single conversion, retries included
+	conversionTimeout = 30 * time.Second
+
+	// batchTimeout bounds a whole batch run
+	batchTimeout = 5 * time.Minute
 )
 
 func main() {
@@ -20,58 +32,276 @@ func main() {
 func run() int {
 	// Parse command-line arguments
 	args, err := cli.ParseArgs(os.Args[1:])
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		fmt.Fprintln(os.Stderr, "\nRun 'app --help' for usage information")
-		return 1
+	var usageErr *cli.UsageError
+	if errors.As(err, &usageErr) {
+		cli.NewPresenter(false, cli.WithOutput(usageErr.Output)).PresentUsageError(usageErr)
+		return cli.ExitUsage
 	}
 
-	// Handle --help flag
+	// Handle --help flag and the help command
 	if args.ShowHelp {
-		cli.ShowHelp()
-		return 0
+		if args.Command == "" {
+			cli.ShowHelp()
+		} else {
+			cli.ShowCommandHelp(args.Command)
+		}
+		return cli.ExitOK
 	}
 
-	// Handle --version flag
+	// Handle --version flag and the version command
 	if args.ShowVersion {
 		cli.ShowVersion()
-		return 0
+		return cli.ExitOK
 	}
 
+	// Create presenter
+	presenterOpts := []cli.PresenterOption{cli.WithOutput(args.Output), cli.WithHeader(!args.NoHeader)}
+	presenter := cli.NewPresenter(args.Verbose, presenterOpts...)
+
+	switch args.Command {
+	case cli.CommandList:
+		return runList(presenter, args)
+	case cli.CommandConfig, cli.CommandCache, cli.CommandCredits:
+		return runLocal(presenter, args)
+	default:
+		return runConversion(presenter, presenterOpts, args)
+	}
+}
+
+// runLocal runs the commands that only read the configuration and caches,
+// which work without an API key
+func runLocal(presenter *cli.Presenter, args *cli.Args) int {
+	cfg, err := config.LoadSettings("")
+	if err != nil {
+		return fail(presenter, args, "Configuration error", err)
+	}
+
+	switch args.Command {
+	case cli.CommandConfig:
+		presenter.PresentSettings(configSettings(cfg))
+		return cli.ExitOK
+	case cli.CommandCache:
+		return runCache(cfg, presenter, args)
+	default:
+		cmc := cfg.ProviderConfig(config.DefaultProvider)
+		ledger := repository.NewCreditLedger(cmc.CreditLedger, cmc.DailyCreditBudget, cmc.MonthlyCreditBudget)
+		presenter.PresentCredits(ledger.Usage())
+		return cli.ExitOK
+	}
+}
+
+// runConversion wires the price providers and runs the commands that convert
+func runConversion(presenter *cli.Presenter, presenterOpts []cli.PresenterOption, args *cli.Args) int {
 	// Validate environment variables
-	if err := cli.ValidateEnvironment(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		fmt.Fprintln(os.Stderr, "\nPlease set the CMC_API_KEY environment variable")
-		fmt.Fprintln(os.Stderr, "You can copy .env.example to .env and add your API key")
-		return 1
+	if err := cli.ValidateEnvironment(args.Provider); err != nil {
+		return fail(presenter, args, "Error", err,
+			"\nPlease set the CMC_API_KEY environment variable",
+			"You can copy .env.example to .env and add your API key")
 	}
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.LoadWithProvider(args.Provider)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
-		return 1
+		return fail(presenter, args, "Configuration error", err)
+	}
+
+	// Write results through a user-defined template
+	if args.Format != "" {
+		formatter, err := newTemplateFormatter(cfg, args.Format)
+		if err != nil {
+			return fail(presenter, args, "Error", err)
+		}
+		presenter = cli.NewPresenter(args.Verbose, append(presenterOpts, cli.WithFormatter(formatter))...)
 	}
 
 	// Initialize dependencies (Dependency Injection)
 	httpClient := infrahttp.NewClient()
-	priceRepo := repository.NewCoinMarketCapRepository(httpClient, cfg.APIKey, cfg.APIURL)
-	convertUseCase := usecase.NewConvertCurrencyUseCase(priceRepo)
+	newRepository := newPriceRepository
+	if args.Consensus {
+		newRepository = newConsensusRepository
+	}
+	priceRepo, cmcRepo, err := newRepository(cfg, httpClient)
+	if err != nil {
+		return fail(presenter, args, "Configuration error", err)
+	}
 
-	// Create presenter
-	presenter := cli.NewPresenter(args.Verbose)
+	opts := []usecase.Option{usecase.WithRounding(args.Rounding)}
+
+	// CoinMarketCap symbol maps validate symbols beyond the built-in registry
+	if cmcRepo != nil {
+		resolver := repository.NewSymbolResolver(cmcRepo, cfg.CachePath(config.SymbolMapFile), cfg.SymbolMapTTL)
+		opts = append(opts, usecase.WithCurrencyResolver(resolver))
+	}
+
+	// Bridged routes price each leg through the rate cache below
+	if args.Bridge {
+		opts = append(opts, usecase.WithBridgeCurrencies(cfg.BridgeCurrencies...))
+	}
+
+	// Consensus pricing always asks the providers for fresh quotes
+	if cfg.RateCacheTTL > 0 && !args.Consensus {
+		ratesPath := ""
+		if cfg.RateCachePersist {
+			ratesPath = cfg.CachePath(config.RateCacheFile(cfg.Provider))
+		}
+		rateCache := repository.NewCachingPriceRepository(priceRepo, ratesPath, cfg.RateCacheTTL, cfg.RateCacheStaleTTL)
+		defer rateCache.Close()
+		priceRepo = rateCache
+	}
+
+	convertUseCase := usecase.NewConvertCurrencyUseCase(priceRepo, opts...)
+
+	// The server bounds every request on its own
+	if args.Command == cli.CommandServe {
+		return runServe(convertUseCase, presenter, args)
+	}
 
 	// Execute conversion with timeout context
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	timeout := conversionTimeout
+	if args.Command == cli.CommandBatch || args.Command == cli.CommandHistory {
+		timeout = batchTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	result, err := convertUseCase.Execute(ctx, args.Amount, args.FromCurrency, args.ToCurrency)
+	// Verbose runs show retries as they happen
+	if args.Verbose {
+		ctx = retry.WithOnRetry(ctx, presenter.PresentRetry)
+	}
+
+	switch args.Command {
+	case cli.CommandBatch:
+		return runBatch(ctx, convertUseCase, presenter, args)
+	case cli.CommandHistory:
+		return runHistory(ctx, convertUseCase, presenter, args)
+	}
+	if args.Expression != nil {
+		return runExpression(ctx, convertUseCase, presenter, args)
+	}
+
+	results, err := convertUseCase.ExecuteMulti(ctx, args.Amount, args.FromCurrency, args.ToCurrencies, usecase.WithAsOf(args.At))
 	if err != nil {
 		presenter.PresentError(err)
-		return 1
+		return cli.ExitFailure
+	}
+
+	// Present results
+	presenter.PresentResults(results)
+	return cli.ExitOK
+}
+
+// newTemplateFormatter creates the formatter for a --format value, which is
+// either a template or the name of one configured as TEMPLATE_<NAME>
+func newTemplateFormatter(cfg *config.Config, format string) (cli.Formatter, error) {
+	text, err := cli.ResolveTemplate(format, cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+	return cli.NewTemplateFormatter(text)
+}
+
+// fail reports a setup error and returns the exit code. JSON output gets the
+// error object on stderr; text output gets prefix, the error and any hints.
+func fail(presenter *cli.Presenter, args *cli.Args, prefix string, err error, hints ...string) int {
+	if args.Output == cli.OutputJSON {
+		presenter.PresentError(err)
+		return cli.ExitFailure
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: %v\n", prefix, err)
+	for _, hint := range hints {
+		fmt.Fprintln(os.Stderr, hint)
+	}
+	return cli.ExitFailure
+}
+
+// providerSettings maps the configuration of the named provider onto the
+// settings its repository factory takes
+func providerSettings(cfg *config.Config, name string) repository.ProviderSettings {
+	provider := cfg.ProviderConfig(name)
+	return repository.ProviderSettings{
+		APIKey:              provider.APIKey,
+		APIURL:              provider.APIURL,
+		RequestsPerMinute:   provider.RequestsPerMinute,
+		CreditLedger:        provider.CreditLedger,
+		DailyCreditBudget:   provider.DailyCreditBudget,
+		MonthlyCreditBudget: provider.MonthlyCreditBudget,
+	}
+}
+
+// newPriceRepository creates the selected provider, followed by the fallback
+// providers when any are configured. It also returns the CoinMarketCap
+// repository of the chain, if there is one, for symbol resolution.
+func newPriceRepository(
+	cfg *config.Config,
+	httpClient *http.Client,
+) (domain.PriceRepository, *repository.CoinMarketCapRepository, error) {
+	var chain []repository.NamedProvider
+	var cmcRepo *repository.CoinMarketCapRepository
+
+	for _, name := range append([]string{cfg.Provider}, cfg.FallbackProviders...) {
+		repo, err := repository.NewProvider(name, httpClient, providerSettings(cfg, name))
+		if err != nil {
+			return nil, nil, fmt.Errorf("provider %s: %w", name, err)
+		}
+		if cmc, ok := repo.(*repository.CoinMarketCapRepository); ok && cmcRepo == nil {
+			cmcRepo = cmc
+		}
+		chain = append(chain, repository.NamedProvider{Name: name, Repository: repo})
+	}
+
+	if len(chain) == 1 {
+		return chain[0].Repository, cmcRepo, nil
+	}
+
+	opts := []repository.FallbackOption{
+		repository.WithUnhealthyCooldown(cfg.ProviderCooldown),
+		repository.WithHealthState(cfg.CachePath(config.ProviderHealthFile)),
+	}
+	if len(cfg.FailoverOn) > 0 {
+		failoverOn, err := repository.FailoverErrorsFromCodes(cfg.FailoverOn)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, repository.WithFailoverErrors(failoverOn...))
+	}
+
+	return repository.NewFallbackPriceRepository(chain, opts...), cmcRepo, nil
+}
+
+// newConsensusRepository creates a consensus over the providers in
+// CONSENSUS_PROVIDERS. It also returns their CoinMarketCap repository, if
+// there is one, for symbol resolution.
+func newConsensusRepository(
+	cfg *config.Config,
+	httpClient *http.Client,
+) (domain.PriceRepository, *repository.CoinMarketCapRepository, error) {
+	if len(cfg.ConsensusProviders) < cfg.ConsensusMinQuotes {
+		return nil, nil, fmt.Errorf("CONSENSUS_PROVIDERS must list at least %d providers", cfg.ConsensusMinQuotes)
+	}
+
+	method, err := repository.ParseConsensusMethod(cfg.ConsensusMethod)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var providers []repository.NamedProvider
+	var cmcRepo *repository.CoinMarketCapRepository
+	for _, name := range cfg.ConsensusProviders {
+		repo, err := repository.NewProvider(name, httpClient, providerSettings(cfg, name))
+		if err != nil {
+			return nil, nil, fmt.Errorf("provider %s: %w", name, err)
+		}
+		if cmc, ok := repo.(*repository.CoinMarketCapRepository); ok && cmcRepo == nil {
+			cmcRepo = cmc
+		}
+		providers = append(providers, repository.NamedProvider{Name: name, Repository: repo})
 	}
 
-	// Present result
-	presenter.PresentResult(result)
-	return 0
+	return repository.NewConsensusPriceRepository(
+		providers,
+		repository.WithConsensusMethod(method),
+		repository.WithTolerance(cfg.ConsensusTolerance),
+		repository.WithMinQuotes(cfg.ConsensusMinQuotes),
+	), cmcRepo, nil
 }
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// maxBatchLine is the longest batch input line accepted
+const maxBatchLine = 64 * 1024
+
+// BatchLine is one conversion read from batch input
+type BatchLine struct {
+	// Number is the 1-based line number in the input
+	Number int
+
+	Amount       domain.Amount
+	FromCurrency string
+	ToCurrency   string
+
+	// Err is set when the line could not be parsed
+	Err error
+}
+
+// ReadBatch reads conversions from r, one per line, either as
+// whitespace-separated "amount from to" or as CSV rows "amount,from,to".
+// Blank lines and lines starting with # are skipped, as is a leading CSV
+// header row. Malformed lines are returned with Err set so the rest of the
+// batch can still be converted; only read failures are returned as an error.
+func ReadBatch(r io.Reader) ([]BatchLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxBatchLine)
+
+	var lines []BatchLine
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields, err := splitBatchLine(text)
+		if err == nil && len(lines) == 0 && isBatchHeader(fields) {
+			continue
+		}
+		lines = append(lines, parseBatchLine(number, fields, err))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading batch input: %w", err)
+	}
+	return lines, nil
+}
+
+// splitBatchLine splits a CSV row, or a whitespace-separated line when it
+// contains no comma
+func splitBatchLine(text string) ([]string, error) {
+	if !strings.Contains(text, ",") {
+		return strings.Fields(text), nil
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.TrimLeadingSpace = true
+	fields, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	for i, field := range fields {
+		fields[i] = strings.TrimSpace(field)
+	}
+	return fields, nil
+}
+
+// isBatchHeader reports whether fields are a header row such as
+// "amount,from,to"
+func isBatchHeader(fields []string) bool {
+	return len(fields) > 0 && strings.EqualFold(fields[0], "amount")
+}
+
+// parseBatchLine validates the fields of one line
+func parseBatchLine(number int, fields []string, err error) BatchLine {
+	line := BatchLine{Number: number}
+	if err != nil {
+		line.Err = fmt.Errorf("%w: %v", domain.ErrMalformedInput, err)
+		return line
+	}
+	if len(fields) != 3 {
+		line.Err = fmt.Errorf("%w: expected 3 fields (amount, from_currency, to_currency), got %d", domain.ErrMalformedInput, len(fields))
+		return line
+	}
+
+	amount, err := domain.ParseAmount(fields[0])
+	if err != nil {
+		line.Err = fmt.Errorf("invalid amount '%s': %w", fields[0], err)
+		return line
+	}
+	if amount.Sign() <= 0 {
+		line.Err = fmt.Errorf("invalid amount '%s': %w", fields[0], domain.ErrInvalidAmount)
+		return line
+	}
+
+	line.Amount = amount
+	line.FromCurrency = fields[1]
+	line.ToCurrency = fields[2]
+	return line
+}
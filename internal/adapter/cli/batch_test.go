@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+func TestReadBatch(t *testing.T) {
+	input := strings.Join([]string{
+		"amount,from,to",
+		"100,USD,BTC",
+		"",
+		"# invoices from March",
+		"  2.5   ETH   EUR  ",
+		"1.5, btc , \"USD\"",
+		"0,USD,EUR",
+		"abc USD EUR",
+		"1 USD",
+		"1,\"USD,EUR",
+	}, "\n")
+
+	lines, err := ReadBatch(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, lines, 7)
+
+	assert.Equal(t, BatchLine{Number: 2, Amount: domain.MustParseAmount("100"), FromCurrency: "USD", ToCurrency: "BTC"}, lines[0])
+	assert.Equal(t, BatchLine{Number: 5, Amount: domain.MustParseAmount("2.5"), FromCurrency: "ETH", ToCurrency: "EUR"}, lines[1])
+	assert.Equal(t, BatchLine{Number: 6, Amount: domain.MustParseAmount("1.5"), FromCurrency: "btc", ToCurrency: "USD"}, lines[2])
+
+	assert.Equal(t, 7, lines[3].Number)
+	assert.ErrorIs(t, lines[3].Err, domain.ErrInvalidAmount)
+	assert.Equal(t, 8, lines[4].Number)
+	assert.ErrorIs(t, lines[4].Err, domain.ErrMalformedAmount)
+	assert.Equal(t, 9, lines[5].Number)
+	assert.ErrorIs(t, lines[5].Err, domain.ErrMalformedInput)
+	assert.Equal(t, 10, lines[6].Number)
+	assert.ErrorIs(t, lines[6].Err, domain.ErrMalformedInput)
+}
+
+func TestReadBatch_HeaderOnlyFirst(t *testing.T) {
+	lines, err := ReadBatch(strings.NewReader("1 BTC USD\namount from to\n"))
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.ErrorIs(t, lines[1].Err, domain.ErrMalformedAmount)
+}
+
+func TestReadBatch_ReadError(t *testing.T) {
+	_, err := ReadBatch(errReader{})
+	assert.ErrorContains(t, err, "reading batch input")
+}
+
+// errReader fails every read
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
@@ -0,0 +1,699 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// Command names
+const (
+	CommandConvert = "convert"
+	CommandRates   = "rates"
+	CommandHistory = "history"
+	CommandBatch   = "batch"
+	CommandList    = "list"
+	CommandServe   = "serve"
+	CommandConfig  = "config"
+	CommandCache   = "cache"
+	CommandCredits = "credits"
+	CommandHelp    = "help"
+	CommandVersion = "version"
+)
+
+// Cache command actions
+const (
+	CacheInfo  = "info"
+	CacheClear = "clear"
+)
+
+const (
+	// DefaultServeAddr is where the serve command listens by default
+	DefaultServeAddr = "127.0.0.1:8080"
+
+	// defaultRateTargets are quoted by the rates command when no targets are given
+	defaultRateTargets = "USD,EUR,GBP,JPY,BTC,ETH"
+
+	// maxHistoryPoints bounds the API calls of one history command
+	maxHistoryPoints = 366
+)
+
+// flagGroup selects a set of related flags a command accepts
+type flagGroup int
+
+const (
+	// flagsConversion are --rounding, --precision, --provider, --consensus and --bridge
+	flagsConversion flagGroup = 1 << iota
+	// flagsAt is --at
+	flagsAt
+	// flagsOutput are --output with every format, --no-header and --format
+	flagsOutput
+	// flagsJSON is --output limited to text and json
+	flagsJSON
+	// flagsWorkers is --workers
+	flagsWorkers
+	// flagsHistory are --start, --end and --step
+	flagsHistory
+	// flagsServe is --addr
+	flagsServe
+	// flagsVersion is --version
+	flagsVersion
+)
+
+// command describes one command of the app
+type command struct {
+	name    string
+	usage   string
+	summary string
+
+	// description and examples are shown by "app help <command>"
+	description []string
+	examples    []string
+
+	flags flagGroup
+
+	// args validates the positional arguments into a
+	args func(a *Args, positional []string) error
+}
+
+// commands lists every command in the order help shows them
+var commands = []*command{
+	{
+		name:    CommandConvert,
+		usage:   "<amount> <from_currency> <to_currency>[,<to_currency>...]",
+		summary: "Convert an amount into one or more currencies (the default command)",
+		description: []string{
+			"Converts amount of from_currency into to_currency, or into each of a",
+			"comma-separated list of targets priced in a single API call. Currencies",
+			"may also be given as an explicit CoinMarketCap asset identifier to",
+			"disambiguate shared tickers: id:1027 or slug:ethereum.",
+			"",
+			"Conversions may also be written the way they are said: 100 usd to btc,",
+			"'$100 in BTC', 1.5k EUR in JPY (k, m and b multiply by a thousand, a",
+			"million and a billion), 1e-3 BTC→USD. Signs such as € £ ¥ ₿ stand for",
+			"their currency; input that could be read more than one way, such as",
+			"1,500 or a sign used by several currencies, is rejected.",
+			"",
+			"Amounts in different currencies may be added, subtracted and multiplied",
+			"by numbers: 0.5 BTC + 2 ETH - 300 USD in EUR prints the total, and",
+			"--verbose adds the value of every term. Each pair is priced once;",
+			"expressions support --output text or json.",
+			"",
+			"The command name may be left out: app 100 USD BTC is app convert 100 USD BTC.",
+		},
+		examples: []string{
+			"app 123.45 USD BTC",
+			"app --verbose 100 BTC USD",
+			"app convert 1 BTC USD,EUR,GBP,JPY",
+			"app 100 usd to btc",
+			"app '$100 in BTC'",
+			"app 1.5k EUR in JPY",
+			"app --verbose '0.5 BTC + 2 ETH - 300 USD in EUR'",
+			"app --rounding down --precision 4 1 BTC USD",
+			"app --verbose 2 id:1027 EUR",
+			"app --at 2024-03-01 2 ETH EUR",
+			"app --provider ecb 100 USD CHF",
+			"app --consensus --verbose 1000000 EUR USD",
+			"app --bridge --verbose 1000 DOGE CHF",
+			"app --output json 1 BTC USD,EUR",
+			"app --format '≈ {{symbol .ToCurrency}}{{number \"en\" .ConvertedAmount}}' 1 BTC EUR",
+		},
+		flags: flagsConversion | flagsAt | flagsOutput | flagsVersion,
+		args:  convertArgs,
+	},
+	{
+		name:    CommandRates,
+		usage:   "<base_currency> [<currency>,...]",
+		summary: "Show the exchange rates of a currency",
+		description: []string{
+			"Shows what one unit of base_currency is worth in each listed currency,",
+			"or in " + defaultRateTargets + " when none are listed.",
+		},
+		examples: []string{
+			"app rates BTC",
+			"app rates --output table EUR USD,GBP,CHF",
+		},
+		flags: flagsConversion | flagsAt | flagsOutput,
+		args:  ratesArgs,
+	},
+	{
+		name:    CommandHistory,
+		usage:   "--start <time> <from_currency> <to_currency>[,<to_currency>...]",
+		summary: "Show historical exchange rates over a time range",
+		description: []string{
+			"Shows what one unit of from_currency was worth in to_currency at every",
+			fmt.Sprintf("--step from --start to --end, at most %d points.", maxHistoryPoints),
+		},
+		examples: []string{
+			"app history --start 2024-03-01 --end 2024-03-07 BTC USD",
+			"app history --start 2024-01-01 --step 168h --output csv ETH EUR,USD",
+		},
+		flags: flagsConversion | flagsHistory | flagsOutput,
+		args:  historyArgs,
+	},
+	{
+		name:    CommandBatch,
+		usage:   "[file]",
+		summary: "Convert every line of a file or stdin",
+		description: []string{
+			"Converts every line of file, or of stdin when no file or - is given.",
+			"Lines are \"amount from to\" or CSV rows amount,from,to; blank lines,",
+			"lines starting with # and a leading header row are skipped. Lines that",
+			"fail are reported on stderr and make the exit code 1.",
+		},
+		examples: []string{
+			"app batch invoices.csv",
+			"app batch --output csv --workers 8 < invoices.txt",
+		},
+		flags: flagsConversion | flagsAt | flagsOutput | flagsWorkers,
+		args:  batchArgs,
+	},
+	{
+		name:    CommandList,
+		usage:   "[fiat|crypto]",
+		summary: "List the known currencies",
+		description: []string{
+			"Lists the currencies of the built-in registry with their name, kind,",
+			"decimals and sign. Other CoinMarketCap symbols are accepted too.",
+		},
+		examples: []string{
+			"app list",
+			"app list --output json crypto",
+		},
+		flags: flagsJSON,
+		args:  listArgs,
+	},
+	{
+		name:    CommandServe,
+		usage:   "",
+		summary: "Serve conversions over HTTP",
+		description: []string{
+			"Serves conversions as JSON documents in the format of --output json:",
+			"  GET /convert?amount=100&from=USD&to=BTC,EUR[&at=2024-03-01]",
+			"  GET /rates?base=BTC[&to=USD,EUR][&at=2024-03-01]",
+			"  GET /healthz",
+			"Errors are {\"error\":{\"code\":...,\"message\":...}} with a matching HTTP status.",
+		},
+		examples: []string{
+			"app serve",
+			"app serve --addr :8080 --provider ecb",
+		},
+		flags: flagsConversion | flagsServe,
+		args:  noArgs,
+	},
+	{
+		name:    CommandConfig,
+		usage:   "",
+		summary: "Show the effective configuration",
+		description: []string{
+			"Shows the configuration read from the environment and .env, with",
+			"defaults filled in and API keys masked.",
+		},
+		examples: []string{"app config"},
+		flags:    flagsJSON,
+		args:     noArgs,
+	},
+	{
+		name:    CommandCache,
+		usage:   "[info|clear]",
+		summary: "Show or clear the persistent caches",
+		description: []string{
+			"info (the default) lists the files in the cache directory; clear removes",
+			"the cached symbol maps, exchange rates and provider health. The",
+			"CoinMarketCap credit ledger is kept so budgets stay accurate.",
+		},
+		examples: []string{"app cache", "app cache clear"},
+		flags:    flagsJSON,
+		args:     cacheArgs,
+	},
+	{
+		name:        CommandCredits,
+		usage:       "",
+		summary:     "Show CoinMarketCap API credits used today and this month",
+		description: []string{"Shows the API credits spent against CMC_DAILY_CREDIT_BUDGET and CMC_MONTHLY_CREDIT_BUDGET."},
+		examples:    []string{"app credits", "app credits --output json"},
+		flags:       flagsJSON,
+		args:        noArgs,
+	},
+	{
+		name:     CommandHelp,
+		usage:    "[command]",
+		summary:  "Show help for the app or a command",
+		examples: []string{"app help", "app help history"},
+		args:     helpArgs,
+	},
+	{
+		name:     CommandVersion,
+		usage:    "",
+		summary:  "Show version information",
+		examples: []string{"app version"},
+		args:     versionArgs,
+	},
+}
+
+// commandByName returns the command called name, or nil
+func commandByName(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// commandIndex returns the position of the command name in args, or -1 when
+// the first argument that is not a flag or flag value is not a command name
+func commandIndex(args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || arg == "-" || !strings.HasPrefix(arg, "-") {
+			if commandByName(arg) != nil {
+				return i
+			}
+			return -1
+		}
+		name := strings.TrimLeft(arg, "-")
+		if !strings.Contains(name, "=") && takesValue(name) {
+			i++
+		}
+	}
+	return -1
+}
+
+// takesValue reports whether the flag called name is followed by a value
+func takesValue(name string) bool {
+	fs, _ := newFlagSet("", ^flagsJSON)
+	f := fs.Lookup(name)
+	if f == nil {
+		return false
+	}
+	boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return !ok || !boolFlag.IsBoolFlag()
+}
+
+// flagValues receives the values of every flag a command may accept
+type flagValues struct {
+	help      bool
+	version   bool
+	verbose   bool
+	rounding  string
+	precision int
+	provider  string
+	consensus bool
+	bridge    bool
+	at        string
+	output    string
+	noHeader  bool
+	format    string
+	workers   int
+	start     string
+	end       string
+	step      time.Duration
+	addr      string
+}
+
+// newFlagSet creates a flag set with --help, --verbose and the flags of groups
+func newFlagSet(name string, groups flagGroup) (*flag.FlagSet, *flagValues) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	v := &flagValues{precision: -1, rounding: domain.RoundHalfEven.String(), output: string(OutputText)}
+
+	fs.BoolVar(&v.help, "help", false, "Show help")
+	fs.BoolVar(&v.verbose, "verbose", false, "Enable verbose output with detailed information")
+	if groups&flagsVersion != 0 {
+		fs.BoolVar(&v.version, "version", false, "Show version information")
+	}
+	if groups&flagsConversion != 0 {
+		fs.StringVar(&v.rounding, "rounding", v.rounding, "Rounding `mode` for converted amounts: half-even, half-up, down, up, ceiling or floor")
+		fs.IntVar(&v.precision, "precision", v.precision, "Decimal `places` of converted amounts (default: minor units or decimals of the target currency)")
+		fs.StringVar(&v.provider, "provider", "", "Price `provider` to query: coinmarketcap or ecb (default: $PROVIDER or coinmarketcap)")
+		fs.BoolVar(&v.consensus, "consensus", false, "Query every provider in $CONSENSUS_PROVIDERS concurrently and use the median rate, discarding outliers")
+		fs.BoolVar(&v.bridge, "bridge", false, "Route pairs without a direct quote through $BRIDGE_CURRENCIES (USD,USDT,BTC,EUR)")
+	}
+	if groups&flagsAt != 0 {
+		fs.StringVar(&v.at, "at", "", "Convert at historical prices for `time`, given as RFC3339 (2024-03-01T15:04:05Z) or a date (2024-03-01, midnight UTC)")
+	}
+	if groups&flagsOutput != 0 {
+		fs.StringVar(&v.output, "output", v.output, "Output `format`: text, json, yaml, csv, tsv or table; with json, errors are written to stderr as {\"error\":{\"code\":...,\"message\":...}}")
+		fs.BoolVar(&v.noHeader, "no-header", false, "Leave out the header row of csv, tsv and table output")
+		fs.StringVar(&v.format, "format", "", "Write each result with a Go `template`, or with the template of that name in TEMPLATE_<NAME>; helpers: round, number, amount, symbol, time, utc")
+	} else if groups&flagsJSON != 0 {
+		fs.StringVar(&v.output, "output", v.output, "Output `format`: text or json")
+	}
+	if groups&flagsWorkers != 0 {
+		fs.IntVar(&v.workers, "workers", 0, "Currency pairs looked up concurrently (default 4)")
+	}
+	if groups&flagsHistory != 0 {
+		fs.StringVar(&v.start, "start", "", "First point in `time`, given as RFC3339 or a date (required)")
+		fs.StringVar(&v.end, "end", "", "Last point in `time`, given as RFC3339 or a date (default: today)")
+		fs.DurationVar(&v.step, "step", 24*time.Hour, "Time `interval` between points, such as 1h or 168h")
+	}
+	if groups&flagsServe != 0 {
+		fs.StringVar(&v.addr, "addr", DefaultServeAddr, "Listen on `address`, such as :8080")
+	}
+	return fs, v
+}
+
+// parse parses the flags and arguments that follow the command name. Errors
+// are returned as a *UsageError carrying the --output format requested.
+func (c *command) parse(args []string) (*Args, error) {
+	fs, v := newFlagSet(c.name, c.flags)
+	result, err := c.parseFlags(fs, v, args)
+	if err != nil {
+		return nil, &UsageError{Command: c.name, Output: v.errorOutput(), Err: err}
+	}
+	return result, nil
+}
+
+// parseFlags parses args into v and validates them
+func (c *command) parseFlags(fs *flag.FlagSet, v *flagValues, args []string) (*Args, error) {
+
+	// Flags may be mixed with the arguments until a "--" ends them
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return &Args{Command: c.name, ShowHelp: true, Output: OutputText}, nil
+			}
+			return nil, err
+		}
+
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		if len(rest) == 0 {
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+
+	result, err := v.build(c)
+	if err != nil {
+		return nil, err
+	}
+	if result.ShowHelp || result.ShowVersion {
+		return result, nil
+	}
+	if err := c.args(result, positional); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// errorOutput returns the --output format errors are written in, text when
+// the value is invalid
+func (v *flagValues) errorOutput() OutputFormat {
+	format, err := ParseOutputFormat(v.output)
+	if err != nil {
+		return OutputText
+	}
+	return format
+}
+
+// build validates the flag values of command c
+func (v *flagValues) build(c *command) (*Args, error) {
+	result := &Args{
+		Command:     c.name,
+		Verbose:     v.verbose,
+		ShowHelp:    v.help,
+		ShowVersion: v.version,
+		Provider:    strings.ToLower(strings.TrimSpace(v.provider)),
+		Consensus:   v.consensus,
+		Bridge:      v.bridge,
+		NoHeader:    v.noHeader,
+		Format:      v.format,
+		Workers:     v.workers,
+		Addr:        v.addr,
+	}
+
+	// Build rounding policy
+	mode, err := domain.ParseRoundingMode(v.rounding)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.workers < 0 {
+		return nil, fmt.Errorf("invalid workers '%d': must be zero or greater", v.workers)
+	}
+
+	if v.precision < -1 {
+		return nil, fmt.Errorf("invalid precision '%d': must be zero or greater", v.precision)
+	}
+
+	result.Output, err = ParseOutputFormat(v.output)
+	if err != nil {
+		return nil, err
+	}
+	if c.flags&flagsJSON != 0 && result.Output != OutputText && result.Output != OutputJSON {
+		return nil, fmt.Errorf("invalid output format '%s': %s supports text or json", result.Output, c.name)
+	}
+	if result.Format != "" && result.Output != OutputText {
+		return nil, fmt.Errorf("--format cannot be combined with --output %s", result.Output)
+	}
+
+	result.Rounding = domain.NewRoundingPolicy(mode)
+	if v.precision >= 0 {
+		result.Rounding = result.Rounding.WithPrecision(int32(v.precision))
+	}
+
+	if v.at != "" {
+		result.At, err = parseAt(v.at)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.flags&flagsHistory != 0 {
+		if err := v.buildHistory(result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// buildHistory validates the time range of the history command
+func (v *flagValues) buildHistory(result *Args) error {
+	if v.help {
+		return nil
+	}
+	if v.start == "" {
+		return errors.New("--start is required")
+	}
+
+	var err error
+	if result.Start, err = parseAt(v.start); err != nil {
+		return err
+	}
+	result.End = time.Now().UTC().Truncate(24 * time.Hour)
+	if v.end != "" {
+		if result.End, err = parseAt(v.end); err != nil {
+			return err
+		}
+	}
+
+	if v.step <= 0 {
+		return fmt.Errorf("invalid step '%s': must be greater than zero", v.step)
+	}
+	result.Step = v.step
+
+	if result.Start.After(result.End) {
+		return fmt.Errorf("invalid time range: --start %s is after --end %s", v.start, result.End.Format(time.RFC3339))
+	}
+	if points := result.End.Sub(result.Start)/result.Step + 1; points > maxHistoryPoints {
+		return fmt.Errorf("invalid time range: %d points exceed the limit of %d, use a shorter range or a longer --step", points, maxHistoryPoints)
+	}
+	return nil
+}
+
+// isUnknownWord reports whether arg is a plain word that names neither a
+// currency of the registry nor a connector
+func isUnknownWord(arg string) bool {
+	for _, r := range arg {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	_, known := domain.DefaultRegistry().Lookup(strings.ToUpper(arg))
+	return arg != "" && !known && !connectors[strings.ToLower(arg)]
+}
+
+// convertArgs reads a conversion such as "100 USD BTC,EUR", "100 usd to btc"
+// or "$100 in BTC", or an expression such as "0.5 BTC + 2 ETH in EUR", given
+// as one or several arguments
+func convertArgs(a *Args, positional []string) error {
+	if len(positional) == 0 {
+		return fmt.Errorf("invalid number of arguments: expected 3 (amount, from_currency, to_currency), got 0")
+	}
+
+	expr, err := ParseExpression(strings.Join(positional, " "))
+	if err != nil {
+		// A conversion that starts with a word other than a currency is more
+		// likely a mistyped command
+		if word := positional[0]; isUnknownWord(word) {
+			return fmt.Errorf("unknown command or currency '%s'; run 'app help' for the commands", word)
+		}
+		return err
+	}
+	a.ToCurrencies = expr.ToCurrencies
+
+	if query, ok := expr.Query(); ok {
+		a.Amount = query.Amount
+		a.FromCurrency = query.FromCurrency
+		return nil
+	}
+
+	// Totals are not conversion results, so they have no rows or template fields
+	if a.Output != OutputText && a.Output != OutputJSON {
+		return fmt.Errorf("invalid output format '%s': expressions support text or json", a.Output)
+	}
+	if a.Format != "" {
+		return errors.New("--format cannot be combined with an expression")
+	}
+	a.Expression = expr
+	return nil
+}
+
+// ratesArgs reads "<base_currency> [<currency>,...]" and quotes one unit of the base
+func ratesArgs(a *Args, positional []string) error {
+	if len(positional) < 1 || len(positional) > 2 {
+		return fmt.Errorf("invalid number of arguments: expected a base currency and optional targets, got %d", len(positional))
+	}
+
+	a.Amount = domain.NewAmountFromInt(1)
+	a.FromCurrency = positional[0]
+	if len(positional) == 2 {
+		targets, err := parseTargets(positional[1])
+		if err != nil {
+			return err
+		}
+		a.ToCurrencies = targets
+		return nil
+	}
+	a.ToCurrencies = rateTargets(a.FromCurrency)
+	return nil
+}
+
+// rateTargets returns the default rate targets other than base
+func rateTargets(base string) []string {
+	var targets []string
+	for _, target := range strings.Split(defaultRateTargets, ",") {
+		if !strings.EqualFold(target, base) {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// historyArgs reads "<from_currency> <to_currency>[,...]" and quotes one unit
+// of the source currency. Text output without --format shows the date of
+// every point.
+func historyArgs(a *Args, positional []string) error {
+	if len(positional) != 2 {
+		return fmt.Errorf("invalid number of arguments: expected 2 (from_currency, to_currency), got %d", len(positional))
+	}
+
+	targets, err := parseTargets(positional[1])
+	if err != nil {
+		return err
+	}
+
+	a.Amount = domain.NewAmountFromInt(1)
+	a.FromCurrency = positional[0]
+	a.ToCurrencies = targets
+
+	if a.Output == OutputText && a.Format == "" && !a.Verbose {
+		layout := "date"
+		if a.Step%(24*time.Hour) != 0 || !a.Start.Equal(a.Start.Truncate(24*time.Hour)) {
+			layout = "datetime"
+		}
+		a.Format = fmt.Sprintf(`{{time %q .AsOf}}  {{.OriginalAmount}} {{.FromCurrency}} = {{amount .ConvertedAmount .ToCurrency}} {{.ToCurrency}}`, layout)
+	}
+	return nil
+}
+
+// batchArgs reads an optional file name
+func batchArgs(a *Args, positional []string) error {
+	if len(positional) > 1 {
+		return fmt.Errorf("invalid number of arguments: batch takes at most one file, got %d", len(positional))
+	}
+	if len(positional) == 1 {
+		a.BatchFile = positional[0]
+	}
+	return nil
+}
+
+// listArgs reads an optional currency kind
+func listArgs(a *Args, positional []string) error {
+	if len(positional) > 1 {
+		return fmt.Errorf("invalid number of arguments: list takes at most one kind, got %d", len(positional))
+	}
+	if len(positional) == 1 {
+		switch kind := domain.CurrencyKind(strings.ToLower(positional[0])); kind {
+		case domain.CurrencyKindFiat, domain.CurrencyKindCrypto:
+			a.Kind = kind
+		default:
+			return fmt.Errorf("invalid currency kind '%s': expected fiat or crypto", positional[0])
+		}
+	}
+	return nil
+}
+
+// cacheArgs reads an optional cache action
+func cacheArgs(a *Args, positional []string) error {
+	if len(positional) > 1 {
+		return fmt.Errorf("invalid number of arguments: cache takes at most one action, got %d", len(positional))
+	}
+
+	a.CacheAction = CacheInfo
+	if len(positional) == 1 {
+		switch action := strings.ToLower(positional[0]); action {
+		case CacheInfo, CacheClear:
+			a.CacheAction = action
+		default:
+			return fmt.Errorf("invalid cache action '%s': expected info or clear", positional[0])
+		}
+	}
+	return nil
+}
+
+// helpArgs reads the optional command to describe
+func helpArgs(a *Args, positional []string) error {
+	if len(positional) > 1 {
+		return fmt.Errorf("invalid number of arguments: help takes at most one command, got %d", len(positional))
+	}
+
+	// ParseArgs checks that the command exists
+	a.Command = ""
+	a.ShowHelp = true
+	if len(positional) == 1 {
+		a.Command = positional[0]
+	}
+	return nil
+}
+
+// versionArgs turns the version command into --version
+func versionArgs(a *Args, positional []string) error {
+	if err := noArgs(a, positional); err != nil {
+		return err
+	}
+	a.Command = ""
+	a.ShowVersion = true
+	return nil
+}
+
+// noArgs rejects positional arguments
+func noArgs(a *Args, positional []string) error {
+	if len(positional) > 0 {
+		return fmt.Errorf("invalid number of arguments: %s takes none, got %d", a.Command, len(positional))
+	}
+	return nil
+}
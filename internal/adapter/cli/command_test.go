@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+func TestParseArgs_History(t *testing.T) {
+	t.Run("daily points", func(t *testing.T) {
+		args, err := ParseArgs([]string{"history", "--start", "2024-03-01", "--end", "2024-03-03", "BTC", "USD,EUR"})
+		require.NoError(t, err)
+
+		assert.Equal(t, CommandHistory, args.Command)
+		assert.Equal(t, "1", args.Amount.String())
+		assert.Equal(t, []string{"USD", "EUR"}, args.ToCurrencies)
+		assert.Equal(t, []time.Time{
+			time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+		}, args.HistoryTimes())
+		assert.Contains(t, args.Format, `{{time "date" .AsOf}}`)
+	})
+
+	t.Run("hourly points show the time of day", func(t *testing.T) {
+		args, err := ParseArgs([]string{"history", "--start", "2024-03-01", "--end", "2024-03-01T03:00:00Z", "--step", "1h", "BTC", "USD"})
+		require.NoError(t, err)
+
+		assert.Len(t, args.HistoryTimes(), 4)
+		assert.Contains(t, args.Format, `{{time "datetime" .AsOf}}`)
+	})
+
+	t.Run("end defaults to today", func(t *testing.T) {
+		start := time.Now().UTC().AddDate(0, 0, -2).Format(time.DateOnly)
+		args, err := ParseArgs([]string{"history", "--start", start, "BTC", "USD"})
+		require.NoError(t, err)
+		assert.Len(t, args.HistoryTimes(), 3)
+	})
+
+	t.Run("structured output keeps the formatter", func(t *testing.T) {
+		args, err := ParseArgs([]string{"history", "--start", "2024-03-01", "--end", "2024-03-02", "--output", "csv", "BTC", "USD"})
+		require.NoError(t, err)
+		assert.Empty(t, args.Format)
+	})
+
+	errorCases := map[string][]string{
+		"missing start":     {"history", "BTC", "USD"},
+		"start after end":   {"history", "--start", "2024-03-02", "--end", "2024-03-01", "BTC", "USD"},
+		"zero step":         {"history", "--start", "2024-03-01", "--end", "2024-03-02", "--step", "0s", "BTC", "USD"},
+		"too many points":   {"history", "--start", "2020-01-01", "--end", "2024-01-01", "BTC", "USD"},
+		"missing target":    {"history", "--start", "2024-03-01", "--end", "2024-03-02", "BTC"},
+		"at is not allowed": {"history", "--at", "2024-03-01", "--start", "2024-03-01", "BTC", "USD"},
+	}
+	for name, args := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseArgs(args)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseArgs_InterspersedFlags(t *testing.T) {
+	args, err := ParseArgs([]string{"batch", "invoices.csv", "--workers", "8"})
+	require.NoError(t, err)
+	assert.Equal(t, "invoices.csv", args.BatchFile)
+	assert.Equal(t, 8, args.Workers)
+
+	args, err = ParseArgs([]string{"cache", "clear", "--verbose"})
+	require.NoError(t, err)
+	assert.Equal(t, CacheClear, args.CacheAction)
+	assert.True(t, args.Verbose)
+
+	args, err = ParseArgs([]string{"batch", "--", "--verbose"})
+	require.NoError(t, err)
+	assert.Equal(t, "--verbose", args.BatchFile)
+	assert.False(t, args.Verbose)
+}
+
+func TestParseArgs_UsageError(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantCommand string
+		wantOutput  OutputFormat
+	}{
+		{name: "implicit convert", args: []string{"100", "USD"}, wantCommand: "", wantOutput: OutputText},
+		{name: "unknown word", args: []string{"convertt", "100", "USD", "BTC"}, wantCommand: "", wantOutput: OutputText},
+		{name: "named command", args: []string{"rates", "--bogus", "BTC"}, wantCommand: CommandRates, wantOutput: OutputText},
+		{name: "flags before the command", args: []string{"--output", "yaml", "credits"}, wantCommand: CommandCredits, wantOutput: OutputYAML},
+		{name: "json output", args: []string{"--output", "json", "100", "USD"}, wantCommand: "", wantOutput: OutputJSON},
+		{name: "json output before a bad flag", args: []string{"--output", "json", "--bogus", "1", "BTC", "USD"}, wantCommand: "", wantOutput: OutputJSON},
+		{name: "invalid output", args: []string{"--output", "xml", "1", "BTC", "USD"}, wantCommand: "", wantOutput: OutputText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseArgs(tt.args)
+
+			var usageErr *UsageError
+			require.ErrorAs(t, err, &usageErr)
+			assert.Equal(t, tt.wantCommand, usageErr.Command)
+			assert.Equal(t, tt.wantOutput, usageErr.Output)
+		})
+	}
+
+	t.Run("unknown words are reported as such", func(t *testing.T) {
+		for _, args := range [][]string{{"bogus"}, {"convertt", "100", "USD", "BTC"}} {
+			_, err := ParseArgs(args)
+			assert.ErrorContains(t, err, fmt.Sprintf("unknown command or currency '%s'", args[0]))
+		}
+
+		_, err := ParseArgs([]string{"usd", "to", "btc"})
+		assert.ErrorContains(t, err, "expected an amount after 'usd', got 'to'")
+	})
+
+	t.Run("invalid timestamps wrap the domain error", func(t *testing.T) {
+		_, err := ParseArgs([]string{"--at", "soon", "1", "BTC", "USD"})
+		assert.ErrorIs(t, err, domain.ErrInvalidTimestamp)
+	})
+}
+
+func TestCommandIndex(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{args: []string{"100", "USD", "BTC"}, want: -1},
+		{args: []string{"batch", "file.csv"}, want: 0},
+		{args: []string{"--workers", "8", "batch"}, want: 2},
+		{args: []string{"--workers=8", "--verbose", "batch"}, want: 2},
+		{args: []string{"--format", "list", "1", "BTC", "USD"}, want: -1},
+		{args: []string{"--verbose", "100", "list", "USD"}, want: -1},
+		{args: []string{}, want: -1},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, commandIndex(tt.args), "%v", tt.args)
+	}
+}
+
+func TestWriteCommandHelp(t *testing.T) {
+	var buf bytes.Buffer
+	writeCommandHelp(&buf, commandByName(CommandBatch))
+	help := buf.String()
+
+	assert.Contains(t, help, "app batch [options] [file]")
+	assert.Contains(t, help, "--workers int")
+	assert.Contains(t, help, "--output format")
+	assert.NotContains(t, help, "--addr")
+	assert.Contains(t, help, "EXIT CODES:")
+
+	buf.Reset()
+	writeHelp(&buf)
+	for _, c := range commands {
+		assert.Contains(t, buf.String(), c.name)
+	}
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// delimitedFormatter writes one row per result with fields separated by comma
+type delimitedFormatter struct {
+	comma  rune
+	header bool
+}
+
+// NewCSVFormatter creates a comma-separated formatter, optionally starting
+// with a header row of column names
+func NewCSVFormatter(header bool) Formatter {
+	return &delimitedFormatter{comma: ',', header: header}
+}
+
+// NewTSVFormatter creates a tab-separated formatter, optionally starting
+// with a header row of column names
+func NewTSVFormatter(header bool) Formatter {
+	return &delimitedFormatter{comma: '\t', header: header}
+}
+
+// Format writes results as delimited rows
+func (f *delimitedFormatter) Format(w io.Writer, results []*domain.ConversionResult) error {
+	return f.FormatLines(w, results, nil)
+}
+
+// FormatLines writes results as delimited rows led by a line column
+func (f *delimitedFormatter) FormatLines(w io.Writer, results []*domain.ConversionResult, lines []int) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = f.comma
+
+	if f.header {
+		if err := cw.Write(header(lines != nil)); err != nil {
+			return err
+		}
+	}
+	for i := range results {
+		if err := cw.Write(numberedRow(results, lines, i)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
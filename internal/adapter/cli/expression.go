@@ -0,0 +1,281 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/kerimovkk/currency-conversion-utility/internal/usecase"
+)
+
+// Expression is a sum of amounts in different currencies valued in one or
+// more target currencies, such as "0.5 BTC + 2 ETH - 300 USD in EUR"
+type Expression struct {
+	Terms        []Term
+	ToCurrencies []string
+}
+
+// Term is one amount of an expression. Amount is positive and already
+// multiplied by any scalars; Negative terms are subtracted.
+type Term struct {
+	Amount   domain.Amount
+	Currency string
+	Negative bool
+}
+
+// ParseExpression parses an expression such as "0.5 BTC + 2 ETH - 300 USD in
+// EUR" or "3 * $100 + €20 to GBP". The grammar is
+//
+//	expression = ["+" | "-"] term {("+" | "-") term} [connector] currencies
+//	term       = {number "*"} money {"*" number}
+//	money      = number currency | currency number [currency]
+//	number     = decimal [exponent] ["k" | "m" | "b" | "bn"]
+//	currencies = currency {"," currency}
+//	currency   = code | sign
+//	connector  = "to" | "in" | "into" | "as" | "->" | "→"
+//
+// where × may be written for * and a sign such as €, £, ¥ or ₿ stands for
+// the currency of the registry that uses it. Where a currency is expected, a
+// ticker that starts with digits, such as 1INCH, is read as one code; where
+// an amount is expected, 1btc is still one BTC. Input that could be read more
+// than one way, such as "1,5", a sign shared by several currencies or
+// "$100 EUR", is rejected with an error wrapping domain.ErrMalformedInput
+// instead of being guessed at.
+func ParseExpression(input string) (*Expression, error) {
+	tokens, err := lexQuery(input)
+	if err != nil {
+		return nil, queryError(input, err)
+	}
+
+	p := &queryParser{tokens: tokens}
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, queryError(input, err)
+	}
+	return expr, nil
+}
+
+// Query returns the expression as a single conversion when it is one amount
+// that is not subtracted, as in "100 USD to EUR"
+func (e *Expression) Query() (*Query, bool) {
+	if len(e.Terms) != 1 || e.Terms[0].Negative {
+		return nil, false
+	}
+	return &Query{Amount: e.Terms[0].Amount, FromCurrency: e.Terms[0].Currency, ToCurrencies: e.ToCurrencies}, true
+}
+
+// String renders the expression in the canonical form "0.5 BTC + 2 ETH to EUR"
+func (e *Expression) String() string {
+	return fmt.Sprintf("%s to %s", e.sum(), strings.Join(e.ToCurrencies, ","))
+}
+
+// sum renders the terms of the expression, e.g. "0.5 BTC + 2 ETH - 300 USD"
+func (e *Expression) sum() string {
+	var b strings.Builder
+	for i, term := range e.Terms {
+		switch {
+		case i == 0 && term.Negative:
+			b.WriteString("-")
+		case i > 0:
+			b.WriteString(" " + term.operator() + " ")
+		}
+		fmt.Fprintf(&b, "%s %s", term.Amount, term.Currency)
+	}
+	return b.String()
+}
+
+// operator returns "-" for subtracted terms and "+" for the others
+func (t Term) operator() string {
+	if t.Negative {
+		return "-"
+	}
+	return "+"
+}
+
+// parseExpression parses: ["+" | "-"] term {("+" | "-") term} [connector] currencies
+func (p *queryParser) parseExpression() (*Expression, error) {
+	expr := &Expression{}
+
+	negative := false
+	if t := p.peek(); isAdditive(t) {
+		p.next()
+		negative = t.text == "-"
+	}
+	for {
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		term.Negative = negative
+		expr.Terms = append(expr.Terms, term)
+
+		t := p.peek()
+		if !isAdditive(t) {
+			break
+		}
+		p.next()
+		negative = t.text == "-"
+	}
+
+	// A lone amount is converted, not summed, so it must be positive
+	if len(expr.Terms) == 1 && expr.Terms[0].Negative {
+		term := expr.Terms[0]
+		return nil, fmt.Errorf("invalid amount '-%s %s': %w", term.Amount, term.Currency, domain.ErrInvalidAmount)
+	}
+
+	if p.peek().kind == tokenConnector {
+		p.next()
+	}
+
+	targets, err := p.parseCurrencies(p.last())
+	if err != nil {
+		return nil, err
+	}
+	expr.ToCurrencies = targets
+	return expr, nil
+}
+
+// parseTerm parses: {number "*"} money {"*" number}
+func (p *queryParser) parseTerm() (Term, error) {
+	factor := domain.NewAmountFromInt(1)
+	for p.peek().kind == tokenNumber && isMultiply(p.peekAt(1)) {
+		scalar, err := positiveAmount(p.next())
+		if err != nil {
+			return Term{}, err
+		}
+		p.next()
+		factor = factor.Mul(scalar)
+	}
+
+	amount, currency, err := p.parseMoney()
+	if err != nil {
+		return Term{}, err
+	}
+
+	for isMultiply(p.peek()) {
+		op := p.next()
+		number := p.next()
+		if number.kind != tokenNumber {
+			return Term{}, fmt.Errorf("%w: expected a number after %s, got %s", domain.ErrMalformedInput, op.describe(), number.describe())
+		}
+		scalar, err := positiveAmount(number)
+		if err != nil {
+			return Term{}, err
+		}
+		factor = factor.Mul(scalar)
+	}
+
+	return Term{Amount: amount.Mul(factor), Currency: currency}, nil
+}
+
+// isAdditive reports whether t is + or -
+func isAdditive(t token) bool {
+	return t.kind == tokenOperator && (t.text == "+" || t.text == "-")
+}
+
+// isMultiply reports whether t is * or ×
+func isMultiply(t token) bool {
+	return t.kind == tokenOperator && (t.text == "*" || t.text == "×")
+}
+
+// BatchConverter converts many amounts, pricing each distinct pair once; it
+// is implemented by usecase.ConvertCurrencyUseCase
+type BatchConverter interface {
+	ExecuteBatch(ctx context.Context, items []usecase.BatchItem, workers int, opts ...usecase.ExecuteOption) []usecase.BatchResult
+}
+
+// Total is the value of an expression in one target currency
+type Total struct {
+	Expression *Expression
+	Amount     domain.Amount
+	Currency   *domain.Currency
+
+	// Results holds the conversion of every term, in the order of Expression.Terms
+	Results []*domain.ConversionResult
+}
+
+// Evaluate values expr in each of its target currencies. The terms are
+// converted together by converter, with at most workers concurrent lookups
+// and the options of opts; terms already in the target currency count at face
+// value, rounded by rounding like the converted ones. A total is the sum of
+// the rounded term values, so it adds up to the breakdown.
+func Evaluate(
+	ctx context.Context,
+	converter BatchConverter,
+	expr *Expression,
+	rounding domain.RoundingPolicy,
+	workers int,
+	opts ...usecase.ExecuteOption,
+) ([]*Total, error) {
+	at := usecase.NewExecuteOptions(opts...).At
+
+	// slot locates the result of a batch item among the totals
+	type slot struct {
+		total, term int
+	}
+
+	totals := make([]*Total, len(expr.ToCurrencies))
+	var items []usecase.BatchItem
+	var slots []slot
+	for i, target := range expr.ToCurrencies {
+		totals[i] = &Total{Expression: expr, Results: make([]*domain.ConversionResult, len(expr.Terms))}
+		for j, term := range expr.Terms {
+			if result := faceValue(term, target, rounding, at); result != nil {
+				totals[i].Results[j] = result
+				continue
+			}
+			items = append(items, usecase.BatchItem{Amount: term.Amount, FromSymbol: term.Currency, ToSymbol: target})
+			slots = append(slots, slot{total: i, term: j})
+		}
+	}
+
+	for k, outcome := range converter.ExecuteBatch(ctx, items, workers, opts...) {
+		s := slots[k]
+		if outcome.Err != nil {
+			term := expr.Terms[s.term]
+			return nil, fmt.Errorf("converting %s %s to %s: %w", term.Amount, term.Currency, expr.ToCurrencies[s.total], outcome.Err)
+		}
+		totals[s.total].Results[s.term] = outcome.Result
+	}
+
+	for _, total := range totals {
+		for j, result := range total.Results {
+			value := result.ConvertedAmount
+			if expr.Terms[j].Negative {
+				value = value.Neg()
+			}
+			total.Amount = total.Amount.Add(value)
+			total.Currency = result.ToCurrency
+		}
+	}
+	return totals, nil
+}
+
+// faceValue returns term as a conversion into its own currency when target
+// names that currency, or nil when the term needs pricing
+func faceValue(term Term, target string, rounding domain.RoundingPolicy, at time.Time) *domain.ConversionResult {
+	if !strings.EqualFold(strings.TrimSpace(term.Currency), strings.TrimSpace(target)) {
+		return nil
+	}
+
+	// The registry only supplies decimals and names; tickers it does not
+	// know, such as 1INCH, count at face value all the same
+	currency, err := domain.NewUnlistedCurrency(target)
+	if err != nil {
+		return nil
+	}
+
+	now := time.Now()
+	return &domain.ConversionResult{
+		OriginalAmount:  term.Amount,
+		ConvertedAmount: rounding.Apply(term.Amount, currency),
+		FromCurrency:    currency,
+		ToCurrency:      currency,
+		ExchangeRate:    domain.NewAmountFromInt(1),
+		Timestamp:       now,
+		LastUpdated:     now,
+		AsOf:            at,
+	}
+}
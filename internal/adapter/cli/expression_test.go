@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/kerimovkk/currency-conversion-utility/internal/usecase"
+)
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		terms []string
+		to    []string
+	}{
+		{input: "0.5 BTC + 2 ETH - 300 USD in EUR", terms: []string{"+0.5 BTC", "+2 ETH", "-300 USD"}, to: []string{"EUR"}},
+		{input: "100 USD to BTC", terms: []string{"+100 USD"}, to: []string{"BTC"}},
+		{input: "-300 USD + 1 BTC to EUR", terms: []string{"-300 USD", "+1 BTC"}, to: []string{"EUR"}},
+		{input: "3 * $100 + €20 to GBP", terms: []string{"+300 USD", "+20 EUR"}, to: []string{"GBP"}},
+		{input: "0.5 BTC * 2 × 3 to USD", terms: []string{"+3 BTC"}, to: []string{"USD"}},
+		{input: "2 * 1.5k EUR - 1 BTC→USD,GBP", terms: []string{"+3000 EUR", "-1 BTC"}, to: []string{"USD", "GBP"}},
+		{input: "1 BTC-1e-3 BTC to USD", terms: []string{"+1 BTC", "-0.001 BTC"}, to: []string{"USD"}},
+		{input: "1 btc + 2 eth eur", terms: []string{"+1 btc", "+2 eth"}, to: []string{"eur"}},
+		{input: "+1 BTC->USD", terms: []string{"+1 BTC"}, to: []string{"USD"}},
+		{input: "10 1INCH - 2 * 3 1INCH to USD", terms: []string{"+10 1INCH", "-6 1INCH"}, to: []string{"USD"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := ParseExpression(tt.input)
+			require.NoError(t, err)
+
+			var terms []string
+			for _, term := range expr.Terms {
+				terms = append(terms, term.operator()+term.Amount.Normalize().String()+" "+term.Currency)
+			}
+			assert.Equal(t, tt.terms, terms)
+			assert.Equal(t, tt.to, expr.ToCurrencies)
+		})
+	}
+}
+
+func TestParseExpression_Errors(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{input: "1 BTC + to EUR", wantErr: "expected an amount, got 'to'"},
+		{input: "1 BTC - -2 ETH to EUR", wantErr: "expected an amount, got '-'"},
+		{input: "1 BTC * EUR", wantErr: "expected a number after '*', got 'EUR'"},
+		{input: "1 BTC * 0 to EUR", wantErr: "invalid amount '0': must be greater than zero"},
+		{input: "2 * * 1 BTC to EUR", wantErr: "expected an amount, got '*'"},
+		{input: "1 BTC + 2 ETH", wantErr: "expected a target currency after 'ETH', got end of input"},
+		{input: "1 BTC + 2 ETH to EUR + 1 USD", wantErr: "unexpected '+' after 'EUR'"},
+		{input: "1 BTC + 1,5 ETH to EUR", wantErr: "ambiguous number '1,5'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseExpression(tt.input)
+			require.Error(t, err)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestExpression_Query(t *testing.T) {
+	expr, err := ParseExpression("2 * $50 in EUR")
+	require.NoError(t, err)
+	query, ok := expr.Query()
+	require.True(t, ok)
+	assert.Equal(t, &Query{Amount: domain.MustParseAmount("100"), FromCurrency: "USD", ToCurrencies: []string{"EUR"}}, query)
+
+	expr, err = ParseExpression("1 BTC + 1 ETH in EUR")
+	require.NoError(t, err)
+	_, ok = expr.Query()
+	assert.False(t, ok)
+
+	_, err = ParseExpression("-100 USD in EUR")
+	assert.ErrorIs(t, err, domain.ErrInvalidAmount)
+
+	_, err = ParseArgs([]string{"--", "-5", "USD", "BTC"})
+	assert.ErrorIs(t, err, domain.ErrInvalidAmount)
+}
+
+func TestParseArgs_Expression(t *testing.T) {
+	args, err := ParseArgs([]string{"0.5", "BTC", "+", "2", "ETH", "-", "300", "USD", "in", "EUR"})
+	require.NoError(t, err)
+	require.NotNil(t, args.Expression)
+	assert.Equal(t, "0.5 BTC + 2 ETH - 300 USD to EUR", args.Expression.String())
+	assert.Equal(t, []string{"EUR"}, args.ToCurrencies)
+
+	args, err = ParseArgs([]string{"--output", "json", "1 BTC - 1 ETH in USD"})
+	require.NoError(t, err)
+	assert.NotNil(t, args.Expression)
+
+	// A single amount stays a plain conversion
+	args, err = ParseArgs([]string{"3 * 100 USD to EUR"})
+	require.NoError(t, err)
+	assert.Nil(t, args.Expression)
+	assert.Equal(t, "300", args.Amount.String())
+
+	_, err = ParseArgs([]string{"--output", "csv", "1 BTC + 1 ETH in USD"})
+	assert.ErrorContains(t, err, "expressions support text or json")
+
+	_, err = ParseArgs([]string{"--format", "{{.ConvertedAmount}}", "1 BTC + 1 ETH in USD"})
+	assert.ErrorContains(t, err, "--format cannot be combined with an expression")
+}
+
+// fakeBatchConverter prices pairs at fixed rates and records the items asked for
+type fakeBatchConverter struct {
+	rates map[string]string
+	items []usecase.BatchItem
+}
+
+func (f *fakeBatchConverter) ExecuteBatch(_ context.Context, items []usecase.BatchItem, _ int, opts ...usecase.ExecuteOption) []usecase.BatchResult {
+	at := usecase.NewExecuteOptions(opts...).At
+	f.items = append(f.items, items...)
+	results := make([]usecase.BatchResult, len(items))
+	for i, item := range items {
+		rate, ok := f.rates[item.FromSymbol+"/"+item.ToSymbol]
+		if !ok {
+			results[i].Err = domain.ErrUnsupportedPair
+			continue
+		}
+		from, _ := domain.NewCurrency(item.FromSymbol)
+		to, _ := domain.NewCurrency(item.ToSymbol)
+		exchangeRate := domain.MustParseAmount(rate)
+		result := domain.NewConversionResult(item.Amount, domain.NewRoundingPolicy(domain.RoundHalfEven).Apply(item.Amount.Mul(exchangeRate), to), exchangeRate, from, to, at, at)
+		result.Source = "fake"
+		results[i].Result = result
+	}
+	return results
+}
+
+func TestEvaluate(t *testing.T) {
+	converter := &fakeBatchConverter{rates: map[string]string{
+		"BTC/EUR": "60000",
+		"ETH/EUR": "3000",
+		"USD/EUR": "0.92",
+		"BTC/USD": "65000",
+		"ETH/USD": "3250",
+		"EUR/USD": "1.08",
+	}}
+	expr, err := ParseExpression("0.5 BTC + 2 ETH - 300 USD + 10 EUR to EUR,USD")
+	require.NoError(t, err)
+
+	totals, err := Evaluate(context.Background(), converter, expr, domain.NewRoundingPolicy(domain.RoundHalfEven), 0)
+	require.NoError(t, err)
+	require.Len(t, totals, 2)
+
+	assert.Equal(t, "EUR", totals[0].Currency.String())
+	assert.True(t, domain.MustParseAmount("35734").Equal(totals[0].Amount), totals[0].Amount.String())
+	assert.Equal(t, "USD", totals[1].Currency.String())
+	assert.True(t, domain.MustParseAmount("38710.80").Equal(totals[1].Amount), totals[1].Amount.String())
+
+	// Terms in the target currency are not priced
+	for _, item := range converter.items {
+		assert.NotEqual(t, item.FromSymbol, item.ToSymbol)
+	}
+	assert.Len(t, converter.items, 6)
+	assert.Equal(t, "10.00", totals[0].Results[3].ConvertedAmount.String())
+}
+
+func TestEvaluate_RoundsFaceValue(t *testing.T) {
+	converter := &fakeBatchConverter{rates: map[string]string{"BTC/USD": "65000"}}
+	expr, err := ParseExpression("1.005 USD + 1 BTC in USD")
+	require.NoError(t, err)
+
+	totals, err := Evaluate(context.Background(), converter, expr, domain.NewRoundingPolicy(domain.RoundHalfUp), 0)
+	require.NoError(t, err)
+	assert.Equal(t, "1.01", totals[0].Results[0].ConvertedAmount.String())
+	assert.Equal(t, "1.005", totals[0].Results[0].OriginalAmount.String())
+	assert.Equal(t, "65001.01", totals[0].Amount.String())
+
+	totals, err = Evaluate(context.Background(), converter, expr, domain.NewRoundingPolicy(domain.RoundDown).WithPrecision(0), 0)
+	require.NoError(t, err)
+	assert.Equal(t, "1", totals[0].Results[0].ConvertedAmount.String())
+}
+
+func TestEvaluate_UnlistedFaceValue(t *testing.T) {
+	converter := &fakeBatchConverter{}
+	expr, err := ParseExpression("10 1INCH in 1inch")
+	require.NoError(t, err)
+
+	// Tickers missing from the registry are not priced against themselves
+	totals, err := Evaluate(context.Background(), converter, expr, domain.NewRoundingPolicy(domain.RoundHalfEven), 0)
+	require.NoError(t, err)
+	assert.Empty(t, converter.items)
+	assert.Equal(t, "1INCH", totals[0].Currency.String())
+	assert.Equal(t, "10", totals[0].Amount.String())
+}
+
+func TestEvaluate_AsOf(t *testing.T) {
+	converter := &fakeBatchConverter{rates: map[string]string{"BTC/USD": "65000"}}
+	expr, err := ParseExpression("1 BTC + 1 USD in USD")
+	require.NoError(t, err)
+
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	totals, err := Evaluate(context.Background(), converter, expr, domain.NewRoundingPolicy(domain.RoundHalfEven), 0, usecase.WithAsOf(at))
+	require.NoError(t, err)
+	assert.Equal(t, at, totals[0].Results[0].Timestamp)
+	assert.Equal(t, at, totals[0].Results[1].AsOf)
+}
+
+func TestEvaluate_Error(t *testing.T) {
+	converter := &fakeBatchConverter{rates: map[string]string{"BTC/EUR": "60000"}}
+	expr, err := ParseExpression("1 BTC + 2 DOGE to EUR")
+	require.NoError(t, err)
+
+	_, err = Evaluate(context.Background(), converter, expr, domain.NewRoundingPolicy(domain.RoundHalfEven), 0)
+	assert.ErrorIs(t, err, domain.ErrUnsupportedPair)
+	assert.ErrorContains(t, err, "converting 2 DOGE to EUR")
+}
+
+func FuzzParseExpression(f *testing.F) {
+	for _, seed := range []string{
+		"0.5 BTC + 2 ETH - 300 USD in EUR", "-300 USD + 1 BTC to EUR", "3 * $100 + €20 to GBP",
+		"0.5 BTC * 2 × 3 to USD", "1 BTC-1e-3 BTC->USD,GBP", "2 * * 1 BTC to EUR", "1 BTC - -2 ETH", "10 1INCH + 1 BTC to 1inch",
+		"100 usd to btc", "$100 in BTC", "1.5k EUR in JPY", "1e-3 BTC→USD", "€50 to £,¥", "1,500 USD to EUR",
+		"kr100 to EUR", "2 id:1027 -> slug:usd-coin", "CN¥1e3 as ₿", ".5bn ETH in usd", "$100 EUR", "1e- BTC",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		expr, err := ParseExpression(input)
+		if err != nil {
+			return
+		}
+
+		// Every accepted term is a positive amount of a currency...
+		if len(expr.Terms) == 0 || len(expr.ToCurrencies) == 0 {
+			t.Fatalf("%q: incomplete expression %+v", input, expr)
+		}
+		for _, term := range expr.Terms {
+			if term.Amount.Sign() <= 0 || term.Currency == "" {
+				t.Fatalf("%q: invalid term %+v", input, term)
+			}
+		}
+
+		// ...and the canonical form reads the same
+		again, err := ParseExpression(expr.String())
+		if err != nil {
+			t.Fatalf("%q: canonical form %q does not parse: %v", input, expr.String(), err)
+		}
+		if again.String() != expr.String() {
+			t.Fatalf("%q: canonical form %q reads as %q", input, expr.String(), again.String())
+		}
+		if !strings.EqualFold(strings.Join(again.ToCurrencies, ","), strings.Join(expr.ToCurrencies, ",")) {
+			t.Fatalf("%q: targets %v read as %v", input, expr.ToCurrencies, again.ToCurrencies)
+		}
+	})
+}
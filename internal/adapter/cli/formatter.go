@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// OutputFormat selects how results and errors are written
+type OutputFormat string
+
+const (
+	// OutputText writes human-readable text
+	OutputText OutputFormat = "text"
+
+	// OutputJSON writes the documented JSON schema to stdout and errors as
+	// JSON objects to stderr
+	OutputJSON OutputFormat = "json"
+
+	// OutputYAML writes the documented schema as YAML
+	OutputYAML OutputFormat = "yaml"
+
+	// OutputCSV writes one comma-separated row per result
+	OutputCSV OutputFormat = "csv"
+
+	// OutputTSV writes one tab-separated row per result
+	OutputTSV OutputFormat = "tsv"
+
+	// OutputTable writes results as aligned columns
+	OutputTable OutputFormat = "table"
+)
+
+// outputFormats lists the accepted --output values in the order shown to users
+var outputFormats = []OutputFormat{OutputText, OutputJSON, OutputYAML, OutputCSV, OutputTSV, OutputTable}
+
+// ParseOutputFormat parses an --output value
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	format := OutputFormat(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range outputFormats {
+		if format == known {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("invalid output format '%s': expected text, json, yaml, csv, tsv or table", s)
+}
+
+// Formatter writes conversion results in one output format
+type Formatter interface {
+	// Format writes results to w
+	Format(w io.Writer, results []*domain.ConversionResult) error
+}
+
+// LineFormatter is implemented by formatters that can number every result
+// with the batch input line it was read from, so that the output still lines
+// up with the input when some lines fail
+type LineFormatter interface {
+	// FormatLines writes results to w; lines[i] is the input line of results[i]
+	FormatLines(w io.Writer, results []*domain.ConversionResult, lines []int) error
+}
+
+// NewFormatter returns the built-in formatter for format. verbose selects
+// detailed text output; header controls the header row of csv, tsv and table.
+func NewFormatter(format OutputFormat, verbose, header bool) Formatter {
+	switch format {
+	case OutputJSON:
+		return NewJSONFormatter()
+	case OutputYAML:
+		return NewYAMLFormatter()
+	case OutputCSV:
+		return NewCSVFormatter(header)
+	case OutputTSV:
+		return NewTSVFormatter(header)
+	case OutputTable:
+		return NewTableFormatter(header)
+	default:
+		return NewTextFormatter(verbose)
+	}
+}
+
+// columns are the fields of the flat csv, tsv and table formats
+var columns = []string{
+	"amount", "from", "converted_amount", "to", "rate", "source",
+	"as_of", "last_updated", "query_time", "route",
+}
+
+// lineColumn leads the columns of numbered batch output
+const lineColumn = "line"
+
+// header returns the column names, led by lineColumn when numbered
+func header(numbered bool) []string {
+	if numbered {
+		return append([]string{lineColumn}, columns...)
+	}
+	return columns
+}
+
+// numberedRow flattens results[i], led by its input line when lines is set
+func numberedRow(results []*domain.ConversionResult, lines []int, i int) []string {
+	if lines == nil {
+		return row(results[i])
+	}
+	return append([]string{strconv.Itoa(lines[i])}, row(results[i])...)
+}
+
+// row flattens a result into the values of columns
+func row(result *domain.ConversionResult) []string {
+	out := newOutputResult(result)
+	return []string{
+		out.Amount,
+		out.From.Symbol,
+		out.ConvertedAmount,
+		out.To.Symbol,
+		out.Rate,
+		out.Source,
+		out.AsOf,
+		out.LastUpdated,
+		out.QueryTime,
+		routePath(result),
+	}
+}
+
+// routePath renders the currencies a routed conversion passed through, e.g.
+// "DOGE → USD → CHF", or "" for a direct conversion
+func routePath(result *domain.ConversionResult) string {
+	path := result.Path()
+	symbols := make([]string, 0, len(path))
+	for _, currency := range path {
+		symbols = append(symbols, currency.String())
+	}
+	return strings.Join(symbols, " → ")
+}
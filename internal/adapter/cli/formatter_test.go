@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    OutputFormat
+		wantErr bool
+	}{
+		{input: "text", want: OutputText},
+		{input: "json", want: OutputJSON},
+		{input: " JSON ", want: OutputJSON},
+		{input: "yaml", want: OutputYAML},
+		{input: "csv", want: OutputCSV},
+		{input: "tsv", want: OutputTSV},
+		{input: "table", want: OutputTable},
+		{input: "xml", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseOutputFormat(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// goldenResults covers a direct, a routed historical and a consensus conversion
+func goldenResults(t *testing.T) []*domain.ConversionResult {
+	currency := func(symbol string) *domain.Currency {
+		c, err := domain.NewCurrency(symbol)
+		require.NoError(t, err)
+		return c
+	}
+	btc, eth, doge := currency("BTC"), currency("ETH"), currency("DOGE")
+	usd, eur, chf := currency("USD"), currency("EUR"), currency("CHF")
+	queried := time.Date(2024, 3, 1, 15, 4, 5, 0, time.UTC)
+	updated := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+
+	direct := domain.NewConversionResult(
+		domain.MustParseAmount("1"),
+		domain.MustParseAmount("65000.00"),
+		domain.MustParseAmount("65000.123456"),
+		btc, usd, queried, updated,
+	)
+	direct.Source = "coinmarketcap"
+
+	routed := domain.NewConversionResult(
+		domain.MustParseAmount("1000"),
+		domain.MustParseAmount("105.60"),
+		domain.MustParseAmount("0.1056"),
+		doge, chf, queried, updated,
+	)
+	routed.AsOf = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	routed.Source = "coinmarketcap, ecb"
+	routed.Legs = []domain.ConversionLeg{
+		{FromCurrency: doge, ToCurrency: usd, ExchangeRate: domain.MustParseAmount("0.12"), Source: "coinmarketcap"},
+		{FromCurrency: usd, ToCurrency: chf, ExchangeRate: domain.MustParseAmount("0.88"), Source: "ecb"},
+	}
+
+	consensus := domain.NewConversionResult(
+		domain.MustParseAmount("2"),
+		domain.MustParseAmount("6000.00"),
+		domain.MustParseAmount("3000"),
+		eth, eur, queried, updated,
+	)
+	consensus.Source = "consensus"
+	consensus.Spread = domain.MustParseAmount("30")
+	consensus.Quotes = []domain.ProviderQuote{
+		{Source: "coinmarketcap", Rate: domain.MustParseAmount("3000"), Deviation: domain.MustParseAmount("0")},
+		{Source: "coingecko", Rate: domain.MustParseAmount("3030"), Deviation: domain.MustParseAmount("0.01"), Rejected: true},
+		{Source: "ecb", Err: fmt.Errorf("%w: ETH", domain.ErrUnsupportedPair)},
+	}
+
+	return []*domain.ConversionResult{direct, routed, consensus}
+}
+
+func TestFormatters_Golden(t *testing.T) {
+	tests := []struct {
+		golden    string
+		formatter Formatter
+	}{
+		{golden: "text.golden", formatter: NewTextFormatter(false)},
+		{golden: "text_verbose.golden", formatter: NewTextFormatter(true)},
+		{golden: "json.golden", formatter: NewJSONFormatter()},
+		{golden: "yaml.golden", formatter: NewYAMLFormatter()},
+		{golden: "csv.golden", formatter: NewCSVFormatter(true)},
+		{golden: "csv_no_header.golden", formatter: NewCSVFormatter(false)},
+		{golden: "tsv.golden", formatter: NewTSVFormatter(true)},
+		{golden: "tsv_no_header.golden", formatter: NewTSVFormatter(false)},
+		{golden: "table.golden", formatter: NewTableFormatter(true)},
+		{golden: "table_no_header.golden", formatter: NewTableFormatter(false)},
+	}
+
+	results := goldenResults(t)
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, tt.formatter.Format(&buf, results))
+
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+			}
+			want, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), buf.String())
+		})
+	}
+}
+
+func TestNewFormatter(t *testing.T) {
+	assert.Equal(t, NewTextFormatter(true), NewFormatter(OutputText, true, true))
+	assert.Equal(t, NewJSONFormatter(), NewFormatter(OutputJSON, false, true))
+	assert.Equal(t, NewYAMLFormatter(), NewFormatter(OutputYAML, false, true))
+	assert.Equal(t, NewCSVFormatter(false), NewFormatter(OutputCSV, false, false))
+	assert.Equal(t, NewTSVFormatter(true), NewFormatter(OutputTSV, false, true))
+	assert.Equal(t, NewTableFormatter(false), NewFormatter(OutputTable, true, false))
+}
+
+func TestPresenter_Writers(t *testing.T) {
+	results := goldenResults(t)[:1]
+
+	t.Run("results go to the output writer", func(t *testing.T) {
+		var out, errOut bytes.Buffer
+		p := NewPresenter(false, WithOutput(OutputCSV), WithHeader(false), WithWriters(&out, &errOut))
+
+		p.PresentResults(results)
+
+		assert.Equal(t, "1,BTC,65000.00,USD,65000.123456,coinmarketcap,,2024-03-01T15:00:00Z,2024-03-01T15:04:05Z,\n", out.String())
+		assert.Empty(t, errOut.String())
+	})
+
+	t.Run("custom formatter", func(t *testing.T) {
+		var out bytes.Buffer
+		p := NewPresenter(false, WithFormatter(NewTSVFormatter(false)), WithWriters(&out, &bytes.Buffer{}))
+
+		p.PresentResult(results[0])
+
+		assert.Contains(t, out.String(), "1\tBTC\t65000.00\tUSD\t")
+	})
+
+	t.Run("json errors carry the domain code", func(t *testing.T) {
+		var out, errOut bytes.Buffer
+		p := NewPresenter(false, WithOutput(OutputJSON), WithWriters(&out, &errOut))
+
+		p.PresentError(fmt.Errorf("failed to fetch price: %w", domain.ErrNetworkFailure))
+
+		assert.JSONEq(t, `{"error":{"code":"network_failure","message":"failed to fetch price: network failure: could not connect to API"}}`, errOut.String())
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("batch line errors", func(t *testing.T) {
+		var errOut bytes.Buffer
+		err := fmt.Errorf("%w: ZZZ", domain.ErrInvalidCurrency)
+
+		NewPresenter(false, WithWriters(&bytes.Buffer{}, &errOut)).PresentLineError(3, err)
+		assert.Equal(t, "Error: line 3: invalid currency symbol: ZZZ\n", errOut.String())
+
+		errOut.Reset()
+		NewPresenter(false, WithOutput(OutputJSON), WithWriters(&bytes.Buffer{}, &errOut)).PresentLineError(3, err)
+		assert.JSONEq(t, `{"error":{"code":"invalid_currency","message":"invalid currency symbol: ZZZ","line":3}}`, errOut.String())
+	})
+
+	t.Run("batch results are numbered with their input lines", func(t *testing.T) {
+		var out bytes.Buffer
+		NewPresenter(false, WithOutput(OutputCSV), WithWriters(&out, &bytes.Buffer{})).PresentBatch(results, []int{4})
+		assert.Equal(t, "line,amount,from,converted_amount,to,rate,source,as_of,last_updated,query_time,route\n"+
+			"4,1,BTC,65000.00,USD,65000.123456,coinmarketcap,,2024-03-01T15:00:00Z,2024-03-01T15:04:05Z,\n", out.String())
+
+		out.Reset()
+		NewPresenter(false, WithOutput(OutputTable), WithWriters(&out, &bytes.Buffer{})).PresentBatch(results, []int{4})
+		assert.Regexp(t, `^LINE +AMOUNT +FROM .*\n4 +1 +BTC +65000\.00 `, out.String())
+
+		out.Reset()
+		NewPresenter(false, WithOutput(OutputJSON), WithWriters(&out, &bytes.Buffer{})).PresentBatch(results, []int{4})
+		assert.Contains(t, out.String(), `{"results":[{"line":4,"amount":"1",`)
+
+		out.Reset()
+		NewPresenter(false, WithWriters(&out, &bytes.Buffer{})).PresentBatch(results, []int{4})
+		assert.Equal(t, "1 BTC = 65000.00 USD\n", out.String())
+	})
+
+	t.Run("usage errors", func(t *testing.T) {
+		var errOut bytes.Buffer
+		usageErr := &UsageError{Command: CommandRates, Err: errors.New("flag provided but not defined: -bogus")}
+
+		NewPresenter(false, WithWriters(&bytes.Buffer{}, &errOut)).PresentUsageError(usageErr)
+		assert.Equal(t, "Error: flag provided but not defined: -bogus\n\nRun 'app help rates' for usage information\n", errOut.String())
+
+		errOut.Reset()
+		NewPresenter(false, WithOutput(OutputJSON), WithWriters(&bytes.Buffer{}, &errOut)).PresentUsageError(usageErr)
+		assert.JSONEq(t, `{"error":{"code":"invalid_usage","message":"flag provided but not defined: -bogus"}}`, errOut.String())
+
+		errOut.Reset()
+		_, err := ParseArgs([]string{"--output", "json", "--at", "soon", "1", "BTC", "USD"})
+		require.ErrorAs(t, err, &usageErr)
+		NewPresenter(false, WithOutput(usageErr.Output), WithWriters(&bytes.Buffer{}, &errOut)).PresentUsageError(usageErr)
+		assert.Contains(t, errOut.String(), `{"error":{"code":"invalid_timestamp","message":"invalid timestamp 'soon': expected`)
+	})
+
+	t.Run("write failures are reported", func(t *testing.T) {
+		var errOut bytes.Buffer
+		p := NewPresenter(false, WithWriters(failingWriter{}, &errOut))
+
+		p.PresentResults(results)
+
+		assert.Equal(t, "Error: writing output: disk full\n", errOut.String())
+	})
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func TestPresenter_PresentTotals(t *testing.T) {
+	converter := &fakeBatchConverter{rates: map[string]string{"BTC/EUR": "60000", "USD/EUR": "0.92"}}
+	expr, err := ParseExpression("0.5 BTC - 300 USD + 10 EUR in EUR")
+	require.NoError(t, err)
+	totals, err := Evaluate(context.Background(), converter, expr, domain.NewRoundingPolicy(domain.RoundHalfEven), 0)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	NewPresenter(false, WithWriters(&out, &bytes.Buffer{})).PresentTotals(totals)
+	assert.Equal(t, "0.5 BTC - 300 USD + 10 EUR = 29734.00 EUR\n", out.String())
+
+	out.Reset()
+	NewPresenter(true, WithWriters(&out, &bytes.Buffer{})).PresentTotals(totals)
+	assert.Equal(t, "0.5 BTC - 300 USD + 10 EUR = 29734.00 EUR\n"+
+		"  + 0.5 BTC  = 30000.00 EUR  1 BTC = 60000 EUR (fake)\n"+
+		"  - 300 USD  = 276.00 EUR    1 USD = 0.92 EUR (fake)\n"+
+		"  + 10 EUR   = 10.00 EUR\n", out.String())
+
+	out.Reset()
+	NewPresenter(false, WithOutput(OutputJSON), WithWriters(&out, &bytes.Buffer{})).PresentTotals(totals)
+	doc := out.String()
+	assert.Contains(t, doc, `"expression":"0.5 BTC - 300 USD + 10 EUR"`)
+	assert.Contains(t, doc, `"amount":"29734.00"`)
+	assert.Contains(t, doc, `"operator":"-","amount":"300"`)
+	assert.Contains(t, doc, `"converted_amount":"276.00"`)
+}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// ShowHelp displays the help message of the app
+func ShowHelp() {
+	writeHelp(os.Stdout)
+}
+
+// ShowCommandHelp displays the help message of the named command
+func ShowCommandHelp(name string) {
+	c := commandByName(name)
+	if c == nil {
+		writeHelp(os.Stdout)
+		return
+	}
+	writeCommandHelp(os.Stdout, c)
+}
+
+// writeHelp writes the overview of every command
+func writeHelp(w io.Writer) {
+	fmt.Fprintln(w, "Currency Conversion Utility")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "USAGE:")
+	fmt.Fprintln(w, "  app <command> [options] [arguments]")
+	fmt.Fprintln(w, "  app [options] <amount> <from_currency> <to_currency>   (same as convert)")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "COMMANDS:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'app help <command>' or 'app <command> --help' for the options of a command.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "EXAMPLES:")
+	fmt.Fprintln(w, "  app 123.45 USD BTC")
+	fmt.Fprintln(w, "  app 1.5k eur in jpy")
+	fmt.Fprintln(w, "  app --verbose 1 BTC USD,EUR,GBP,JPY")
+	fmt.Fprintln(w, "  app rates --output table BTC")
+	fmt.Fprintln(w, "  app history --start 2024-03-01 --end 2024-03-07 BTC USD")
+	fmt.Fprintln(w, "  app batch --output csv invoices.csv")
+	fmt.Fprintln(w, "  app serve --addr :8080")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "ENVIRONMENT VARIABLES:")
+	fmt.Fprintln(w, "  CMC_API_KEY     CoinMarketCap API key (required for coinmarketcap)")
+	fmt.Fprintln(w, "  CMC_API_URL     CoinMarketCap API base URL (optional)")
+	fmt.Fprintln(w, "  PROVIDER        Default price provider (optional)")
+	fmt.Fprintln(w, "  CMC_REQUESTS_PER_MINUTE, CMC_DAILY_CREDIT_BUDGET, CMC_MONTHLY_CREDIT_BUDGET")
+	fmt.Fprintln(w, "                  CoinMarketCap rate limit and credit budgets (optional)")
+	fmt.Fprintln(w, "  PROVIDER_<NAME>_API_KEY, PROVIDER_<NAME>_API_URL")
+	fmt.Fprintln(w, "                  Settings of other providers (optional)")
+	fmt.Fprintln(w, "  TEMPLATE_<NAME> Named output template selectable with --format <name> (optional)")
+	fmt.Fprintln(w, "  Run 'app config' to see every setting in effect.")
+	fmt.Fprintln(w)
+	writeExitCodes(w)
+}
+
+// writeCommandHelp writes the usage, description, options and examples of c
+func writeCommandHelp(w io.Writer, c *command) {
+	fmt.Fprintf(w, "app %s - %s\n", c.name, c.summary)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "USAGE:")
+	fmt.Fprintln(w, "  "+strings.TrimSpace(fmt.Sprintf("app %s [options] %s", c.name, c.usage)))
+	if len(c.description) > 0 {
+		fmt.Fprintln(w)
+		for _, line := range c.description {
+			fmt.Fprintln(w, strings.TrimRight("  "+line, " "))
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "OPTIONS:")
+	fs, _ := newFlagSet(c.name, c.flags)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fs.VisitAll(func(f *flag.Flag) {
+		name, usage := flag.UnquoteUsage(f)
+		option := "--" + f.Name
+		if name != "" {
+			option += " " + name
+		}
+		switch f.DefValue {
+		case "", "0", "-1", "false":
+		default:
+			usage += fmt.Sprintf(" (default %s)", f.DefValue)
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", option, usage)
+	})
+	_ = tw.Flush()
+
+	if len(c.examples) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "EXAMPLES:")
+		for _, example := range c.examples {
+			fmt.Fprintln(w, "  "+example)
+		}
+	}
+	fmt.Fprintln(w)
+	writeExitCodes(w)
+}
+
+// writeExitCodes documents the exit codes shared by every command
+func writeExitCodes(w io.Writer) {
+	fmt.Fprintln(w, "EXIT CODES:")
+	fmt.Fprintf(w, "  %d  success\n", ExitOK)
+	fmt.Fprintf(w, "  %d  the command failed, e.g. a conversion error or a failed batch line\n", ExitFailure)
+	fmt.Fprintf(w, "  %d  invalid arguments or options\n", ExitUsage)
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// Setting is one configuration value shown by the config command
+type Setting struct {
+	Name  string
+	Value string
+
+	// Secret values such as API keys are masked
+	Secret bool
+}
+
+// CacheFile is one file in the cache directory
+type CacheFile struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+}
+
+// PresentCurrencies displays the currencies of the registry
+func (p *Presenter) PresentCurrencies(currencies []*domain.Currency) {
+	if p.output == OutputJSON {
+		doc := outputCurrencies{Currencies: make([]outputCurrencyInfo, 0, len(currencies))}
+		for _, c := range currencies {
+			doc.Currencies = append(doc.Currencies, outputCurrencyInfo{
+				Symbol:   c.Symbol,
+				Name:     c.Name,
+				Kind:     string(c.Kind),
+				Decimals: c.Decimals,
+				Sign:     c.Sign,
+				CMCID:    c.CMCID,
+			})
+		}
+		p.writeJSON(p.out, doc)
+		return
+	}
+
+	tw := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SYMBOL\tNAME\tKIND\tDECIMALS\tSIGN")
+	for _, c := range currencies {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", c.Symbol, c.Name, c.Kind, c.Decimals, c.Sign)
+	}
+	p.flush(tw)
+}
+
+// PresentSettings displays configuration values as NAME=value lines
+func (p *Presenter) PresentSettings(settings []Setting) {
+	if p.output == OutputJSON {
+		doc := outputSettings{Settings: make([]outputSetting, 0, len(settings))}
+		for _, s := range settings {
+			doc.Settings = append(doc.Settings, outputSetting{Name: s.Name, Value: s.display()})
+		}
+		p.writeJSON(p.out, doc)
+		return
+	}
+
+	for _, s := range settings {
+		fmt.Fprintf(p.out, "%s=%s\n", s.Name, s.display())
+	}
+}
+
+// display returns the value, masking all but the last four characters of secrets
+func (s Setting) display() string {
+	if !s.Secret || s.Value == "" {
+		return s.Value
+	}
+	if len(s.Value) <= 8 {
+		return strings.Repeat("*", len(s.Value))
+	}
+	return strings.Repeat("*", len(s.Value)-4) + s.Value[len(s.Value)-4:]
+}
+
+// PresentCache displays the files in the cache directory
+func (p *Presenter) PresentCache(dir string, files []CacheFile) {
+	if p.output == OutputJSON {
+		doc := outputCache{Dir: dir}
+		for _, f := range files {
+			doc.Files = append(doc.Files, outputCacheFile{Name: f.Name, Size: f.Size, Modified: formatTimestamp(f.Modified)})
+		}
+		p.writeJSON(p.out, doc)
+		return
+	}
+
+	if dir == "" {
+		fmt.Fprintln(p.out, "Caching is disabled: no cache directory is configured")
+		return
+	}
+	fmt.Fprintf(p.out, "Cache directory: %s\n", dir)
+	if len(files) == 0 {
+		fmt.Fprintln(p.out, "No cached files")
+		return
+	}
+
+	tw := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	for _, f := range files {
+		fmt.Fprintf(tw, "  %s\t%d bytes\t%s\n", f.Name, f.Size, f.Modified.Local().Format(time.DateTime))
+	}
+	p.flush(tw)
+}
+
+// PresentCacheCleared reports the cache files that were removed
+func (p *Presenter) PresentCacheCleared(dir string, removed []string) {
+	if p.output == OutputJSON {
+		p.writeJSON(p.out, outputCache{Dir: dir, Removed: removed})
+		return
+	}
+
+	if len(removed) == 0 {
+		fmt.Fprintln(p.out, "Nothing to clear")
+		return
+	}
+	fmt.Fprintf(p.out, "Removed %d cache files from %s\n", len(removed), dir)
+	if p.verbose {
+		for _, name := range removed {
+			fmt.Fprintf(p.out, "  %s\n", name)
+		}
+	}
+}
+
+// flush writes out buffered table output, reporting failures on stderr
+func (p *Presenter) flush(tw *tabwriter.Writer) {
+	if err := tw.Flush(); err != nil {
+		fmt.Fprintf(p.errOut, "Error: writing output: %v\n", err)
+	}
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+func TestPresenter_PresentCurrencies(t *testing.T) {
+	usd, ok := domain.DefaultRegistry().Lookup("USD")
+	require.True(t, ok)
+	btc, ok := domain.DefaultRegistry().Lookup("BTC")
+	require.True(t, ok)
+
+	var out bytes.Buffer
+	NewPresenter(false, WithWriters(&out, &bytes.Buffer{})).PresentCurrencies([]*domain.Currency{btc, usd})
+	assert.Equal(t, "SYMBOL  NAME       KIND    DECIMALS  SIGN\n"+
+		"BTC     Bitcoin    crypto  8         ₿\n"+
+		"USD     US Dollar  fiat    2         $\n", out.String())
+
+	out.Reset()
+	NewPresenter(false, WithOutput(OutputJSON), WithWriters(&out, &bytes.Buffer{})).PresentCurrencies([]*domain.Currency{usd})
+	assert.JSONEq(t, `{"currencies":[{"symbol":"USD","name":"US Dollar","kind":"fiat","decimals":2,"sign":"$"}]}`, out.String())
+}
+
+func TestPresenter_PresentSettings(t *testing.T) {
+	settings := []Setting{
+		{Name: "PROVIDER", Value: "coinmarketcap"},
+		{Name: "CMC_API_KEY", Value: "abcdef-123456", Secret: true},
+		{Name: "SHORT_KEY", Value: "abc", Secret: true},
+		{Name: "UNSET_KEY", Secret: true},
+	}
+
+	var out bytes.Buffer
+	NewPresenter(false, WithWriters(&out, &bytes.Buffer{})).PresentSettings(settings)
+	assert.Equal(t, "PROVIDER=coinmarketcap\nCMC_API_KEY=*********3456\nSHORT_KEY=***\nUNSET_KEY=\n", out.String())
+
+	out.Reset()
+	NewPresenter(false, WithOutput(OutputJSON), WithWriters(&out, &bytes.Buffer{})).PresentSettings(settings[:2])
+	assert.JSONEq(t, `{"settings":[{"name":"PROVIDER","value":"coinmarketcap"},{"name":"CMC_API_KEY","value":"*********3456"}]}`, out.String())
+}
+
+func TestPresenter_PresentCache(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	files := []CacheFile{{Name: "cmc-symbols.json", Size: 2048, Modified: modified}}
+
+	var out bytes.Buffer
+	NewPresenter(false, WithWriters(&out, &bytes.Buffer{})).PresentCache("/tmp/cache", files)
+	assert.Contains(t, out.String(), "Cache directory: /tmp/cache\n")
+	assert.Contains(t, out.String(), "cmc-symbols.json  2048 bytes")
+
+	out.Reset()
+	NewPresenter(false, WithWriters(&out, &bytes.Buffer{})).PresentCache("", nil)
+	assert.Equal(t, "Caching is disabled: no cache directory is configured\n", out.String())
+
+	out.Reset()
+	NewPresenter(false, WithOutput(OutputJSON), WithWriters(&out, &bytes.Buffer{})).PresentCache("/tmp/cache", files)
+	assert.JSONEq(t, `{"dir":"/tmp/cache","files":[{"name":"cmc-symbols.json","size":2048,"modified":"2024-03-01T12:00:00Z"}]}`, out.String())
+
+	out.Reset()
+	NewPresenter(true, WithWriters(&out, &bytes.Buffer{})).PresentCacheCleared("/tmp/cache", []string{"a.json", "b.json"})
+	assert.Equal(t, "Removed 2 cache files from /tmp/cache\n  a.json\n  b.json\n", out.String())
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// jsonFormatter writes results as a single JSON document
+type jsonFormatter struct{}
+
+// NewJSONFormatter creates the JSON formatter
+func NewJSONFormatter() Formatter {
+	return jsonFormatter{}
+}
+
+// Format writes results as {"results":[...]}
+func (f jsonFormatter) Format(w io.Writer, results []*domain.ConversionResult) error {
+	return f.FormatLines(w, results, nil)
+}
+
+// FormatLines writes results with the input line of each one
+func (jsonFormatter) FormatLines(w io.Writer, results []*domain.ConversionResult, lines []int) error {
+	return writeJSON(w, newOutputDocument(results, lines))
+}
+
+// newOutputDocument converts results into the output schema, numbering them
+// with their input lines when lines is set
+func newOutputDocument(results []*domain.ConversionResult, lines []int) outputDocument {
+	doc := outputDocument{Results: make([]outputResult, 0, len(results))}
+	for i, result := range results {
+		out := newOutputResult(result)
+		if lines != nil {
+			out.Line = lines[i]
+		}
+		doc.Results = append(doc.Results, out)
+	}
+	return doc
+}
+
+// writeJSON encodes v as a single line followed by a newline
+func writeJSON(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
@@ -1,103 +1,163 @@
 package cli
 
 import (
-	"flag"
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
 )
 
 const (
 	version = "1.0.0"
 )
 
+// Exit codes of the app
+const (
+	// ExitOK reports success
+	ExitOK = 0
+
+	// ExitFailure reports a command that failed, such as a conversion error
+	ExitFailure = 1
+
+	// ExitUsage reports invalid arguments or flags
+	ExitUsage = 2
+)
+
 // Args represents parsed command-line arguments
 type Args struct {
-	Amount      float64
+	// Command is the command to run; it is empty when --help or --version is
+	// given without one
+	Command string
+
+	Amount       domain.Amount
 	FromCurrency string
-	ToCurrency   string
-	Verbose     bool
-	ShowHelp    bool
-	ShowVersion bool
+	ToCurrencies []string
+	Rounding     domain.RoundingPolicy
+	At           time.Time
+	Provider     string
+	Consensus    bool
+	Bridge       bool
+	Output       OutputFormat
+	NoHeader     bool
+	Format       string
+	Verbose      bool
+	ShowHelp     bool
+	ShowVersion  bool
+
+	// Expression is set instead of Amount and FromCurrency when convert is
+	// given a sum of amounts such as "0.5 BTC + 2 ETH in EUR"
+	Expression *Expression
+
+	// BatchFile is read by the batch command; empty or "-" reads stdin
+	BatchFile string
+	Workers   int
+
+	// Start, End and Step select the points in time of the history command
+	Start time.Time
+	End   time.Time
+	Step  time.Duration
+
+	// Kind limits the list command to fiat or crypto currencies; empty lists both
+	Kind domain.CurrencyKind
+
+	// CacheAction is what the cache command does: info or clear
+	CacheAction string
+
+	// Addr is the address the serve command listens on
+	Addr string
 }
 
-// ParseArgs parses command-line arguments
-func ParseArgs(args []string) (*Args, error) {
-	// Create a new flag set for custom parsing
-	fs := flag.NewFlagSet("currency-converter", flag.ContinueOnError)
-
-	// Define flags
-	verbose := fs.Bool("verbose", false, "Enable verbose output")
-	help := fs.Bool("help", false, "Show help message")
-	version := fs.Bool("version", false, "Show version information")
+// UsageError reports invalid command-line arguments
+type UsageError struct {
+	// Command is the command the arguments were meant for; empty when none was named
+	Command string
 
-	// Parse flags
-	if err := fs.Parse(args); err != nil {
-		return nil, err
-	}
+	// Output is the --output format requested before parsing failed, text
+	// when none was given or it was invalid
+	Output OutputFormat
 
-	result := &Args{
-		Verbose:     *verbose,
-		ShowHelp:    *help,
-		ShowVersion: *version,
-	}
+	Err error
+}
 
-	// If help or version requested, return early
-	if result.ShowHelp || result.ShowVersion {
-		return result, nil
-	}
+// Error implements the error interface
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
 
-	// Get remaining arguments (non-flag arguments)
-	remaining := fs.Args()
+// Unwrap returns the underlying error
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
 
-	// Validate argument count
-	if len(remaining) != 3 {
-		return nil, fmt.Errorf("invalid number of arguments: expected 3 (amount, from_currency, to_currency), got %d", len(remaining))
+// ParseArgs parses command-line arguments. The first argument that is not a
+// flag names the command; when it is not a command name the arguments are an
+// implicit convert, so "app 100 USD BTC" keeps working. Flags may come before
+// or after the command name and between its arguments. Errors are returned
+// as a *UsageError.
+func ParseArgs(args []string) (*Args, error) {
+	name, implicit := CommandConvert, true
+	if i := commandIndex(args); i >= 0 {
+		name, implicit = args[i], false
+		args = append(args[:i:i], args[i+1:]...)
 	}
 
-	// Parse amount
-	amount, err := strconv.ParseFloat(remaining[0], 64)
-	if err != nil {
-		return nil, fmt.Errorf("invalid amount '%s': must be a number", remaining[0])
+	result, err := commandByName(name).parse(args)
+	if err == nil && commandByName(result.Command) == nil && result.Command != "" {
+		err = &UsageError{Output: result.Output, Err: fmt.Errorf("unknown command '%s'", result.Command)}
+	}
+	var usageErr *UsageError
+	if errors.As(err, &usageErr) {
+		usageErr.Command = name
+		if implicit {
+			usageErr.Command = ""
+		}
+		return nil, usageErr
 	}
 
-	if amount <= 0 {
-		return nil, fmt.Errorf("invalid amount '%f': must be greater than zero", amount)
+	// Plain --help and --version describe the whole app
+	if implicit && (result.ShowHelp || result.ShowVersion) {
+		result.Command = ""
 	}
+	return result, nil
+}
 
-	result.Amount = amount
-	result.FromCurrency = remaining[1]
-	result.ToCurrency = remaining[2]
+// HistoryTimes returns the points in time of the history command, from Start
+// to End in steps of Step
+func (a *Args) HistoryTimes() []time.Time {
+	var times []time.Time
+	for t := a.Start; !t.After(a.End); t = t.Add(a.Step) {
+		times = append(times, t)
+	}
+	return times
+}
 
-	return result, nil
+// parseTargets splits a comma-separated list of target currencies
+func parseTargets(arg string) ([]string, error) {
+	parts := strings.Split(arg, ",")
+	targets := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("invalid target currencies '%s': empty entry in list", arg)
+		}
+		targets = append(targets, part)
+	}
+	return targets, nil
 }
 
-// ShowHelp displays help message
-func ShowHelp() {
-	fmt.Println("Currency Conversion Utility")
-	fmt.Println()
-	fmt.Println("USAGE:")
-	fmt.Println("  app [options] <amount> <from_currency> <to_currency>")
-	fmt.Println()
-	fmt.Println("ARGUMENTS:")
-	fmt.Println("  amount          Amount to convert (must be > 0)")
-	fmt.Println("  from_currency   Source currency symbol (e.g., USD, BTC)")
-	fmt.Println("  to_currency     Target currency symbol (e.g., EUR, ETH)")
-	fmt.Println()
-	fmt.Println("OPTIONS:")
-	fmt.Println("  --help          Show this help message")
-	fmt.Println("  --version       Show version information")
-	fmt.Println("  --verbose       Enable verbose output with detailed information")
-	fmt.Println()
-	fmt.Println("EXAMPLES:")
-	fmt.Println("  app 123.45 USD BTC")
-	fmt.Println("  app --verbose 100 BTC USD")
-	fmt.Println("  app 50 EUR GBP")
-	fmt.Println()
-	fmt.Println("ENVIRONMENT VARIABLES:")
-	fmt.Println("  CMC_API_KEY     CoinMarketCap API key (required)")
-	fmt.Println("  CMC_API_URL     CoinMarketCap API base URL (optional)")
-	fmt.Println()
+// parseAt parses an as-of timestamp given either as RFC3339 or as a date,
+// which is taken as midnight UTC. Errors wrap domain.ErrInvalidTimestamp.
+func parseAt(arg string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, arg); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, arg); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%w '%s': expected RFC3339 (2024-03-01T15:04:05Z) or date (2024-03-01)", domain.ErrInvalidTimestamp, arg)
 }
 
 // ShowVersion displays version information
@@ -106,10 +166,17 @@ func ShowVersion() {
 	fmt.Println("Powered by CoinMarketCap API")
 }
 
-// ValidateEnvironment checks if required environment variables are set
-func ValidateEnvironment() error {
+// ValidateEnvironment checks if environment variables required by the
+// selected provider are set; an empty provider falls back to $PROVIDER
+func ValidateEnvironment(provider string) error {
+	if provider == "" {
+		provider = os.Getenv("PROVIDER")
+	}
+	if provider != "" && provider != "coinmarketcap" {
+		return nil
+	}
 	if os.Getenv("CMC_API_KEY") == "" {
-		return fmt.Errorf("CMC_API_KEY environment variable is not set")
+		return fmt.Errorf("%w: CMC_API_KEY environment variable is not set", domain.ErrAPIKeyMissing)
 	}
 	return nil
 }
@@ -2,7 +2,9 @@ package cli
 
 import (
 	"testing"
+	"time"
 
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,9 +19,10 @@ func TestParseArgs(t *testing.T) {
 			name: "valid args with amount and currencies",
 			args: []string{"123.45", "USD", "BTC"},
 			want: &Args{
-				Amount:       123.45,
+				Amount:       domain.MustParseAmount("123.45"),
 				FromCurrency: "USD",
-				ToCurrency:   "BTC",
+				ToCurrencies: []string{"BTC"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
 				Verbose:      false,
 				ShowHelp:     false,
 				ShowVersion:  false,
@@ -30,15 +33,27 @@ func TestParseArgs(t *testing.T) {
 			name: "valid args with verbose flag",
 			args: []string{"--verbose", "100", "BTC", "ETH"},
 			want: &Args{
-				Amount:       100,
+				Amount:       domain.MustParseAmount("100"),
 				FromCurrency: "BTC",
-				ToCurrency:   "ETH",
+				ToCurrencies: []string{"ETH"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
 				Verbose:      true,
 				ShowHelp:     false,
 				ShowVersion:  false,
 			},
 			wantErr: false,
 		},
+		{
+			name: "tickers starting with digits",
+			args: []string{"100", "1INCH", "USD"},
+			want: &Args{
+				Amount:       domain.MustParseAmount("100"),
+				FromCurrency: "1INCH",
+				ToCurrencies: []string{"USD"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+			},
+			wantErr: false,
+		},
 		{
 			name: "help flag",
 			args: []string{"--help"},
@@ -59,6 +74,23 @@ func TestParseArgs(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "comma-separated target currencies",
+			args: []string{"1", "BTC", "USD, EUR,GBP"},
+			want: &Args{
+				Amount:       domain.MustParseAmount("1"),
+				FromCurrency: "BTC",
+				ToCurrencies: []string{"USD", "EUR", "GBP"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+			},
+			wantErr: false,
+		},
+		{
+			name:    "empty entry in target list",
+			args:    []string{"1", "BTC", "USD,,EUR"},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name:    "too few arguments",
 			args:    []string{"100", "USD"},
@@ -77,6 +109,357 @@ func TestParseArgs(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "amount with 18 decimal places keeps full precision",
+			args: []string{"0.000000000000000001", "ETH", "USD"},
+			want: &Args{
+				Amount:       domain.MustParseAmount("0.000000000000000001"),
+				FromCurrency: "ETH",
+				ToCurrencies: []string{"USD"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+			},
+			wantErr: false,
+		},
+		{
+			name: "rounding mode and precision flags",
+			args: []string{"--rounding", "half-up", "--precision", "2", "1", "BTC", "USD"},
+			want: &Args{
+				Amount:       domain.MustParseAmount("1"),
+				FromCurrency: "BTC",
+				ToCurrencies: []string{"USD"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfUp).WithPrecision(2),
+			},
+			wantErr: false,
+		},
+		{
+			name:    "unknown rounding mode",
+			args:    []string{"--rounding", "sideways", "1", "BTC", "USD"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "negative precision",
+			args:    []string{"--precision", "-2", "1", "BTC", "USD"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "historical date",
+			args: []string{"--at", "2024-03-01", "2", "ETH", "EUR"},
+			want: &Args{
+				Amount:       domain.MustParseAmount("2"),
+				FromCurrency: "ETH",
+				ToCurrencies: []string{"EUR"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+				At:           time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: false,
+		},
+		{
+			name: "historical RFC3339 timestamp",
+			args: []string{"--at", "2024-03-01T15:30:00+02:00", "2", "ETH", "EUR"},
+			want: &Args{
+				Amount:       domain.MustParseAmount("2"),
+				FromCurrency: "ETH",
+				ToCurrencies: []string{"EUR"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+				At:           time.Date(2024, 3, 1, 13, 30, 0, 0, time.UTC),
+			},
+			wantErr: false,
+		},
+		{
+			name: "provider flag",
+			args: []string{"--provider", " CoinMarketCap ", "1", "BTC", "USD"},
+			want: &Args{
+				Amount:       domain.MustParseAmount("1"),
+				FromCurrency: "BTC",
+				ToCurrencies: []string{"USD"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+				Provider:     "coinmarketcap",
+			},
+			wantErr: false,
+		},
+		{
+			name: "consensus flag",
+			args: []string{"--consensus", "1000000", "EUR", "USD"},
+			want: &Args{
+				Amount:       domain.MustParseAmount("1000000"),
+				FromCurrency: "EUR",
+				ToCurrencies: []string{"USD"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+				Consensus:    true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "bridge flag",
+			args: []string{"--bridge", "1000", "DOGE", "CHF"},
+			want: &Args{
+				Amount:       domain.MustParseAmount("1000"),
+				FromCurrency: "DOGE",
+				ToCurrencies: []string{"CHF"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+				Bridge:       true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "json output",
+			args: []string{"--output", "json", "1", "BTC", "USD"},
+			want: &Args{
+				Amount:       domain.MustParseAmount("1"),
+				FromCurrency: "BTC",
+				ToCurrencies: []string{"USD"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+				Output:       OutputJSON,
+			},
+			wantErr: false,
+		},
+		{
+			name: "csv output without header",
+			args: []string{"--output", "csv", "--no-header", "1", "BTC", "USD"},
+			want: &Args{
+				Amount:       domain.MustParseAmount("1"),
+				FromCurrency: "BTC",
+				ToCurrencies: []string{"USD"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+				Output:       OutputCSV,
+				NoHeader:     true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "format template",
+			args: []string{"--format", "{{.ConvertedAmount}}", "1", "BTC", "USD"},
+			want: &Args{
+				Amount:       domain.MustParseAmount("1"),
+				FromCurrency: "BTC",
+				ToCurrencies: []string{"USD"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+				Format:       "{{.ConvertedAmount}}",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "format template with structured output",
+			args:    []string{"--format", "short", "--output", "json", "1", "BTC", "USD"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unknown output format",
+			args:    []string{"--output", "xml", "1", "BTC", "USD"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "credits command",
+			args: []string{"credits"},
+			want: &Args{
+				Command:  CommandCredits,
+				Rounding: domain.NewRoundingPolicy(domain.RoundHalfEven),
+			},
+			wantErr: false,
+		},
+		{
+			name: "batch from stdin",
+			args: []string{"--workers", "8", "batch"},
+			want: &Args{
+				Command:  CommandBatch,
+				Rounding: domain.NewRoundingPolicy(domain.RoundHalfEven),
+				Workers:  8,
+			},
+			wantErr: false,
+		},
+		{
+			name: "batch from file",
+			args: []string{"batch", "invoices.csv"},
+			want: &Args{
+				Command:   CommandBatch,
+				Rounding:  domain.NewRoundingPolicy(domain.RoundHalfEven),
+				BatchFile: "invoices.csv",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "batch with several files",
+			args:    []string{"batch", "a.csv", "b.csv"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "negative workers",
+			args:    []string{"--workers", "-1", "batch"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "explicit convert command",
+			args: []string{"convert", "--verbose", "1", "BTC", "USD"},
+			want: &Args{
+				Command:      CommandConvert,
+				Amount:       domain.MustParseAmount("1"),
+				FromCurrency: "BTC",
+				ToCurrencies: []string{"USD"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+				Verbose:      true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "credits with json output before the command",
+			args: []string{"--output", "json", "credits"},
+			want: &Args{
+				Command: CommandCredits,
+				Output:  OutputJSON,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "credits rejects csv output",
+			args:    []string{"credits", "--output", "csv"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "flag of another command",
+			args:    []string{"list", "--workers", "2"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "rates with default targets",
+			args: []string{"rates", "usd"},
+			want: &Args{
+				Command:      CommandRates,
+				Amount:       domain.MustParseAmount("1"),
+				FromCurrency: "usd",
+				ToCurrencies: []string{"EUR", "GBP", "JPY", "BTC", "ETH"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+			},
+			wantErr: false,
+		},
+		{
+			name: "rates with targets",
+			args: []string{"rates", "--output", "table", "EUR", "USD,CHF"},
+			want: &Args{
+				Command:      CommandRates,
+				Amount:       domain.MustParseAmount("1"),
+				FromCurrency: "EUR",
+				ToCurrencies: []string{"USD", "CHF"},
+				Rounding:     domain.NewRoundingPolicy(domain.RoundHalfEven),
+				Output:       OutputTable,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "rates without base",
+			args:    []string{"rates"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "list crypto",
+			args: []string{"list", "Crypto"},
+			want: &Args{
+				Command: CommandList,
+				Kind:    domain.CurrencyKindCrypto,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "list unknown kind",
+			args:    []string{"list", "stocks"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "cache defaults to info",
+			args: []string{"cache"},
+			want: &Args{
+				Command:     CommandCache,
+				CacheAction: CacheInfo,
+			},
+			wantErr: false,
+		},
+		{
+			name: "cache clear",
+			args: []string{"cache", "clear"},
+			want: &Args{
+				Command:     CommandCache,
+				CacheAction: CacheClear,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "unknown cache action",
+			args:    []string{"cache", "purge"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "serve address",
+			args: []string{"serve", "--addr", ":9090", "--provider", "ecb"},
+			want: &Args{
+				Command:  CommandServe,
+				Rounding: domain.NewRoundingPolicy(domain.RoundHalfEven),
+				Provider: "ecb",
+				Addr:     ":9090",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "config takes no arguments",
+			args:    []string{"config", "show"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "help command",
+			args: []string{"help"},
+			want: &Args{
+				ShowHelp: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "help for a command",
+			args: []string{"help", "rates"},
+			want: &Args{
+				Command:  CommandRates,
+				ShowHelp: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "help flag of a command",
+			args: []string{"history", "-h"},
+			want: &Args{
+				Command:  CommandHistory,
+				ShowHelp: true,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "help for an unknown command",
+			args:    []string{"help", "nope"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "version command",
+			args: []string{"version"},
+			want: &Args{
+				ShowVersion: true,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "malformed historical time",
+			args:    []string{"--at", "01/03/2024", "2", "ETH", "EUR"},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name:    "invalid amount (zero)",
 			args:    []string{"0", "USD", "BTC"},
@@ -99,12 +482,38 @@ func TestParseArgs(t *testing.T) {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.want.Amount, got.Amount)
+				assert.Equal(t, tt.want.Amount.String(), got.Amount.String())
 				assert.Equal(t, tt.want.FromCurrency, got.FromCurrency)
-				assert.Equal(t, tt.want.ToCurrency, got.ToCurrency)
+				assert.Equal(t, tt.want.ToCurrencies, got.ToCurrencies)
 				assert.Equal(t, tt.want.Verbose, got.Verbose)
+				if tt.want.Amount.Sign() > 0 {
+					assert.Equal(t, tt.want.Rounding, got.Rounding)
+				}
+				assert.True(t, tt.want.At.Equal(got.At), "at: want %v, got %v", tt.want.At, got.At)
+				assert.Equal(t, tt.want.Provider, got.Provider)
+				assert.Equal(t, tt.want.Consensus, got.Consensus)
+				assert.Equal(t, tt.want.Bridge, got.Bridge)
+				if tt.want.Output != "" {
+					assert.Equal(t, tt.want.Output, got.Output)
+				} else {
+					assert.Equal(t, OutputText, got.Output)
+				}
+				assert.Equal(t, tt.want.NoHeader, got.NoHeader)
+				assert.Equal(t, tt.want.Format, got.Format)
 				assert.Equal(t, tt.want.ShowHelp, got.ShowHelp)
 				assert.Equal(t, tt.want.ShowVersion, got.ShowVersion)
+				wantCommand := tt.want.Command
+				if wantCommand == "" && !tt.want.ShowHelp && !tt.want.ShowVersion {
+					wantCommand = CommandConvert
+				}
+				assert.Equal(t, wantCommand, got.Command)
+				assert.Equal(t, tt.want.Kind, got.Kind)
+				assert.Equal(t, tt.want.CacheAction, got.CacheAction)
+				assert.Equal(t, tt.want.BatchFile, got.BatchFile)
+				assert.Equal(t, tt.want.Workers, got.Workers)
+				if tt.want.Addr != "" {
+					assert.Equal(t, tt.want.Addr, got.Addr)
+				}
 			}
 		})
 	}
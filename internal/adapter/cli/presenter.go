@@ -2,66 +2,272 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
 )
 
 // Presenter handles output formatting
 type Presenter struct {
-	verbose bool
+	verbose   bool
+	output    OutputFormat
+	header    bool
+	formatter Formatter
+	out       io.Writer
+	errOut    io.Writer
+}
+
+// PresenterOption configures optional behaviour of Presenter
+type PresenterOption func(*Presenter)
+
+// WithOutput selects the output format; text is the default
+func WithOutput(format OutputFormat) PresenterOption {
+	return func(p *Presenter) {
+		p.output = format
+	}
+}
+
+// WithHeader controls the header row of csv, tsv and table output; it is on
+// by default
+func WithHeader(header bool) PresenterOption {
+	return func(p *Presenter) {
+		p.header = header
+	}
+}
+
+// WithFormatter writes results through formatter instead of the built-in
+// formatter of the output format
+func WithFormatter(formatter Formatter) PresenterOption {
+	return func(p *Presenter) {
+		p.formatter = formatter
+	}
+}
+
+// WithWriters redirects results to out and errors to errOut instead of
+// stdout and stderr
+func WithWriters(out, errOut io.Writer) PresenterOption {
+	return func(p *Presenter) {
+		p.out = out
+		p.errOut = errOut
+	}
 }
 
 // NewPresenter creates a new Presenter instance
-func NewPresenter(verbose bool) *Presenter {
-	return &Presenter{
+func NewPresenter(verbose bool, opts ...PresenterOption) *Presenter {
+	p := &Presenter{
 		verbose: verbose,
+		output:  OutputText,
+		header:  true,
+		out:     os.Stdout,
+		errOut:  os.Stderr,
+	}
+	for _, opt := range opts {
+		opt(p)
 	}
+	if p.formatter == nil {
+		p.formatter = NewFormatter(p.output, p.verbose, p.header)
+	}
+	return p
 }
 
 // PresentResult displays the conversion result
 func (p *Presenter) PresentResult(result *domain.ConversionResult) {
-	if p.verbose {
-		p.presentVerbose(result)
+	p.PresentResults([]*domain.ConversionResult{result})
+}
+
+// PresentResults displays the results of a multi-target conversion
+func (p *Presenter) PresentResults(results []*domain.ConversionResult) {
+	if err := p.formatter.Format(p.out, results); err != nil {
+		fmt.Fprintf(p.errOut, "Error: writing output: %v\n", err)
+	}
+}
+
+// PresentBatch displays the converted lines of a batch; lines[i] is the
+// input line of results[i]. Formatters implementing LineFormatter number
+// every result so that failed lines leave a visible gap.
+func (p *Presenter) PresentBatch(results []*domain.ConversionResult, lines []int) {
+	formatter, ok := p.formatter.(LineFormatter)
+	if !ok {
+		p.PresentResults(results)
+		return
+	}
+	if err := formatter.FormatLines(p.out, results, lines); err != nil {
+		fmt.Fprintf(p.errOut, "Error: writing output: %v\n", err)
+	}
+}
+
+// PresentTotals displays the value of an expression in each target currency
+// and, in verbose mode, the value of every term
+func (p *Presenter) PresentTotals(totals []*Total) {
+	if p.output == OutputJSON {
+		doc := outputTotals{Totals: make([]outputTotal, 0, len(totals))}
+		for _, total := range totals {
+			doc.Totals = append(doc.Totals, newOutputTotal(total))
+		}
+		p.writeJSON(p.out, doc)
+		return
+	}
+
+	tw := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	for _, total := range totals {
+		fmt.Fprintf(tw, "%s = %s %s\n", total.Expression.sum(), formatAmount(total.Amount, total.Currency), total.Currency.String())
+		if !p.verbose {
+			continue
+		}
+		for i, result := range total.Results {
+			from, to := result.FromCurrency.String(), result.ToCurrency.String()
+			line := fmt.Sprintf("  %s %s %s\t= %s %s", total.Expression.Terms[i].operator(), result.OriginalAmount, from,
+				formatAmount(result.ConvertedAmount, result.ToCurrency), to)
+			if from != to {
+				line += fmt.Sprintf("\t1 %s = %s %s", from, result.ExchangeRate, to)
+				if result.Source != "" {
+					line += " (" + result.Source + ")"
+				}
+			}
+			fmt.Fprintln(tw, line)
+		}
+	}
+	p.flush(tw)
+}
+
+// relative returns part / whole, or zero when whole is zero
+func relative(part, whole domain.Amount) domain.Amount {
+	ratio, err := part.Div(whole)
+	if err != nil {
+		return domain.Amount{}
+	}
+	return ratio
+}
+
+// percent renders a fraction such as 0.0123 as "1.23"
+func percent(fraction domain.Amount) string {
+	return fraction.Mul(domain.NewAmountFromInt(100)).StringFixed(2)
+}
+
+// describeAsset names the concrete CoinMarketCap asset behind a currency,
+// e.g. "Ethereum (ETH, CMC ID 1027)"
+func describeAsset(currency *domain.Currency) string {
+	if currency.CMCID == 0 {
+		return ""
+	}
+	name := currency.Name
+	if name == "" {
+		name = currency.String()
+	}
+	return fmt.Sprintf("%s (%s, CMC ID %d)", name, currency.String(), currency.CMCID)
+}
+
+// formatAmount renders an already rounded amount. Fiat amounts keep the
+// places the rounding policy gave them; crypto amounts drop trailing zeros.
+func formatAmount(amount domain.Amount, currency *domain.Currency) string {
+	if currency.IsFiat() {
+		return amount.String()
+	}
+	return amount.Normalize().String()
+}
+
+// PresentCredits displays the CoinMarketCap API credits spent against the budgets
+func (p *Presenter) PresentCredits(usage domain.CreditUsage) {
+	if p.output == OutputJSON {
+		p.writeJSON(p.out, outputCredits{
+			Day:           usage.Day.Format(time.DateOnly),
+			DayCredits:    usage.DayCredits,
+			DailyBudget:   usage.DailyBudget,
+			Month:         usage.Day.Format("2006-01"),
+			MonthCredits:  usage.MonthCredits,
+			MonthlyBudget: usage.MonthlyBudget,
+		})
+		return
+	}
+
+	fmt.Fprintln(p.out, "CoinMarketCap API credits")
+	fmt.Fprintf(p.out, "Today (%s):   %s\n", usage.Day.Format(time.DateOnly), creditLine(usage.DayCredits, usage.DailyBudget))
+	fmt.Fprintf(p.out, "This month (%s): %s\n", usage.Day.Format("2006-01"), creditLine(usage.MonthCredits, usage.MonthlyBudget))
+}
+
+// creditLine renders credits used against a budget, where zero is unlimited
+func creditLine(used, budget int) string {
+	if budget == 0 {
+		return fmt.Sprintf("%d used (no budget)", used)
+	}
+	share := percent(relative(domain.NewAmountFromInt(int64(used)), domain.NewAmountFromInt(int64(budget))))
+	return fmt.Sprintf("%d of %d used (%s%%), %d left", used, budget, share, max(budget-used, 0))
+}
+
+// PresentRetry reports a failed API attempt that is about to be retried; it
+// matches retry.OnRetryFunc so verbose runs can show why a conversion is slow
+func (p *Presenter) PresentRetry(attempt int, err error, delay time.Duration) {
+	if p.output == OutputJSON {
+		p.writeJSON(p.errOut, outputRetry{Retry: outputRetryDetail{
+			Attempt: attempt,
+			Code:    domain.ErrorCode(err),
+			Message: err.Error(),
+			Delay:   delay.Round(time.Millisecond).String(),
+		}})
+		return
+	}
+	fmt.Fprintf(p.errOut, "Retry: attempt %d failed (%v), retrying in %s\n", attempt, err, delay.Round(time.Millisecond))
+}
+
+// PresentLineError reports a batch input line that could not be converted
+func (p *Presenter) PresentLineError(line int, err error) {
+	if p.output == OutputJSON {
+		p.writeJSON(p.errOut, outputError{Error: outputErrorDetail{
+			Code:    domain.ErrorCode(err),
+			Message: err.Error(),
+			Line:    line,
+		}})
+		return
+	}
+	fmt.Fprintf(p.errOut, "Error: line %d: %v\n", line, err)
+}
+
+// usageErrorCode is the JSON error code of invalid arguments that wrap no
+// domain error
+const usageErrorCode = "invalid_usage"
+
+// PresentUsageError reports invalid arguments, in text with a pointer to the
+// help of the command they were meant for
+func (p *Presenter) PresentUsageError(err *UsageError) {
+	if p.output == OutputJSON {
+		code := domain.ErrorCode(err)
+		if code == domain.UnknownErrorCode {
+			code = usageErrorCode
+		}
+		p.writeJSON(p.errOut, outputError{Error: outputErrorDetail{Code: code, Message: err.Error()}})
+		return
+	}
+
+	fmt.Fprintf(p.errOut, "Error: %v\n", err)
+	if err.Command != "" {
+		fmt.Fprintf(p.errOut, "\nRun 'app help %s' for usage information\n", err.Command)
 	} else {
-		p.presentSimple(result)
-	}
-}
-
-// presentSimple displays a simple one-line result
-func (p *Presenter) presentSimple(result *domain.ConversionResult) {
-	fmt.Printf("%.8g %s = %.8g %s\n",
-		result.OriginalAmount,
-		result.FromCurrency.String(),
-		result.ConvertedAmount,
-		result.ToCurrency.String(),
-	)
-}
-
-// presentVerbose displays detailed conversion information
-func (p *Presenter) presentVerbose(result *domain.ConversionResult) {
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Println("CURRENCY CONVERSION RESULT")
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Printf("Original Amount:    %.8g %s\n", result.OriginalAmount, result.FromCurrency.String())
-	fmt.Printf("Converted Amount:   %.8g %s\n", result.ConvertedAmount, result.ToCurrency.String())
-	fmt.Println(strings.Repeat("-", 60))
-	fmt.Printf("Exchange Rate:      1 %s = %.8g %s\n",
-		result.FromCurrency.String(),
-		result.ExchangeRate,
-		result.ToCurrency.String(),
-	)
-	fmt.Printf("Last Updated:       %s\n", result.LastUpdated.Format("2006-01-02 15:04:05 MST"))
-	fmt.Printf("Query Time:         %s\n", result.Timestamp.Format("2006-01-02 15:04:05 MST"))
-	fmt.Println(strings.Repeat("=", 60))
+		fmt.Fprintln(p.errOut, "\nRun 'app --help' for usage information")
+	}
 }
 
 // PresentError displays an error message in a user-friendly format
 func (p *Presenter) PresentError(err error) {
+	if p.output == OutputJSON {
+		p.writeJSON(p.errOut, outputError{Error: outputErrorDetail{
+			Code:    domain.ErrorCode(err),
+			Message: err.Error(),
+		}})
+		return
+	}
 	if p.verbose {
-		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		fmt.Fprintf(p.errOut, "ERROR: %v\n", err)
 	} else {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(p.errOut, "Error: %v\n", err)
+	}
+}
+
+// writeJSON writes v as JSON, reporting encoding failures on stderr
+func (p *Presenter) writeJSON(w io.Writer, v any) {
+	if err := writeJSON(w, v); err != nil {
+		fmt.Fprintf(p.errOut, "Error: writing output: %v\n", err)
 	}
 }
@@ -0,0 +1,465 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// Query is a conversion typed the way people say it, such as "100 usd to
+// btc", "$100 in BTC", "1.5k EUR in JPY" or "100 USD BTC,EUR": an expression
+// of one amount that is not subtracted
+type Query struct {
+	Amount       domain.Amount
+	FromCurrency string
+	ToCurrencies []string
+}
+
+// queryError explains why input could not be parsed
+func queryError(input string, err error) error {
+	return fmt.Errorf("invalid conversion '%s': %w", strings.TrimSpace(input), err)
+}
+
+// tokenKind classifies the tokens of a query
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenCode
+	tokenSign
+	tokenConnector
+	tokenComma
+	tokenOperator
+)
+
+// token is one lexical element of a query
+type token struct {
+	kind tokenKind
+	text string
+
+	// amount is the value of a tokenNumber
+	amount domain.Amount
+
+	// joined is set when no space separates the token from the one before
+	joined bool
+}
+
+// describe names the token for error messages
+func (t token) describe() string {
+	if t.kind == tokenEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("'%s'", t.text)
+}
+
+// multipliers scale an amount by its suffix, e.g. 1.5k
+var multipliers = map[string]domain.Amount{
+	"k":  domain.NewAmountFromInt(1_000),
+	"m":  domain.NewAmountFromInt(1_000_000),
+	"b":  domain.NewAmountFromInt(1_000_000_000),
+	"bn": domain.NewAmountFromInt(1_000_000_000),
+}
+
+// connectors separate the amount from the target currencies
+var connectors = map[string]bool{"to": true, "in": true, "into": true, "as": true}
+
+// operators combine the amounts of an expression; × is a synonym for *
+var operators = map[rune]bool{'+': true, '-': true, '*': true, '×': true}
+
+// lexQuery splits input into tokens
+func lexQuery(input string) ([]token, error) {
+	var tokens []token
+	spaced := false
+	emit := func(t token) {
+		t.joined = len(tokens) > 0 && !spaced
+		tokens = append(tokens, t)
+		spaced = false
+	}
+
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		switch {
+		case unicode.IsSpace(r):
+			spaced = true
+			i += size
+			continue
+		case r == ',':
+			emit(token{kind: tokenComma, text: ","})
+			i += size
+			continue
+		case r == '→':
+			emit(token{kind: tokenConnector, text: "→"})
+			i += size
+			continue
+		case strings.HasPrefix(input[i:], "->"):
+			emit(token{kind: tokenConnector, text: "->"})
+			i += 2
+			continue
+		case operators[r]:
+			emit(token{kind: tokenOperator, text: string(r)})
+			i += size
+			continue
+		}
+
+		if sign := currencySigns().match(input[i:]); sign != "" {
+			emit(token{kind: tokenSign, text: sign})
+			i += len(sign)
+			continue
+		}
+
+		var t token
+		var err error
+		switch {
+		case isDigit(r) || r == '.' && i+1 < len(input) && isDigit(rune(input[i+1])):
+			t, err = lexNumber(input[i:])
+		case unicode.IsLetter(r):
+			t = lexWord(input[i:])
+		default:
+			return nil, fmt.Errorf("%w: unexpected '%c'", domain.ErrMalformedInput, r)
+		}
+		if err != nil {
+			return nil, err
+		}
+		emit(t)
+		i += len(t.text)
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// lexNumber reads a decimal number with an optional exponent and multiplier
+// suffix from the start of s
+func lexNumber(s string) (token, error) {
+	end := 0
+	for end < len(s) && (isDigit(rune(s[end])) || s[end] == '.') {
+		end++
+	}
+
+	// Thousands and decimal separators differ by locale, so 1,500 is refused
+	if end+1 < len(s) && s[end] == ',' && isDigit(rune(s[end+1])) {
+		return token{}, fmt.Errorf("%w: ambiguous number '%s': write it without separators and with a decimal point, e.g. 1500 or 1.5",
+			domain.ErrMalformedInput, s[:end+1+digitRun(s[end+1:])])
+	}
+
+	// An exponent needs digits, so "1eur" is 1 EUR
+	if end < len(s) && (s[end] == 'e' || s[end] == 'E') {
+		exp := end + 1
+		if exp < len(s) && (s[exp] == '+' || s[exp] == '-') {
+			exp++
+		}
+		if n := digitRun(s[exp:]); n > 0 {
+			end = exp + n
+		}
+	}
+
+	word := s[:max(end, wordEnd(s))]
+	amount, err := domain.ParseAmount(s[:end])
+	if err != nil {
+		return token{}, fmt.Errorf("invalid amount '%s': %w", word, err)
+	}
+
+	// A multiplier must not run into a currency code, so "1btc" is 1 BTC
+	for suffix, factor := range multipliers {
+		rest := s[end:]
+		if len(rest) >= len(suffix) && strings.EqualFold(rest[:len(suffix)], suffix) && !startsWithLetter(rest[len(suffix):]) {
+			end += len(suffix)
+			amount = amount.Mul(factor)
+			break
+		}
+	}
+
+	// Letters followed by digits, as in 0x10, are no currency code
+	if letters := letterRun(s[end:]); letters > 0 && digitRun(s[end+letters:]) > 0 {
+		return token{}, fmt.Errorf("%w: unrecognized amount '%s'", domain.ErrMalformedInput, word)
+	}
+	return token{kind: tokenNumber, text: s[:end], amount: amount}, nil
+}
+
+// wordEnd returns the length of the run of characters s starts with, up to
+// the next space, comma or operator
+func wordEnd(s string) int {
+	for i, r := range s {
+		if unicode.IsSpace(r) || r == ',' || r == '→' || operators[r] {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// lexWord reads a currency code, an explicit asset identifier such as
+// slug:usd-coin or a connector word from the start of s
+func lexWord(s string) token {
+	end, identifier := 0, false
+	for end < len(s) {
+		r, size := utf8.DecodeRuneInString(s[end:])
+		next := end + size
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+		case r == ':' && !identifier && next < len(s) && isWordRune(s[next:]):
+			identifier = true
+		case (r == '-' || r == '_') && identifier && next < len(s) && isWordRune(s[next:]):
+		default:
+			return wordToken(s[:end])
+		}
+		end = next
+	}
+	return wordToken(s[:end])
+}
+
+// wordToken classifies a word as a connector or a currency code
+func wordToken(word string) token {
+	if connectors[strings.ToLower(word)] {
+		return token{kind: tokenConnector, text: word}
+	}
+	return token{kind: tokenCode, text: word}
+}
+
+// isDigit reports whether r is an ASCII digit
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// digitRun returns the number of ASCII digits s starts with
+func digitRun(s string) int {
+	n := 0
+	for n < len(s) && isDigit(rune(s[n])) {
+		n++
+	}
+	return n
+}
+
+// letterRun returns the number of ASCII letters s starts with
+func letterRun(s string) int {
+	n := 0
+	for n < len(s) && (s[n] >= 'a' && s[n] <= 'z' || s[n] >= 'A' && s[n] <= 'Z') {
+		n++
+	}
+	return n
+}
+
+// startsWithLetter reports whether s starts with a letter
+func startsWithLetter(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return s != "" && unicode.IsLetter(r)
+}
+
+// isWordRune reports whether s starts with a letter or digit
+func isWordRune(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// queryParser is a recursive-descent parser over the tokens of a query or
+// expression
+type queryParser struct {
+	tokens []token
+	pos    int
+}
+
+// peek returns the next token without consuming it
+func (p *queryParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next consumes and returns the next token
+func (p *queryParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// peekAt returns the token n places ahead without consuming anything
+func (p *queryParser) peekAt(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+// last returns the token consumed most recently
+func (p *queryParser) last() token {
+	return p.tokens[max(p.pos-1, 0)]
+}
+
+// peekCurrency returns the token where a currency is expected, and how many
+// tokens it spans, without consuming it. Tickers that start with digits, such
+// as 1INCH, lex as an integer joined to a word and are read as one code.
+func (p *queryParser) peekCurrency() (token, int) {
+	t := p.peek()
+	if t.kind == tokenNumber && digitRun(t.text) == len(t.text) {
+		if word := p.peekAt(1); word.kind == tokenCode && word.joined {
+			return token{kind: tokenCode, text: t.text + word.text}, 2
+		}
+	}
+	return t, 1
+}
+
+// nextCurrency consumes the token where a currency is expected
+func (p *queryParser) nextCurrency() token {
+	t, n := p.peekCurrency()
+	if n == 1 {
+		return p.next()
+	}
+	p.pos += n
+	return t
+}
+
+// parseMoney parses: number currency | currency number [currency]
+func (p *queryParser) parseMoney() (domain.Amount, string, error) {
+	first := p.next()
+	switch first.kind {
+	case tokenNumber:
+		amount, err := positiveAmount(first)
+		if err != nil {
+			return domain.Amount{}, "", err
+		}
+		t := p.nextCurrency()
+		if !isCurrency(t) {
+			return domain.Amount{}, "", fmt.Errorf("%w: expected a currency after %s, got %s", domain.ErrMalformedInput, first.describe(), t.describe())
+		}
+		from, err := currencyOf(t)
+		return amount, from, err
+
+	case tokenCode, tokenSign:
+		from, err := currencyOf(first)
+		if err != nil {
+			return domain.Amount{}, "", err
+		}
+		number := p.next()
+		if number.kind != tokenNumber {
+			return domain.Amount{}, "", fmt.Errorf("%w: expected an amount after %s, got %s", domain.ErrMalformedInput, first.describe(), number.describe())
+		}
+		amount, err := positiveAmount(number)
+		if err != nil {
+			return domain.Amount{}, "", err
+		}
+
+		// "$100 USD" repeats the currency; "$100 EUR" leaves it unclear
+		if repeated, n := p.peekCurrency(); isCurrency(repeated) {
+			again, err := currencyOf(repeated)
+			if err != nil {
+				return domain.Amount{}, "", err
+			}
+			if !strings.EqualFold(again, from) {
+				return domain.Amount{}, "", fmt.Errorf("%w: %s and %s name different currencies; write e.g. '%s%s to %s'",
+					domain.ErrMalformedInput, first.describe(), repeated.describe(), first.text, number.text, repeated.text)
+			}
+			p.pos += n
+		}
+		return amount, from, nil
+
+	default:
+		return domain.Amount{}, "", fmt.Errorf("%w: expected an amount, got %s", domain.ErrMalformedInput, first.describe())
+	}
+}
+
+// parseCurrencies parses: currency {"," currency} up to the end of input
+func (p *queryParser) parseCurrencies(after token) ([]string, error) {
+	var targets []string
+	for {
+		t := p.nextCurrency()
+		if !isCurrency(t) {
+			return nil, fmt.Errorf("%w: expected a target currency after %s, got %s", domain.ErrMalformedInput, after.describe(), t.describe())
+		}
+		target, err := currencyOf(t)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+
+		switch sep := p.next(); sep.kind {
+		case tokenEOF:
+			return targets, nil
+		case tokenComma:
+			after = sep
+		case tokenCode, tokenSign:
+			return nil, fmt.Errorf("%w: unexpected %s after %s; separate target currencies with commas", domain.ErrMalformedInput, sep.describe(), t.describe())
+		default:
+			return nil, fmt.Errorf("%w: unexpected %s after %s", domain.ErrMalformedInput, sep.describe(), t.describe())
+		}
+	}
+}
+
+// isCurrency reports whether t names a currency
+func isCurrency(t token) bool {
+	return t.kind == tokenCode || t.kind == tokenSign
+}
+
+// currencyOf returns the currency a code or sign token stands for
+func currencyOf(t token) (string, error) {
+	if t.kind == tokenCode {
+		return t.text, nil
+	}
+
+	symbols := currencySigns().bySign[t.text]
+	if len(symbols) > 1 {
+		return "", fmt.Errorf("%w: the sign '%s' is used by %s; write the currency code instead",
+			domain.ErrMalformedInput, t.text, strings.Join(symbols, ", "))
+	}
+	return symbols[0], nil
+}
+
+// positiveAmount returns the amount of a number token, which must be positive
+func positiveAmount(t token) (domain.Amount, error) {
+	if t.amount.Sign() <= 0 {
+		return domain.Amount{}, fmt.Errorf("invalid amount '%s': must be greater than zero", t.text)
+	}
+	return t.amount, nil
+}
+
+// signTable maps currency signs such as € to the currencies that use them
+type signTable struct {
+	bySign map[string][]string
+
+	// signs is sorted longest first so CN¥ wins over ¥
+	signs []string
+}
+
+// currencySigns returns the signs of the default registry
+var currencySigns = sync.OnceValue(func() *signTable {
+	return newSignTable(domain.DefaultRegistry().All())
+})
+
+// newSignTable indexes the signs of currencies
+func newSignTable(currencies []*domain.Currency) *signTable {
+	table := &signTable{bySign: make(map[string][]string)}
+	for _, c := range currencies {
+		if c.Sign == "" {
+			continue
+		}
+		if _, ok := table.bySign[c.Sign]; !ok {
+			table.signs = append(table.signs, c.Sign)
+		}
+		table.bySign[c.Sign] = append(table.bySign[c.Sign], c.Symbol)
+	}
+	sort.Slice(table.signs, func(i, j int) bool {
+		if len(table.signs[i]) != len(table.signs[j]) {
+			return len(table.signs[i]) > len(table.signs[j])
+		}
+		return table.signs[i] < table.signs[j]
+	})
+	return table
+}
+
+// match returns the sign s starts with, or "". A sign that ends in a letter,
+// such as kr, must not run into a word, so KRW is not read as kr and W.
+func (t *signTable) match(s string) string {
+	for _, sign := range t.signs {
+		if !strings.HasPrefix(s, sign) {
+			continue
+		}
+		last, _ := utf8.DecodeLastRuneInString(sign)
+		if unicode.IsLetter(last) && startsWithLetter(s[len(sign):]) {
+			continue
+		}
+		return sign
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+func TestParseExpression_Query(t *testing.T) {
+	tests := []struct {
+		input  string
+		amount string
+		from   string
+		to     []string
+	}{
+		{input: "100 USD BTC", amount: "100", from: "USD", to: []string{"BTC"}},
+		{input: "100 usd to btc", amount: "100", from: "usd", to: []string{"btc"}},
+		{input: "$100 in BTC", amount: "100", from: "USD", to: []string{"BTC"}},
+		{input: "100$ into EUR", amount: "100", from: "USD", to: []string{"EUR"}},
+		{input: "1.5k EUR in JPY", amount: "1500", from: "EUR", to: []string{"JPY"}},
+		{input: "2.5M usd as eur", amount: "2500000", from: "usd", to: []string{"eur"}},
+		{input: "1bn JPY to USD", amount: "1000000000", from: "JPY", to: []string{"USD"}},
+		{input: "1e-3 BTC→USD", amount: "0.001", from: "BTC", to: []string{"USD"}},
+		{input: "1E+2 ETH -> EUR", amount: "100", from: "ETH", to: []string{"EUR"}},
+		{input: "€50 to £", amount: "50", from: "EUR", to: []string{"GBP"}},
+		{input: "¥1000 in ₿", amount: "1000", from: "JPY", to: []string{"BTC"}},
+		{input: "CN¥100 to USD", amount: "100", from: "CNY", to: []string{"USD"}},
+		{input: "₿0.5 in USD", amount: "0.5", from: "BTC", to: []string{"USD"}},
+		{input: "100 zł to EUR", amount: "100", from: "PLN", to: []string{"EUR"}},
+		{input: "$100 USD to EUR", amount: "100", from: "USD", to: []string{"EUR"}},
+		{input: "EUR 50 to USD", amount: "50", from: "EUR", to: []string{"USD"}},
+		{input: "100usd to btc", amount: "100", from: "usd", to: []string{"btc"}},
+		{input: "1btc in usd", amount: "1", from: "btc", to: []string{"usd"}},
+		{input: "1 BTC to USD, EUR ,GBP", amount: "1", from: "BTC", to: []string{"USD", "EUR", "GBP"}},
+		{input: "1 BTC to KRW", amount: "1", from: "BTC", to: []string{"KRW"}},
+		{input: ".5 ETH to USD", amount: "0.5", from: "ETH", to: []string{"USD"}},
+		{input: "2 id:1027 to slug:usd-coin", amount: "2", from: "id:1027", to: []string{"slug:usd-coin"}},
+		{input: "100 1INCH USD", amount: "100", from: "1INCH", to: []string{"USD"}},
+		{input: "100 USD 1INCH", amount: "100", from: "USD", to: []string{"1INCH"}},
+		{input: "5k 1000SATS in usd,1inch", amount: "5000", from: "1000SATS", to: []string{"usd", "1inch"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := ParseExpression(tt.input)
+			require.NoError(t, err)
+			query, ok := expr.Query()
+			require.True(t, ok)
+
+			assert.True(t, domain.MustParseAmount(tt.amount).Equal(query.Amount), "amount: want %s, got %s", tt.amount, query.Amount)
+			assert.Equal(t, tt.from, query.FromCurrency)
+			assert.Equal(t, tt.to, query.ToCurrencies)
+		})
+	}
+}
+
+func TestParseExpression_QueryErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{input: "", wantErr: "expected an amount, got end of input"},
+		{input: "USD to BTC", wantErr: "expected an amount after 'USD', got 'to'"},
+		{input: "100 to BTC", wantErr: "expected a currency after '100', got 'to'"},
+		{input: "100 USD to", wantErr: "expected a target currency after 'to', got end of input"},
+		{input: "100 USD to BTC EUR", wantErr: "separate target currencies with commas"},
+		{input: "100 USD to BTC,", wantErr: "expected a target currency after ','"},
+		{input: "100 USD to in BTC", wantErr: "expected a target currency after 'to', got 'in'"},
+		{input: "1,500 USD to EUR", wantErr: "ambiguous number '1,500'"},
+		{input: "1,5 EUR to USD", wantErr: "ambiguous number '1,5'"},
+		{input: "kr100 to EUR", wantErr: "the sign 'kr' is used by DKK, NOK, SEK"},
+		{input: "$100 EUR", wantErr: "'$' and 'EUR' name different currencies; write e.g. '$100 to EUR'"},
+		{input: "0 USD to EUR", wantErr: "invalid amount '0': must be greater than zero"},
+		{input: "-5 USD to EUR", wantErr: "invalid amount '-5 USD'"},
+		{input: "100 USD @ EUR", wantErr: "unexpected '@'"},
+		{input: "1.2.3 USD to EUR", wantErr: "invalid amount '1.2.3'"},
+		{input: "0x10 USD BTC", wantErr: "unrecognized amount '0x10'"},
+		{input: "1.5e3x2 USD BTC", wantErr: "unrecognized amount '1.5e3x2'"},
+		{input: "100 200 USD", wantErr: "expected a currency after '100', got '200'"},
+		{input: "$100 1INCH", wantErr: "'$' and '1INCH' name different currencies"},
+		{input: "100 1.5INCH to USD", wantErr: "expected a currency after '100', got '1.5'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseExpression(tt.input)
+			require.Error(t, err)
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.True(t, strings.HasPrefix(err.Error(), "invalid conversion '"), err.Error())
+		})
+	}
+}
+
+func TestParseExpression_AmbiguityIsMalformedInput(t *testing.T) {
+	for _, input := range []string{"1,500 USD to EUR", "kr100 to EUR", "$100 EUR", "100 USD BTC EUR"} {
+		_, err := ParseExpression(input)
+		assert.ErrorIs(t, err, domain.ErrMalformedInput, input)
+	}
+}
+
+func TestSignTable_Match(t *testing.T) {
+	table := currencySigns()
+
+	assert.Equal(t, "CN¥", table.match("CN¥100"))
+	assert.Equal(t, "¥", table.match("¥100"))
+	assert.Equal(t, "kr", table.match("kr 100"))
+	assert.Equal(t, "", table.match("krw"))
+	assert.Equal(t, "R", table.match("R100"))
+	assert.Equal(t, "", table.match("RUB"))
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// The types below are the documented JSON and YAML output schema. Fields may
+// be added but existing ones keep their names and meaning. Amounts and rates
+// are decimal strings so no precision is lost; timestamps are RFC3339 in UTC.
+
+// outputDocument is the document written to stdout for a conversion
+type outputDocument struct {
+	Results []outputResult `json:"results" yaml:"results"`
+}
+
+// outputResult is one converted amount
+type outputResult struct {
+	Line            int            `json:"line,omitempty" yaml:"line,omitempty"`
+	Amount          string         `json:"amount" yaml:"amount"`
+	From            outputCurrency `json:"from" yaml:"from"`
+	ConvertedAmount string         `json:"converted_amount" yaml:"converted_amount"`
+	To              outputCurrency `json:"to" yaml:"to"`
+	Rate            string         `json:"rate" yaml:"rate"`
+	Source          string         `json:"source,omitempty" yaml:"source,omitempty"`
+	AsOf            string         `json:"as_of,omitempty" yaml:"as_of,omitempty"`
+	LastUpdated     string         `json:"last_updated" yaml:"last_updated"`
+	QueryTime       string         `json:"query_time" yaml:"query_time"`
+	Route           []outputLeg    `json:"route,omitempty" yaml:"route,omitempty"`
+	Spread          string         `json:"spread,omitempty" yaml:"spread,omitempty"`
+	Quotes          []outputQuote  `json:"quotes,omitempty" yaml:"quotes,omitempty"`
+}
+
+// outputCurrency identifies a currency or crypto asset
+type outputCurrency struct {
+	Symbol string `json:"symbol" yaml:"symbol"`
+	Name   string `json:"name,omitempty" yaml:"name,omitempty"`
+	CMCID  int    `json:"cmc_id,omitempty" yaml:"cmc_id,omitempty"`
+}
+
+// outputLeg is one step of a conversion routed through bridge currencies
+type outputLeg struct {
+	From   string `json:"from" yaml:"from"`
+	To     string `json:"to" yaml:"to"`
+	Rate   string `json:"rate" yaml:"rate"`
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// outputQuote is one provider's answer behind a consensus rate
+type outputQuote struct {
+	Source    string `json:"source" yaml:"source"`
+	Rate      string `json:"rate,omitempty" yaml:"rate,omitempty"`
+	Deviation string `json:"deviation,omitempty" yaml:"deviation,omitempty"`
+	Rejected  bool   `json:"rejected,omitempty" yaml:"rejected,omitempty"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// outputError is the document written to stderr when a command fails
+type outputError struct {
+	Error outputErrorDetail `json:"error"`
+}
+
+// outputErrorDetail carries the machine-readable code of a domain error
+type outputErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// outputRetry is written to stderr in verbose mode for every retried API call
+type outputRetry struct {
+	Retry outputRetryDetail `json:"retry"`
+}
+
+// outputRetryDetail describes a failed attempt that is about to be retried
+type outputRetryDetail struct {
+	Attempt int    `json:"attempt"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Delay   string `json:"delay"`
+}
+
+// outputCredits reports API credit usage; zero budgets are unlimited
+type outputCredits struct {
+	Day           string `json:"day" yaml:"day"`
+	DayCredits    int    `json:"day_credits" yaml:"day_credits"`
+	DailyBudget   int    `json:"daily_budget" yaml:"daily_budget"`
+	Month         string `json:"month" yaml:"month"`
+	MonthCredits  int    `json:"month_credits" yaml:"month_credits"`
+	MonthlyBudget int    `json:"monthly_budget" yaml:"monthly_budget"`
+}
+
+// outputCurrencies lists the known currencies
+type outputCurrencies struct {
+	Currencies []outputCurrencyInfo `json:"currencies" yaml:"currencies"`
+}
+
+// outputCurrencyInfo describes a currency of the registry
+type outputCurrencyInfo struct {
+	Symbol   string `json:"symbol" yaml:"symbol"`
+	Name     string `json:"name" yaml:"name"`
+	Kind     string `json:"kind" yaml:"kind"`
+	Decimals int    `json:"decimals" yaml:"decimals"`
+	Sign     string `json:"sign,omitempty" yaml:"sign,omitempty"`
+	CMCID    int    `json:"cmc_id,omitempty" yaml:"cmc_id,omitempty"`
+}
+
+// outputSettings lists the effective configuration
+type outputSettings struct {
+	Settings []outputSetting `json:"settings" yaml:"settings"`
+}
+
+// outputSetting is one configuration value; secrets are masked
+type outputSetting struct {
+	Name  string `json:"name" yaml:"name"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// outputCache describes the persistent caches
+type outputCache struct {
+	Dir     string            `json:"dir" yaml:"dir"`
+	Files   []outputCacheFile `json:"files,omitempty" yaml:"files,omitempty"`
+	Removed []string          `json:"removed,omitempty" yaml:"removed,omitempty"`
+}
+
+// outputCacheFile is one file in the cache directory
+type outputCacheFile struct {
+	Name     string `json:"name" yaml:"name"`
+	Size     int64  `json:"size" yaml:"size"`
+	Modified string `json:"modified" yaml:"modified"`
+}
+
+// outputTotals is the document written to stdout for an expression
+type outputTotals struct {
+	Totals []outputTotal `json:"totals" yaml:"totals"`
+}
+
+// outputTotal is the value of an expression in one currency
+type outputTotal struct {
+	Expression string         `json:"expression" yaml:"expression"`
+	Amount     string         `json:"amount" yaml:"amount"`
+	Currency   outputCurrency `json:"currency" yaml:"currency"`
+	Terms      []outputTerm   `json:"terms" yaml:"terms"`
+}
+
+// outputTerm is the conversion of one term of an expression; the operator
+// is "-" for subtracted terms and "+" for the others
+type outputTerm struct {
+	Operator     string `json:"operator" yaml:"operator"`
+	outputResult `yaml:",inline"`
+}
+
+// newOutputResult converts a domain result into the output schema
+func newOutputResult(result *domain.ConversionResult) outputResult {
+	out := outputResult{
+		Amount:          result.OriginalAmount.String(),
+		From:            newOutputCurrency(result.FromCurrency),
+		ConvertedAmount: formatAmount(result.ConvertedAmount, result.ToCurrency),
+		To:              newOutputCurrency(result.ToCurrency),
+		Rate:            result.ExchangeRate.String(),
+		Source:          result.Source,
+		LastUpdated:     formatTimestamp(result.LastUpdated),
+		QueryTime:       formatTimestamp(result.Timestamp),
+	}
+	if !result.AsOf.IsZero() {
+		out.AsOf = formatTimestamp(result.AsOf)
+	}
+
+	for _, leg := range result.Legs {
+		out.Route = append(out.Route, outputLeg{
+			From:   leg.FromCurrency.String(),
+			To:     leg.ToCurrency.String(),
+			Rate:   leg.ExchangeRate.String(),
+			Source: leg.Source,
+		})
+	}
+
+	if len(result.Quotes) > 0 {
+		out.Spread = result.Spread.String()
+	}
+	for _, q := range result.Quotes {
+		quote := outputQuote{Source: q.Source, Rejected: q.Rejected}
+		if q.Err != nil {
+			quote.Error = q.Err.Error()
+		} else {
+			quote.Rate = q.Rate.String()
+			quote.Deviation = q.Deviation.String()
+		}
+		out.Quotes = append(out.Quotes, quote)
+	}
+	return out
+}
+
+// newOutputCurrency describes a currency in the output schema
+func newOutputCurrency(currency *domain.Currency) outputCurrency {
+	return outputCurrency{
+		Symbol: currency.String(),
+		Name:   currency.Name,
+		CMCID:  currency.CMCID,
+	}
+}
+
+// newOutputTotal converts a total into the output schema
+func newOutputTotal(total *Total) outputTotal {
+	out := outputTotal{
+		Expression: total.Expression.sum(),
+		Amount:     formatAmount(total.Amount, total.Currency),
+		Currency:   newOutputCurrency(total.Currency),
+		Terms:      make([]outputTerm, 0, len(total.Results)),
+	}
+	for i, result := range total.Results {
+		out.Terms = append(out.Terms, outputTerm{
+			Operator:     total.Expression.Terms[i].operator(),
+			outputResult: newOutputResult(result),
+		})
+	}
+	return out
+}
+
+// formatTimestamp renders t as RFC3339 in UTC, or "" when unset
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
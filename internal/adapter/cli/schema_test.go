@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+func TestNewOutputResult(t *testing.T) {
+	usd, _ := domain.NewCurrency("USD")
+	btc, _ := domain.NewCurrency("BTC")
+	eur, _ := domain.NewCurrency("EUR")
+	queried := time.Date(2024, 3, 1, 16, 4, 5, 0, time.FixedZone("CET", 3600))
+	updated := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+
+	t.Run("direct conversion", func(t *testing.T) {
+		result := domain.NewConversionResult(
+			domain.MustParseAmount("123.45"),
+			domain.MustParseAmount("0.001234567890123456"),
+			domain.MustParseAmount("0.0000100005499402"),
+			usd, btc, queried, updated,
+		)
+		result.Source = "coinmarketcap"
+
+		got := newOutputResult(result)
+
+		assert.Equal(t, "123.45", got.Amount)
+		assert.Equal(t, "USD", got.From.Symbol)
+		assert.Equal(t, "0.001234567890123456", got.ConvertedAmount)
+		assert.Equal(t, "BTC", got.To.Symbol)
+		assert.Equal(t, "0.0000100005499402", got.Rate)
+		assert.Equal(t, "coinmarketcap", got.Source)
+		assert.Equal(t, "2024-03-01T15:04:05Z", got.QueryTime)
+		assert.Equal(t, "2024-03-01T15:00:00Z", got.LastUpdated)
+		assert.Empty(t, got.AsOf)
+		assert.Empty(t, got.Route)
+		assert.Empty(t, got.Quotes)
+	})
+
+	t.Run("fiat amounts keep the places they were rounded to", func(t *testing.T) {
+		result := domain.NewConversionResult(
+			domain.MustParseAmount("1"),
+			domain.MustParseAmount("65000.00"),
+			domain.MustParseAmount("65000"),
+			btc, usd, queried, updated,
+		)
+		assert.Equal(t, "65000.00", newOutputResult(result).ConvertedAmount)
+
+		result.ConvertedAmount = domain.MustParseAmount("65000")
+		assert.Equal(t, "65000", newOutputResult(result).ConvertedAmount)
+	})
+
+	t.Run("historical routed consensus", func(t *testing.T) {
+		result := domain.NewConversionResult(
+			domain.MustParseAmount("2"),
+			domain.MustParseAmount("120000.00"),
+			domain.MustParseAmount("60000"),
+			btc, eur, queried, updated,
+		)
+		result.AsOf = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		result.Legs = []domain.ConversionLeg{
+			{FromCurrency: btc, ToCurrency: usd, ExchangeRate: domain.MustParseAmount("65000"), Source: "coinmarketcap"},
+			{FromCurrency: usd, ToCurrency: eur, ExchangeRate: domain.MustParseAmount("0.923"), Source: "ecb"},
+		}
+		result.Spread = domain.MustParseAmount("0.002")
+		result.Quotes = []domain.ProviderQuote{
+			{Source: "coinmarketcap", Rate: domain.MustParseAmount("60000"), Deviation: domain.MustParseAmount("0")},
+			{Source: "ecb", Err: fmt.Errorf("%w: BTC", domain.ErrUnsupportedPair)},
+		}
+
+		got := newOutputResult(result)
+
+		assert.Equal(t, "2024-03-01T00:00:00Z", got.AsOf)
+		assert.Equal(t, []outputLeg{
+			{From: "BTC", To: "USD", Rate: "65000", Source: "coinmarketcap"},
+			{From: "USD", To: "EUR", Rate: "0.923", Source: "ecb"},
+		}, got.Route)
+		assert.Equal(t, "0.002", got.Spread)
+		assert.Equal(t, []outputQuote{
+			{Source: "coinmarketcap", Rate: "60000", Deviation: "0"},
+			{Source: "ecb", Error: "unsupported currency pair: BTC"},
+		}, got.Quotes)
+	})
+}
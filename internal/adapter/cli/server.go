@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/kerimovkk/currency-conversion-utility/internal/usecase"
+)
+
+// Converter converts an amount into one or more currencies; it is
+// implemented by usecase.ConvertCurrencyUseCase
+type Converter interface {
+	ExecuteMulti(ctx context.Context, amount domain.Amount, fromSymbol string, toSymbols []string, opts ...usecase.ExecuteOption) ([]*domain.ConversionResult, error)
+}
+
+// server answers conversion requests over HTTP
+type server struct {
+	converter Converter
+	timeout   time.Duration
+}
+
+// NewHandler serves conversions as JSON documents in the schema of
+// --output json. Every request is bounded by timeout.
+func NewHandler(converter Converter, timeout time.Duration) http.Handler {
+	s := &server{converter: converter, timeout: timeout}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /convert", s.handleConvert)
+	mux.HandleFunc("GET /rates", s.handleRates)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// handleConvert answers GET /convert?amount=100&from=USD&to=BTC,EUR[&at=...]
+func (s *server) handleConvert(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	amount, err := domain.ParseAmount(query.Get("amount"))
+	if err != nil {
+		writeHTTPError(w, fmt.Errorf("invalid amount '%s': %w", query.Get("amount"), err))
+		return
+	}
+	if amount.Sign() <= 0 {
+		writeHTTPError(w, fmt.Errorf("invalid amount '%s': %w", query.Get("amount"), domain.ErrInvalidAmount))
+		return
+	}
+
+	toSymbols, err := parseTargets(query.Get("to"))
+	if err != nil {
+		writeHTTPError(w, fmt.Errorf("%w: %v", domain.ErrMalformedInput, err))
+		return
+	}
+
+	s.convert(w, r, amount, query.Get("from"), toSymbols)
+}
+
+// handleRates answers GET /rates?base=BTC[&to=USD,EUR][&at=...]
+func (s *server) handleRates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	toSymbols := rateTargets(query.Get("base"))
+	if query.Has("to") {
+		var err error
+		if toSymbols, err = parseTargets(query.Get("to")); err != nil {
+			writeHTTPError(w, fmt.Errorf("%w: %v", domain.ErrMalformedInput, err))
+			return
+		}
+	}
+
+	s.convert(w, r, domain.NewAmountFromInt(1), query.Get("base"), toSymbols)
+}
+
+// convert writes the conversion of amount from into toSymbols
+func (s *server) convert(w http.ResponseWriter, r *http.Request, amount domain.Amount, from string, toSymbols []string) {
+	if from == "" {
+		writeHTTPError(w, fmt.Errorf("%w: the source currency is required", domain.ErrMalformedInput))
+		return
+	}
+
+	var at time.Time
+	var err error
+	if value := r.URL.Query().Get("at"); value != "" {
+		if at, err = parseAt(value); err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+
+	results, err := s.converter.ExecuteMulti(ctx, amount, from, toSymbols, usecase.WithAsOf(at))
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = writeJSON(w, newOutputDocument(results, nil))
+}
+
+// writeHTTPError writes err as an error document with a matching status
+func writeHTTPError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(err))
+	_ = writeJSON(w, outputError{Error: outputErrorDetail{
+		Code:    domain.ErrorCode(err),
+		Message: err.Error(),
+	}})
+}
+
+// httpStatus maps a domain error to an HTTP status code
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCurrency),
+		errors.Is(err, domain.ErrInvalidAmount),
+		errors.Is(err, domain.ErrMalformedAmount),
+		errors.Is(err, domain.ErrMalformedInput),
+		errors.Is(err, domain.ErrInvalidTimestamp):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrUnsupportedPair):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrRateLimitExceeded),
+		errors.Is(err, domain.ErrCreditBudgetExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case domain.ErrorCode(err) != domain.UnknownErrorCode:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
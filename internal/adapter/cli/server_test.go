@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/kerimovkk/currency-conversion-utility/internal/usecase"
+)
+
+// fakeConverter records its last call and answers with results or err
+type fakeConverter struct {
+	results []*domain.ConversionResult
+	err     error
+
+	amount domain.Amount
+	from   string
+	to     []string
+	at     time.Time
+}
+
+func (f *fakeConverter) ExecuteMulti(_ context.Context, amount domain.Amount, from string, to []string, opts ...usecase.ExecuteOption) ([]*domain.ConversionResult, error) {
+	f.amount, f.from, f.to, f.at = amount, from, to, usecase.NewExecuteOptions(opts...).At
+	return f.results, f.err
+}
+
+func TestHandler(t *testing.T) {
+	results := goldenResults(t)[:1]
+
+	tests := []struct {
+		name       string
+		target     string
+		err        error
+		wantStatus int
+		wantBody   string
+		wantTo     []string
+	}{
+		{
+			name:       "convert",
+			target:     "/convert?amount=1&from=BTC&to=USD",
+			wantStatus: http.StatusOK,
+			wantBody:   `"converted_amount":"65000.00"`,
+			wantTo:     []string{"USD"},
+		},
+		{
+			name:       "rates with default targets",
+			target:     "/rates?base=EUR",
+			wantStatus: http.StatusOK,
+			wantBody:   `"results":[`,
+			wantTo:     []string{"USD", "GBP", "JPY", "BTC", "ETH"},
+		},
+		{
+			name:       "malformed amount",
+			target:     "/convert?amount=abc&from=BTC&to=USD",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"code":"malformed_amount"`,
+		},
+		{
+			name:       "missing source currency",
+			target:     "/convert?amount=1&to=USD",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"code":"malformed_input"`,
+		},
+		{
+			name:       "malformed time",
+			target:     "/rates?base=BTC&at=yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"code":"invalid_timestamp"`,
+		},
+		{
+			name:       "upstream rate limit",
+			target:     "/convert?amount=1&from=BTC&to=USD",
+			err:        fmt.Errorf("fetching price: %w", domain.ErrRateLimitExceeded),
+			wantStatus: http.StatusTooManyRequests,
+			wantBody:   `"code":"rate_limit_exceeded"`,
+		},
+		{
+			name:       "upstream failure",
+			target:     "/convert?amount=1&from=BTC&to=USD",
+			err:        domain.ErrServerError,
+			wantStatus: http.StatusBadGateway,
+			wantBody:   `"code":"server_error"`,
+		},
+		{
+			name:       "wrong method",
+			target:     "/convert",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := &fakeConverter{results: results, err: tt.err}
+			handler := NewHandler(converter, time.Second)
+
+			method := http.MethodGet
+			if tt.wantStatus == http.StatusMethodNotAllowed {
+				method = http.MethodPost
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(method, tt.target, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+			if tt.wantTo != nil {
+				assert.Equal(t, tt.wantTo, converter.to)
+			}
+		})
+	}
+}
+
+func TestHandler_HistoricalRates(t *testing.T) {
+	converter := &fakeConverter{results: goldenResults(t)[:1]}
+	rec := httptest.NewRecorder()
+
+	NewHandler(converter, time.Second).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rates?base=BTC&to=USD,EUR&at=2024-03-01", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", converter.amount.String())
+	assert.Equal(t, "BTC", converter.from)
+	assert.Equal(t, []string{"USD", "EUR"}, converter.to)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), converter.at)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// tableFormatter writes results as columns aligned with spaces
+type tableFormatter struct {
+	header bool
+}
+
+// NewTableFormatter creates the aligned table formatter, optionally starting
+// with a header row of column names
+func NewTableFormatter(header bool) Formatter {
+	return &tableFormatter{header: header}
+}
+
+// Format writes results as an aligned table; empty cells show as "-"
+func (f *tableFormatter) Format(w io.Writer, results []*domain.ConversionResult) error {
+	return f.FormatLines(w, results, nil)
+}
+
+// FormatLines writes results as an aligned table led by a line column
+func (f *tableFormatter) FormatLines(w io.Writer, results []*domain.ConversionResult, lines []int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if f.header {
+		columns := header(lines != nil)
+		names := make([]string, len(columns))
+		for i, column := range columns {
+			names[i] = strings.ToUpper(strings.ReplaceAll(column, "_", " "))
+		}
+		if _, err := io.WriteString(tw, strings.Join(names, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	for i := range results {
+		cells := numberedRow(results, lines, i)
+		for i, cell := range cells {
+			if cell == "" {
+				cells[i] = "-"
+			}
+		}
+		if _, err := io.WriteString(tw, strings.Join(cells, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// templateFormatter writes every result through a user-defined text/template
+type templateFormatter struct {
+	tmpl *template.Template
+}
+
+// NewTemplateFormatter creates a formatter executing the Go text/template
+// text once per domain.ConversionResult, with the helpers of templateFuncs.
+// A newline is added after each result unless the template ends with one.
+func NewTemplateFormatter(text string) (Formatter, error) {
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+	return &templateFormatter{tmpl: tmpl}, nil
+}
+
+// Format writes results through the template
+func (f *templateFormatter) Format(w io.Writer, results []*domain.ConversionResult) error {
+	var b strings.Builder
+	for _, result := range results {
+		if err := f.tmpl.Execute(&b, result); err != nil {
+			return fmt.Errorf("executing format template: %w", err)
+		}
+		if !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ResolveTemplate returns the template for a --format value: the value
+// itself when it contains an action such as {{.ConvertedAmount}}, otherwise
+// the named template it refers to
+func ResolveTemplate(format string, named map[string]string) (string, error) {
+	if strings.Contains(format, "{{") {
+		return format, nil
+	}
+	if text, ok := named[strings.ToLower(strings.TrimSpace(format))]; ok {
+		return text, nil
+	}
+	return "", fmt.Errorf("unknown template '%s': expected a template such as '{{.ConvertedAmount}}' or a name defined by TEMPLATE_<NAME>", format)
+}
+
+// templateFuncs are the helpers available to format templates
+var templateFuncs = template.FuncMap{
+	"round":  roundAmount,
+	"number": formatNumber,
+	"amount": formatAmount,
+	"symbol": (*domain.Currency).DisplaySign,
+	"time":   formatTime,
+	"utc":    func(t time.Time) time.Time { return t.UTC() },
+}
+
+// roundAmount rounds a half-even to exactly places decimals, keeping
+// trailing zeros, e.g. {{round 2 .ExchangeRate}}
+func roundAmount(places int, a domain.Amount) (domain.Amount, error) {
+	if places < 0 {
+		return domain.Amount{}, fmt.Errorf("round: invalid number of places %d", places)
+	}
+	return domain.ParseAmount(a.StringFixed(int32(places)))
+}
+
+// timeLayouts are the layout names accepted by the time helper besides Go
+// reference layouts
+var timeLayouts = map[string]string{
+	"rfc3339":  time.RFC3339,
+	"date":     time.DateOnly,
+	"datetime": time.DateTime,
+	"kitchen":  time.Kitchen,
+}
+
+// formatTime renders t with a named or Go reference layout, e.g.
+// {{time "date" .Timestamp}} or {{time "02 Jan 15:04" .LastUpdated}}
+func formatTime(layout string, t time.Time) string {
+	if named, ok := timeLayouts[layout]; ok {
+		layout = named
+	}
+	return t.Format(layout)
+}
+
+// numberLocale holds the separators a locale writes numbers with
+type numberLocale struct {
+	group   string
+	decimal string
+}
+
+// numberLocales maps lower-case language tags, with or without region, to
+// their number separators
+var numberLocales = map[string]numberLocale{
+	"en":    {group: ",", decimal: "."},
+	"ja":    {group: ",", decimal: "."},
+	"ko":    {group: ",", decimal: "."},
+	"zh":    {group: ",", decimal: "."},
+	"de":    {group: ".", decimal: ","},
+	"es":    {group: ".", decimal: ","},
+	"it":    {group: ".", decimal: ","},
+	"nl":    {group: ".", decimal: ","},
+	"pt":    {group: ".", decimal: ","},
+	"tr":    {group: ".", decimal: ","},
+	"fr":    {group: "\u202f", decimal: ","},
+	"cs":    {group: "\u00a0", decimal: ","},
+	"pl":    {group: "\u00a0", decimal: ","},
+	"ru":    {group: "\u00a0", decimal: ","},
+	"sv":    {group: "\u00a0", decimal: ","},
+	"uk":    {group: "\u00a0", decimal: ","},
+	"de-ch": {group: "’", decimal: "."},
+	"fr-ch": {group: "\u202f", decimal: "."},
+}
+
+// formatNumber groups the digits of a the way locale does, e.g.
+// {{number "de-DE" .ConvertedAmount}} renders 1234.5 as "1.234,5"
+func formatNumber(locale string, a domain.Amount) (string, error) {
+	tag := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+	loc, ok := numberLocales[tag]
+	if !ok {
+		language, _, _ := strings.Cut(tag, "-")
+		if loc, ok = numberLocales[language]; !ok {
+			return "", fmt.Errorf("number: unsupported locale '%s'", locale)
+		}
+	}
+
+	digits := a.String()
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(loc.group)
+		}
+		b.WriteRune(d)
+	}
+	if hasFrac {
+		b.WriteString(loc.decimal)
+		b.WriteString(frac)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	results := goldenResults(t)
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "fields",
+			template: "{{.OriginalAmount}} {{.FromCurrency}} -> {{.ConvertedAmount}} {{.ToCurrency}}",
+			want:     "1 BTC -> 65000.00 USD\n1000 DOGE -> 105.60 CHF\n2 ETH -> 6000.00 EUR\n",
+		},
+		{
+			name:     "symbol and locale number",
+			template: `≈ {{symbol .ToCurrency}}{{number "en-US" .ConvertedAmount}}`,
+			want:     "≈ $65,000.00\n≈ CHF105.60\n≈ €6,000.00\n",
+		},
+		{
+			name:     "rounded rate and time",
+			template: `{{.FromCurrency}}→{{.ToCurrency}} {{round 2 .ExchangeRate}} @ {{time "2006-01-02T15:04" .Timestamp}}`,
+			want:     "BTC→USD 65000.12 @ 2024-03-01T15:04\nDOGE→CHF 0.11 @ 2024-03-01T15:04\nETH→EUR 3000.00 @ 2024-03-01T15:04\n",
+		},
+		{
+			name:     "pipelines and named layouts",
+			template: `{{.ExchangeRate | round 4 | number "de"}} {{.LastUpdated | utc | time "date"}}`,
+			want:     "65.000,1235 2024-03-01\n0,1056 2024-03-01\n3.000,0000 2024-03-01\n",
+		},
+		{
+			name:     "trailing newline is kept",
+			template: "{{amount .ConvertedAmount .ToCurrency}}\n",
+			want:     "65000.00\n105.60\n6000.00\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewTemplateFormatter(tt.template)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, f.Format(&buf, results))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestTemplateFormatter_Errors(t *testing.T) {
+	_, err := NewTemplateFormatter("{{.ConvertedAmount")
+	assert.ErrorContains(t, err, "invalid format template")
+
+	f, err := NewTemplateFormatter(`{{number "xx" .ConvertedAmount}}`)
+	require.NoError(t, err)
+	err = f.Format(&bytes.Buffer{}, goldenResults(t))
+	assert.ErrorContains(t, err, "unsupported locale 'xx'")
+
+	f, err = NewTemplateFormatter("{{.NoSuchField}}")
+	require.NoError(t, err)
+	assert.Error(t, f.Format(&bytes.Buffer{}, goldenResults(t)))
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		locale string
+		amount string
+		want   string
+	}{
+		{locale: "en", amount: "1234567.891", want: "1,234,567.891"},
+		{locale: "en_GB", amount: "999", want: "999"},
+		{locale: "de-DE", amount: "1234.5", want: "1.234,5"},
+		{locale: "fr", amount: "-1234567", want: "-1\u202f234\u202f567"},
+		{locale: "de-CH", amount: "1234.50", want: "1’234.50"},
+		{locale: "ru", amount: "0.001", want: "0,001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+" "+tt.amount, func(t *testing.T) {
+			got, err := formatNumber(tt.locale, domain.MustParseAmount(tt.amount))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveTemplate(t *testing.T) {
+	named := map[string]string{"short": "{{.ConvertedAmount}}"}
+
+	got, err := ResolveTemplate("{{.Source}}", named)
+	assert.NoError(t, err)
+	assert.Equal(t, "{{.Source}}", got)
+
+	got, err = ResolveTemplate("Short", named)
+	assert.NoError(t, err)
+	assert.Equal(t, "{{.ConvertedAmount}}", got)
+
+	_, err = ResolveTemplate("long", named)
+	assert.ErrorContains(t, err, "unknown template 'long'")
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// textFormatter writes human-readable results, one line each or a detailed
+// block each in verbose mode
+type textFormatter struct {
+	verbose bool
+}
+
+// NewTextFormatter creates the human-readable formatter
+func NewTextFormatter(verbose bool) Formatter {
+	return &textFormatter{verbose: verbose}
+}
+
+// Format writes results as text
+func (f *textFormatter) Format(w io.Writer, results []*domain.ConversionResult) error {
+	var b strings.Builder
+	for i, result := range results {
+		if !f.verbose {
+			writeSimple(&b, result)
+			continue
+		}
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		writeVerbose(&b, result)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeSimple writes a simple one-line result
+func writeSimple(w io.Writer, result *domain.ConversionResult) {
+	fmt.Fprintf(w, "%s %s = %s %s\n",
+		result.OriginalAmount,
+		result.FromCurrency.String(),
+		formatAmount(result.ConvertedAmount, result.ToCurrency),
+		result.ToCurrency.String(),
+	)
+}
+
+// writeVerbose writes detailed conversion information
+func writeVerbose(w io.Writer, result *domain.ConversionResult) {
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+	fmt.Fprintln(w, "CURRENCY CONVERSION RESULT")
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+	fmt.Fprintf(w, "Original Amount:    %s %s\n", result.OriginalAmount, result.FromCurrency.String())
+	fmt.Fprintf(w, "Converted Amount:   %s %s\n", formatAmount(result.ConvertedAmount, result.ToCurrency), result.ToCurrency.String())
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	fmt.Fprintf(w, "Exchange Rate:      1 %s = %s %s\n",
+		result.FromCurrency.String(),
+		result.ExchangeRate,
+		result.ToCurrency.String(),
+	)
+	if asset := describeAsset(result.FromCurrency); asset != "" {
+		fmt.Fprintf(w, "Source Asset:       %s\n", asset)
+	}
+	if result.ToCurrency.Pinned {
+		if asset := describeAsset(result.ToCurrency); asset != "" {
+			fmt.Fprintf(w, "Target Asset:       %s\n", asset)
+		}
+	}
+	if result.Source != "" {
+		fmt.Fprintf(w, "Source:             %s\n", result.Source)
+	}
+	if len(result.Quotes) > 0 {
+		writeQuotes(w, result)
+	}
+	if len(result.Legs) > 0 {
+		writeRoute(w, result)
+	}
+	if !result.AsOf.IsZero() {
+		fmt.Fprintf(w, "Prices As Of:       %s\n", result.AsOf.UTC().Format("2006-01-02 15:04:05 MST"))
+	}
+	fmt.Fprintf(w, "Last Updated:       %s\n", result.LastUpdated.Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(w, "Query Time:         %s\n", result.Timestamp.Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+}
+
+// writeQuotes lists the provider quotes behind a consensus rate
+func writeQuotes(w io.Writer, result *domain.ConversionResult) {
+	fmt.Fprintf(w, "Spread:             %s %s (%s%%)\n",
+		result.Spread,
+		result.ToCurrency.String(),
+		percent(relative(result.Spread, result.ExchangeRate)),
+	)
+	fmt.Fprintln(w, "Provider Quotes:")
+	for _, q := range result.Quotes {
+		switch {
+		case q.Err != nil:
+			fmt.Fprintf(w, "  %-17s failed: %v\n", q.Source, q.Err)
+		case q.Rejected:
+			fmt.Fprintf(w, "  %-17s %s (%s%% off, rejected)\n", q.Source, q.Rate, percent(q.Deviation))
+		default:
+			fmt.Fprintf(w, "  %-17s %s (%s%% off)\n", q.Source, q.Rate, percent(q.Deviation))
+		}
+	}
+}
+
+// writeRoute shows the bridge currencies a conversion was routed through
+// and the rate of every leg
+func writeRoute(w io.Writer, result *domain.ConversionResult) {
+	fmt.Fprintf(w, "Route:              %s\n", routePath(result))
+	for _, leg := range result.Legs {
+		line := fmt.Sprintf("  1 %s = %s %s", leg.FromCurrency.String(), leg.ExchangeRate, leg.ToCurrency.String())
+		if leg.Source != "" {
+			line += " (" + leg.Source + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
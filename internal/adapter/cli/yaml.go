@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// yamlFormatter writes results as a YAML document with the JSON schema
+type yamlFormatter struct{}
+
+// NewYAMLFormatter creates the YAML formatter
+func NewYAMLFormatter() Formatter {
+	return yamlFormatter{}
+}
+
+// Format writes results as a YAML document with a results list
+func (f yamlFormatter) Format(w io.Writer, results []*domain.ConversionResult) error {
+	return f.FormatLines(w, results, nil)
+}
+
+// FormatLines writes results with the input line of each one
+func (yamlFormatter) FormatLines(w io.Writer, results []*domain.ConversionResult, lines []int) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(newOutputDocument(results, lines)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -3,14 +3,19 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/kerimovkk/currency-conversion-utility/pkg/ratelimit"
 	"github.com/kerimovkk/currency-conversion-utility/pkg/retry"
 )
 
@@ -20,22 +25,92 @@ type CoinMarketCapRepository struct {
 	apiKey     string
 	baseURL    string
 	retry      *retry.Strategy
+	breaker    *retry.CircuitBreaker
+	limiter    *ratelimit.TokenBucket
+	ledger     *CreditLedger
+}
+
+// CoinMarketCapOption configures optional behaviour of CoinMarketCapRepository
+type CoinMarketCapOption func(*CoinMarketCapRepository)
+
+// WithRateLimit spaces API calls out according to limiter
+func WithRateLimit(limiter *ratelimit.TokenBucket) CoinMarketCapOption {
+	return func(c *CoinMarketCapRepository) {
+		c.limiter = limiter
+	}
+}
+
+// WithCreditLedger records the credits every call costs in ledger and
+// refuses calls that would exceed its budget
+func WithCreditLedger(ledger *CreditLedger) CoinMarketCapOption {
+	return func(c *CoinMarketCapRepository) {
+		c.ledger = ledger
+	}
+}
+
+// coinMarketCapSource names CoinMarketCap in registries and results
+const coinMarketCapSource = "coinmarketcap"
+
+// rateLimitBurst is the most calls the rate limiter lets through at once
+const rateLimitBurst = 5
+
+// cmcAttemptTimeout cuts off a single API request, leaving the rest of the
+// conversion timeout to the remaining attempts
+const cmcAttemptTimeout = 10 * time.Second
+
+func init() {
+	Register(coinMarketCapSource, newCoinMarketCapProvider)
+}
+
+// newCoinMarketCapProvider is the ProviderFactory for CoinMarketCap
+func newCoinMarketCapProvider(httpClient *http.Client, settings ProviderSettings) (domain.PriceRepository, error) {
+	if settings.APIKey == "" {
+		return nil, domain.ErrAPIKeyMissing
+	}
+
+	var opts []CoinMarketCapOption
+	if settings.RequestsPerMinute > 0 {
+		burst := min(settings.RequestsPerMinute, rateLimitBurst)
+		opts = append(opts, WithRateLimit(ratelimit.NewTokenBucket(settings.RequestsPerMinute, burst)))
+	}
+	if settings.CreditLedger != "" || settings.DailyCreditBudget > 0 || settings.MonthlyCreditBudget > 0 {
+		opts = append(opts, WithCreditLedger(NewCreditLedger(settings.CreditLedger, settings.DailyCreditBudget, settings.MonthlyCreditBudget)))
+	}
+	return NewCoinMarketCapRepository(httpClient, settings.APIKey, settings.APIURL, opts...), nil
 }
 
 // NewCoinMarketCapRepository creates a new CoinMarketCap API client
-func NewCoinMarketCapRepository(httpClient *http.Client, apiKey, baseURL string) *CoinMarketCapRepository {
-	return &CoinMarketCapRepository{
+func NewCoinMarketCapRepository(httpClient *http.Client, apiKey, baseURL string, opts ...CoinMarketCapOption) *CoinMarketCapRepository {
+	// Concurrent conversions share one rate limit, so their retries must not line up
+	strategy := retry.DefaultStrategy()
+	strategy.Jitter = retry.EqualJitter
+	strategy.AttemptTimeout = cmcAttemptTimeout
+
+	c := &CoinMarketCapRepository{
 		httpClient: httpClient,
 		apiKey:     apiKey,
 		baseURL:    baseURL,
-		retry:      retry.DefaultStrategy(),
+		retry:      strategy,
+		breaker:    retry.NewCircuitBreaker(retry.DefaultBreakerSettings(), isUpstreamFailure),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// CreditUsage returns the credits recorded by the repository's ledger
+func (c *CoinMarketCapRepository) CreditUsage() (domain.CreditUsage, bool) {
+	if c.ledger == nil {
+		return domain.CreditUsage{}, false
 	}
+	return c.ledger.Usage(), true
 }
 
 // APIResponse represents the structure of the CoinMarketCap API response
 type APIResponse struct {
-	Status StatusObject `json:"status"`
-	Data   interface{}  `json:"data"`
+	Status StatusObject    `json:"status"`
+	Data   json.RawMessage `json:"data"`
 }
 
 // StatusObject represents the status object in API responses
@@ -52,56 +127,262 @@ type PriceConversionData struct {
 	Symbol      string                 `json:"symbol"`
 	ID          int                    `json:"id"`
 	Name        string                 `json:"name"`
-	Amount      float64                `json:"amount"`
+	Amount      json.Number            `json:"amount"`
 	LastUpdated time.Time              `json:"last_updated"`
 	Quote       map[string]QuoteDetail `json:"quote"`
 }
 
 // QuoteDetail contains the conversion details for a specific currency
 type QuoteDetail struct {
-	Price       float64   `json:"price"`
-	LastUpdated time.Time `json:"last_updated"`
+	Price       json.Number `json:"price"`
+	LastUpdated time.Time   `json:"last_updated"`
+}
+
+// CryptocurrencyInfo represents an entry returned by /v2/cryptocurrency/info
+type CryptocurrencyInfo struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+	Slug   string `json:"slug"`
 }
 
 // GetConversionPrice fetches the conversion price from CoinMarketCap API
 func (c *CoinMarketCapRepository) GetConversionPrice(
 	ctx context.Context,
-	amount float64,
-	from, to string,
+	request *domain.ConversionRequest,
 ) (*domain.ConversionResult, error) {
-	var result *domain.ConversionResult
-	var lastErr error
-
-	// Execute with retry logic
-	err := retry.Do(ctx, c.retry, c.shouldRetry, func(ctx context.Context) error {
-		var err error
-		result, err = c.fetchConversionPrice(ctx, amount, from, to)
-		lastErr = err
-		return err
+	results, err := c.GetConversionPrices(ctx, []*domain.ConversionRequest{request})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// GetConversionPrices prices several targets of the same source amount using
+// the comma-separated convert list of /v1/tools/price-conversion
+func (c *CoinMarketCapRepository) GetConversionPrices(
+	ctx context.Context,
+	requests []*domain.ConversionRequest,
+) ([]*domain.ConversionResult, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	// Requests that cannot share one call are priced individually
+	if !sameSource(requests) {
+		results := make([]*domain.ConversionResult, 0, len(requests))
+		for _, request := range requests {
+			result, err := c.GetConversionPrice(ctx, request)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		return results, nil
+	}
+
+	// Execute with retry logic; an open breaker fails fast without retrying
+	return retry.DoValue(ctx, c.retry, c.shouldRetry, func(ctx context.Context) ([]*domain.ConversionResult, error) {
+		var results []*domain.ConversionResult
+		err := c.breaker.Do(ctx, func(ctx context.Context) error {
+			var err error
+			results, err = c.fetchConversionPrices(ctx, requests)
+			return err
+		})
+		if errors.Is(err, retry.ErrCircuitOpen) {
+			return nil, domain.ErrCircuitOpen
+		}
+		return results, err
 	})
+}
+
+// sameSource reports whether all requests share amount, source currency and as-of time
+func sameSource(requests []*domain.ConversionRequest) bool {
+	first := requests[0]
+	for _, r := range requests[1:] {
+		if !r.Amount.Equal(first.Amount) || !r.FromCurrency.Equals(first.FromCurrency) || !r.At.Equal(first.At) {
+			return false
+		}
+	}
+	return true
+}
 
+// fetchConversionPrices performs the actual API calls. Targets selected by
+// symbol and by ID cannot be mixed in one call, so at most two calls are made.
+func (c *CoinMarketCapRepository) fetchConversionPrices(
+	ctx context.Context,
+	requests []*domain.ConversionRequest,
+) ([]*domain.ConversionResult, error) {
+	source := requests[0]
+
+	params := url.Values{}
+	params.Add("amount", source.Amount.String())
+	if source.IsHistorical() {
+		params.Add("time", source.At.UTC().Format(time.RFC3339))
+	}
+
+	// Select the source asset by ID when it was pinned, otherwise by symbol
+	fromID, err := c.pinnedID(ctx, source.FromCurrency)
 	if err != nil {
-		return nil, lastErr
+		return nil, err
+	}
+	if fromID != 0 {
+		params.Add("id", strconv.Itoa(fromID))
+	} else {
+		params.Add("symbol", source.FromCurrency.Symbol)
 	}
 
-	return result, nil
+	// Quotes are keyed by whatever was passed as the conversion target
+	quoteKeys := make([]string, len(requests))
+	targets := map[string][]string{}
+	toCurrencies := make([]*domain.Currency, len(requests))
+	for i, request := range requests {
+		toCurrencies[i], err = c.describeAsset(ctx, request.ToCurrency)
+		if err != nil {
+			return nil, err
+		}
+		toID, err := c.pinnedID(ctx, toCurrencies[i])
+		if err != nil {
+			return nil, err
+		}
+
+		param := "convert"
+		quoteKeys[i] = request.ToCurrency.Symbol
+		if toID != 0 {
+			param = "convert_id"
+			quoteKeys[i] = strconv.Itoa(toID)
+		}
+		if !slices.Contains(targets[param], quoteKeys[i]) {
+			targets[param] = append(targets[param], quoteKeys[i])
+		}
+	}
+
+	quotes := make(map[string]*PriceConversionData)
+	for _, param := range []string{"convert", "convert_id"} {
+		if len(targets[param]) == 0 {
+			continue
+		}
+
+		callParams := maps.Clone(params)
+		callParams.Set(param, strings.Join(targets[param], ","))
+
+		data, err := c.get(ctx, "/v1/tools/price-conversion", callParams)
+		if err != nil {
+			return nil, err
+		}
+
+		convData, err := decodeConversionData(data)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range targets[param] {
+			quotes[key] = convData
+		}
+	}
+
+	results := make([]*domain.ConversionResult, 0, len(requests))
+	for i, request := range requests {
+		result, err := c.buildResult(quotes[quoteKeys[i]], request, toCurrencies[i], quoteKeys[i])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
 }
 
-// fetchConversionPrice performs the actual API call
-func (c *CoinMarketCapRepository) fetchConversionPrice(
-	ctx context.Context,
-	amount float64,
-	from, to string,
-) (*domain.ConversionResult, error) {
-	// Build request URL
-	endpoint := fmt.Sprintf("%s/v1/tools/price-conversion", c.baseURL)
+// pinnedID returns the CoinMarketCap ID to query for a pinned currency,
+// resolving its slug when necessary. It returns 0 for currencies selected by symbol.
+func (c *CoinMarketCapRepository) pinnedID(ctx context.Context, currency *domain.Currency) (int, error) {
+	if !currency.Pinned {
+		return 0, nil
+	}
+	if currency.CMCID != 0 {
+		return currency.CMCID, nil
+	}
+
+	info, err := c.assetInfo(ctx, currency)
+	if err != nil {
+		return 0, err
+	}
+	return info.ID, nil
+}
+
+// describeAsset returns currency with the symbol and name CoinMarketCap lists
+// for a pinned asset missing from the registry, so results name the asset
+// instead of showing its ID. Other currencies are returned unchanged, as are
+// assets the info endpoint does not know, such as fiat currencies pinned by ID.
+func (c *CoinMarketCapRepository) describeAsset(ctx context.Context, currency *domain.Currency) (*domain.Currency, error) {
+	if !currency.Pinned || currency.Symbol != "" {
+		return currency, nil
+	}
+
+	info, err := c.assetInfo(ctx, currency)
+	if errors.Is(err, domain.ErrInvalidCurrency) && currency.CMCID != 0 {
+		return currency, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	described := *currency
+	described.Symbol = info.Symbol
+	described.Name = info.Name
+	described.CMCID = info.ID
+	described.Slug = info.Slug
+	if described.Decimals == 0 {
+		described.Decimals = domain.DefaultCryptoDecimals
+	}
+	return &described, nil
+}
 
+// assetInfo looks up the asset behind the CoinMarketCap ID or slug of a
+// pinned currency
+func (c *CoinMarketCapRepository) assetInfo(ctx context.Context, currency *domain.Currency) (*CryptocurrencyInfo, error) {
 	params := url.Values{}
-	params.Add("amount", strconv.FormatFloat(amount, 'f', -1, 64))
-	params.Add("symbol", from)
-	params.Add("convert", to)
+	if currency.CMCID != 0 {
+		params.Add("id", strconv.Itoa(currency.CMCID))
+	} else {
+		params.Add("slug", currency.Slug)
+	}
+
+	data, err := c.get(ctx, "/v2/cryptocurrency/info", params)
+	if err != nil {
+		return nil, err
+	}
 
-	fullURL := fmt.Sprintf("%s?%s", endpoint, params.Encode())
+	var infos map[string]CryptocurrencyInfo
+	if err := json.Unmarshal(data, &infos); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse asset info", domain.ErrInvalidResponse)
+	}
+
+	for _, info := range infos {
+		if info.ID == currency.CMCID || currency.CMCID == 0 && info.Slug == currency.Slug {
+			return &info, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: no asset %s", domain.ErrInvalidCurrency, currency)
+}
+
+// get performs an authenticated GET request and returns the "data" member of the response
+func (c *CoinMarketCapRepository) get(ctx context.Context, path string, params url.Values) (json.RawMessage, error) {
+	// Refuse calls the credit budget cannot cover before spending anything
+	if c.ledger != nil {
+		if err := c.ledger.Check(estimateCredits(params)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Wait for the client-side rate limit
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Build request URL
+	fullURL := fmt.Sprintf("%s%s?%s", c.baseURL, path, params.Encode())
 
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
@@ -128,7 +409,7 @@ func (c *CoinMarketCapRepository) fetchConversionPrice(
 
 	// Handle HTTP error status codes
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleHTTPError(resp.StatusCode, body)
+		return nil, c.handleHTTPError(resp.StatusCode, resp.Header, body)
 	}
 
 	// Parse response
@@ -136,17 +417,35 @@ func (c *CoinMarketCapRepository) fetchConversionPrice(
 	if err := json.Unmarshal(body, &apiResp); err != nil {
 		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidResponse, err)
 	}
+	if c.ledger != nil {
+		c.ledger.Record(apiResp.Status.CreditCount)
+	}
 
 	// Check for API-level errors
 	if apiResp.Status.ErrorCode != 0 {
 		return nil, c.handleAPIError(apiResp.Status.ErrorCode, apiResp.Status.ErrorMessage)
 	}
 
-	return c.parseConversionData(apiResp.Data, amount, from, to)
+	return apiResp.Data, nil
+}
+
+// estimateCredits predicts the credits a call costs: one, plus one for every
+// conversion target beyond the first
+func estimateCredits(params url.Values) int {
+	credits := 0
+	for _, param := range []string{"convert", "convert_id"} {
+		if value := params.Get(param); value != "" {
+			credits += len(strings.Split(value, ","))
+		}
+	}
+	return max(credits, 1)
 }
 
-// handleHTTPError converts HTTP error codes to domain errors
-func (c *CoinMarketCapRepository) handleHTTPError(statusCode int, body []byte) error {
+// handleHTTPError converts HTTP error codes to domain errors. Rate limit and
+// server errors carry the delay suggested by the response headers, if any.
+func (c *CoinMarketCapRepository) handleHTTPError(statusCode int, header http.Header, body []byte) error {
+	delay := suggestedDelay(header, time.Now())
+
 	switch statusCode {
 	case http.StatusBadRequest:
 		return fmt.Errorf("%w: %s", domain.ErrInvalidCurrency, string(body))
@@ -155,10 +454,10 @@ func (c *CoinMarketCapRepository) handleHTTPError(statusCode int, body []byte) e
 	case http.StatusForbidden:
 		return domain.ErrForbidden
 	case http.StatusTooManyRequests:
-		return domain.ErrRateLimitExceeded
+		return retry.After(domain.ErrRateLimitExceeded, delay)
 	case http.StatusInternalServerError, http.StatusBadGateway,
-		 http.StatusServiceUnavailable, http.StatusGatewayTimeout:
-		return domain.ErrServerError
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return retry.After(domain.ErrServerError, delay)
 	default:
 		return fmt.Errorf("%w: HTTP %d", domain.ErrAPIFailure, statusCode)
 	}
@@ -186,56 +485,77 @@ func (c *CoinMarketCapRepository) handleAPIError(errorCode int, errorMsg *string
 // shouldRetry determines if an error should trigger a retry
 func (c *CoinMarketCapRepository) shouldRetry(err error) bool {
 	// Retry on rate limit and server errors
-	return err == domain.ErrRateLimitExceeded ||
-		   err == domain.ErrServerError ||
-		   err == domain.ErrNetworkFailure
+	return errors.Is(err, domain.ErrRateLimitExceeded) ||
+		errors.Is(err, domain.ErrServerError) ||
+		errors.Is(err, domain.ErrNetworkFailure)
 }
 
-// parseConversionData extracts conversion result from API response data
-func (c *CoinMarketCapRepository) parseConversionData(
-	data interface{},
-	amount float64,
-	from, to string,
-) (*domain.ConversionResult, error) {
-	dataBytes, err := json.Marshal(data)
-	if err != nil {
-		return nil, fmt.Errorf("%w: failed to marshal data", domain.ErrInvalidResponse)
-	}
+// isUpstreamFailure reports whether err says something about CoinMarketCap's
+// health rather than about the request, and so counts against the breaker
+func isUpstreamFailure(err error) bool {
+	return errors.Is(err, domain.ErrServerError) ||
+		errors.Is(err, domain.ErrNetworkFailure) ||
+		errors.Is(err, domain.ErrRateLimitExceeded)
+}
 
+// decodeConversionData parses the data member of a price-conversion response
+func decodeConversionData(data json.RawMessage) (*PriceConversionData, error) {
 	var convData PriceConversionData
-	if err := json.Unmarshal(dataBytes, &convData); err != nil {
+	if err := json.Unmarshal(data, &convData); err != nil {
 		return nil, fmt.Errorf("%w: failed to parse conversion data", domain.ErrInvalidResponse)
 	}
+	return &convData, nil
+}
 
+// buildResult extracts the conversion result for one target from decoded response data
+func (c *CoinMarketCapRepository) buildResult(
+	convData *PriceConversionData,
+	request *domain.ConversionRequest,
+	target *domain.Currency,
+	quoteKey string,
+) (*domain.ConversionResult, error) {
 	// Extract quote for target currency
-	quoteDetail, ok := convData.Quote[to]
+	quoteDetail, ok := convData.Quote[quoteKey]
 	if !ok {
-		return nil, fmt.Errorf("%w: no quote found for %s", domain.ErrInvalidResponse, to)
+		return nil, fmt.Errorf("%w: no quote found for %s", domain.ErrInvalidResponse, request.ToCurrency)
+	}
+
+	// Record the concrete asset CoinMarketCap used for the source currency
+	fromCurrency := *request.FromCurrency
+	if convData.Symbol != "" {
+		fromCurrency.Symbol = convData.Symbol
 	}
+	if convData.Name != "" {
+		fromCurrency.Name = convData.Name
+	}
+	if convData.ID != 0 {
+		fromCurrency.CMCID = convData.ID
+	}
+
+	toCurrency := *target
 
-	// Create currency objects
-	fromCurrency, err := domain.NewCurrency(from)
+	// Calculate converted amount and exchange rate
+	convertedAmount, err := domain.ParseAmount(quoteDetail.Price.String())
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: invalid price for %s", domain.ErrInvalidResponse, request.ToCurrency)
 	}
 
-	toCurrency, err := domain.NewCurrency(to)
+	exchangeRate, err := convertedAmount.Div(request.Amount)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate converted amount and exchange rate
-	convertedAmount := quoteDetail.Price
-	exchangeRate := convertedAmount / amount
-
-	// Create and return result
-	return domain.NewConversionResult(
-		amount,
+	result := domain.NewConversionResult(
+		request.Amount,
 		convertedAmount,
 		exchangeRate,
-		fromCurrency,
-		toCurrency,
+		&fromCurrency,
+		&toCurrency,
 		time.Now(),
 		quoteDetail.LastUpdated,
-	), nil
+	)
+	result.AsOf = request.At
+	result.Source = coinMarketCapSource
+
+	return result, nil
 }
@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/kerimovkk/currency-conversion-utility/pkg/ratelimit"
+	"github.com/kerimovkk/currency-conversion-utility/pkg/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRequest builds a validated conversion request for tests
+func newRequest(t *testing.T, amount, from, to string) *domain.ConversionRequest {
+	t.Helper()
+
+	fromCurrency, err := domain.NewCurrency(from)
+	require.NoError(t, err)
+	toCurrency, err := domain.NewCurrency(to)
+	require.NoError(t, err)
+
+	request, err := domain.NewConversionRequest(domain.MustParseAmount(amount), fromCurrency, toCurrency)
+	require.NoError(t, err)
+	return request
+}
+
+func TestCoinMarketCapRepository_GetConversionPrice_PreservesPrecision(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"status": {"timestamp": "2025-11-08T12:35:10Z", "error_code": 0, "credit_count": 1},
+			"data": {
+				"symbol": "ETH",
+				"id": 1027,
+				"name": "Ethereum",
+				"amount": 1.000000000000000001,
+				"last_updated": "2025-11-08T12:34:56Z",
+				"quote": {
+					"USD": {"price": 3456.789012345678904690, "last_updated": "2025-11-08T12:34:56Z"}
+				}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	repo := NewCoinMarketCapRepository(server.Client(), "test-key", server.URL)
+
+	result, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1.000000000000000001", "ETH", "USD"))
+
+	require.NoError(t, err)
+	assert.Contains(t, gotQuery, "amount=1.000000000000000001")
+	assert.Equal(t, "1.000000000000000001", result.OriginalAmount.String())
+	assert.Equal(t, "3456.789012345678904690", result.ConvertedAmount.String())
+	assert.Equal(t, "3456.789012345678901233210987654321", result.ExchangeRate.String())
+	assert.Equal(t, "coinmarketcap", result.Source)
+}
+
+func TestCoinMarketCapRepository_GetConversionPrice_AssetSelection(t *testing.T) {
+	tests := []struct {
+		name       string
+		from, to   string
+		wantParams url.Values
+		quoteKey   string
+	}{
+		{
+			name:       "bare symbols",
+			from:       "ETH",
+			to:         "USD",
+			wantParams: url.Values{"symbol": {"ETH"}, "convert": {"USD"}},
+			quoteKey:   "USD",
+		},
+		{
+			name:       "explicit source ID",
+			from:       "id:1027",
+			to:         "USD",
+			wantParams: url.Values{"id": {"1027"}, "convert": {"USD"}},
+			quoteKey:   "USD",
+		},
+		{
+			name:       "source slug known to the registry",
+			from:       "slug:ethereum",
+			to:         "USD",
+			wantParams: url.Values{"id": {"1027"}, "convert": {"USD"}},
+			quoteKey:   "USD",
+		},
+		{
+			name:       "source slug resolved through the API",
+			from:       "slug:ether-fork",
+			to:         "USD",
+			wantParams: url.Values{"id": {"99999"}, "convert": {"USD"}},
+			quoteKey:   "USD",
+		},
+		{
+			name:       "explicit target ID",
+			from:       "ETH",
+			to:         "id:2781",
+			wantParams: url.Values{"symbol": {"ETH"}, "convert_id": {"2781"}},
+			quoteKey:   "2781",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotParams url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.URL.Path == "/v2/cryptocurrency/info" {
+					if r.URL.Query().Get("id") != "" {
+						_, _ = w.Write([]byte(`{"status": {"error_code": 0}, "data": {}}`))
+						return
+					}
+					assert.Equal(t, "ether-fork", r.URL.Query().Get("slug"))
+					_, _ = w.Write([]byte(`{"status": {"error_code": 0}, "data": {
+						"99999": {"id": 99999, "name": "Ether Fork", "symbol": "ETH", "slug": "ether-fork"}
+					}}`))
+					return
+				}
+				gotParams = r.URL.Query()
+				_, _ = w.Write([]byte(`{"status": {"error_code": 0}, "data": {
+					"symbol": "ETH", "id": 1027, "name": "Ethereum", "amount": 2,
+					"quote": {"` + tt.quoteKey + `": {"price": 7000, "last_updated": "2025-11-08T12:34:56Z"}}
+				}}`))
+			}))
+			defer server.Close()
+
+			repo := NewCoinMarketCapRepository(server.Client(), "test-key", server.URL)
+			result, err := repo.GetConversionPrice(context.Background(), newRequest(t, "2", tt.from, tt.to))
+
+			require.NoError(t, err)
+			for key, want := range tt.wantParams {
+				assert.Equal(t, want, gotParams[key], "query parameter %s", key)
+			}
+			assert.Equal(t, "3500", result.ExchangeRate.String())
+			assert.Equal(t, "Ethereum", result.FromCurrency.Name)
+			assert.Equal(t, 1027, result.FromCurrency.CMCID)
+		})
+	}
+}
+
+func TestCoinMarketCapRepository_GetConversionPrice_UnknownPinnedTarget(t *testing.T) {
+	var infoQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v2/cryptocurrency/info" {
+			infoQuery = r.URL.RawQuery
+			_, _ = w.Write([]byte(`{"status": {"error_code": 0}, "data": {
+				"99999": {"id": 99999, "name": "Tiny Coin", "symbol": "TINY", "slug": "tiny-coin"}
+			}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": {"error_code": 0}, "data": {
+			"symbol": "USD", "id": 2781, "name": "US Dollar", "amount": 100,
+			"quote": {"99999": {"price": 0.0123456, "last_updated": "2025-11-08T12:34:56Z"}}
+		}}`))
+	}))
+	defer server.Close()
+
+	repo := NewCoinMarketCapRepository(server.Client(), "test-key", server.URL)
+	result, err := repo.GetConversionPrice(context.Background(), newRequest(t, "100", "USD", "id:99999"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "id=99999", infoQuery)
+	assert.Equal(t, "TINY", result.ToCurrency.Symbol)
+	assert.Equal(t, "Tiny Coin", result.ToCurrency.Name)
+	assert.Equal(t, domain.DefaultCryptoDecimals, result.ToCurrency.Decimals)
+	assert.Equal(t, "0.0123456", result.ConvertedAmount.String())
+}
+
+func TestCoinMarketCapRepository_GetConversionPrices_SingleCall(t *testing.T) {
+	var calls []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.Query())
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("convert_id") != "" {
+			_, _ = w.Write([]byte(`{"status": {"error_code": 0}, "data": {
+				"symbol": "BTC", "id": 1, "name": "Bitcoin", "amount": 1,
+				"quote": {"1027": {"price": 20.5}}
+			}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": {"error_code": 0}, "data": {
+			"symbol": "BTC", "id": 1, "name": "Bitcoin", "amount": 1,
+			"quote": {
+				"USD": {"price": 67000.12},
+				"EUR": {"price": 61000.34},
+				"GBP": {"price": 52000.56}
+			}
+		}}`))
+	}))
+	defer server.Close()
+
+	repo := NewCoinMarketCapRepository(server.Client(), "test-key", server.URL)
+
+	requests := []*domain.ConversionRequest{
+		newRequest(t, "1", "BTC", "USD"),
+		newRequest(t, "1", "BTC", "EUR"),
+		newRequest(t, "1", "BTC", "GBP"),
+	}
+	results, err := repo.GetConversionPrices(context.Background(), requests)
+
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+	assert.Equal(t, "USD,EUR,GBP", calls[0].Get("convert"))
+	require.Len(t, results, 3)
+	assert.Equal(t, "USD", results[0].ToCurrency.Symbol)
+	assert.Equal(t, "61000.34", results[1].ConvertedAmount.String())
+	assert.Equal(t, "52000.56", results[2].ExchangeRate.String())
+
+	// Pinned targets are priced by ID in a separate call
+	calls = nil
+	requests = append(requests, newRequest(t, "1", "BTC", "id:1027"))
+	results, err = repo.GetConversionPrices(context.Background(), requests)
+
+	require.NoError(t, err)
+	require.Len(t, calls, 2)
+	assert.Equal(t, "1027", calls[1].Get("convert_id"))
+	assert.Equal(t, "20.5", results[3].ConvertedAmount.String())
+}
+
+func TestCoinMarketCapRepository_GetConversionPrice_Historical(t *testing.T) {
+	var gotParams url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotParams = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status": {"error_code": 0}, "data": {
+			"symbol": "ETH", "id": 1027, "name": "Ethereum", "amount": 2,
+			"quote": {"EUR": {"price": 6400.5, "last_updated": "2024-03-01T00:00:00Z"}}
+		}}`))
+	}))
+	defer server.Close()
+
+	repo := NewCoinMarketCapRepository(server.Client(), "test-key", server.URL)
+
+	request := newRequest(t, "2", "ETH", "EUR")
+	request.At = time.Date(2024, 3, 1, 2, 0, 0, 0, time.FixedZone("CET", 3600))
+	result, err := repo.GetConversionPrice(context.Background(), request)
+
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-01T01:00:00Z", gotParams.Get("time"))
+	assert.True(t, result.AsOf.Equal(request.At))
+	assert.Equal(t, "3200.25", result.ExchangeRate.String())
+}
+
+func TestCoinMarketCapRepository_GetConversionPrice_CircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := NewCoinMarketCapRepository(server.Client(), "test-key", server.URL)
+	repo.retry = &retry.Strategy{MaxAttempts: 4, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+	repo.breaker = retry.NewCircuitBreaker(&retry.BreakerSettings{
+		FailureThreshold: 0.5,
+		MinRequests:      3,
+		Window:           time.Minute,
+		Cooldown:         time.Minute,
+		HalfOpenMaxCalls: 1,
+	}, isUpstreamFailure)
+
+	_, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "BTC", "USD"))
+	assert.ErrorIs(t, err, domain.ErrCircuitOpen, "the fourth attempt finds the breaker open")
+	assert.Equal(t, int32(3), calls.Load())
+
+	start := time.Now()
+	_, err = repo.GetConversionPrice(context.Background(), newRequest(t, "1", "ETH", "USD"))
+	assert.ErrorIs(t, err, domain.ErrCircuitOpen)
+	assert.Equal(t, int32(3), calls.Load(), "no request while open")
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestCoinMarketCapRepository_GetConversionPrice_RetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"status": {"error_code": 1008, "error_message": "minute rate limit"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status": {"error_code": 0}, "data": {
+			"symbol": "BTC", "id": 1, "name": "Bitcoin", "amount": 1,
+			"quote": {"USD": {"price": 67000, "last_updated": "2026-03-01T00:00:00Z"}}
+		}}`))
+	}))
+	defer server.Close()
+
+	repo := NewCoinMarketCapRepository(server.Client(), "test-key", server.URL)
+	repo.retry = &retry.Strategy{MaxAttempts: 2, InitialDelay: time.Millisecond, MaxDelay: 2 * time.Second, Multiplier: 2}
+
+	start := time.Now()
+	result, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "BTC", "USD"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "67000", result.ConvertedAmount.String())
+	assert.Equal(t, int32(2), calls.Load())
+	assert.GreaterOrEqual(t, time.Since(start), time.Second, "waits as long as Retry-After asks")
+}
+
+func TestCoinMarketCapRepository_GetConversionPrice_CreditBudget(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status": {"error_code": 0, "credit_count": 2}, "data": {
+			"symbol": "BTC", "id": 1, "name": "Bitcoin", "amount": 1,
+			"quote": {
+				"USD": {"price": 67000, "last_updated": "2026-03-01T00:00:00Z"},
+				"EUR": {"price": 61000, "last_updated": "2026-03-01T00:00:00Z"}
+			}
+		}}`))
+	}))
+	defer server.Close()
+
+	ledger := NewCreditLedger("", 3, 0)
+	repo := NewCoinMarketCapRepository(server.Client(), "test-key", server.URL, WithCreditLedger(ledger))
+
+	requests := []*domain.ConversionRequest{newRequest(t, "1", "BTC", "USD"), newRequest(t, "1", "BTC", "EUR")}
+	_, err := repo.GetConversionPrices(context.Background(), requests)
+	require.NoError(t, err)
+
+	usage, ok := repo.CreditUsage()
+	require.True(t, ok)
+	assert.Equal(t, 2, usage.DayCredits)
+
+	// Two more targets would cost two credits, one more than the budget leaves
+	_, err = repo.GetConversionPrices(context.Background(), requests)
+	assert.ErrorIs(t, err, domain.ErrCreditBudgetExceeded)
+	assert.Equal(t, int32(1), calls.Load(), "the refused call never reaches the API")
+
+	_, err = repo.GetConversionPrice(context.Background(), requests[0])
+	assert.NoError(t, err)
+}
+
+func TestCoinMarketCapRepository_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status": {"error_code": 0}, "data": {
+			"symbol": "BTC", "id": 1, "name": "Bitcoin", "amount": 1,
+			"quote": {"USD": {"price": 67000, "last_updated": "2026-03-01T00:00:00Z"}}
+		}}`))
+	}))
+	defer server.Close()
+
+	limiter := ratelimit.NewTokenBucket(1200, 1)
+	repo := NewCoinMarketCapRepository(server.Client(), "test-key", server.URL, WithRateLimit(limiter))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "BTC", "USD"))
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, "1200/min spaces calls 50ms apart")
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// ConsensusMethod selects how accepted quotes are combined into one rate
+type ConsensusMethod string
+
+const (
+	// ConsensusMedian uses the median of the accepted quotes
+	ConsensusMedian ConsensusMethod = "median"
+
+	// ConsensusTrimmedMean averages the accepted quotes after dropping the
+	// highest and lowest ones
+	ConsensusTrimmedMean ConsensusMethod = "trimmed-mean"
+)
+
+const (
+	// consensusSource is reported as Source of consensus results
+	consensusSource = "consensus"
+
+	// trimFraction is the share of quotes dropped at each end by ConsensusTrimmedMean
+	trimFraction = 0.25
+)
+
+// DefaultConsensusTolerance discards quotes more than 1% away from the median
+var DefaultConsensusTolerance = domain.MustParseAmount("0.01")
+
+// ParseConsensusMethod parses a consensus method name such as "median"
+func ParseConsensusMethod(name string) (ConsensusMethod, error) {
+	switch method := ConsensusMethod(name); method {
+	case ConsensusMedian, ConsensusTrimmedMean:
+		return method, nil
+	default:
+		return "", fmt.Errorf("unknown consensus method %q (expected %s or %s)", name, ConsensusMedian, ConsensusTrimmedMean)
+	}
+}
+
+// ConsensusPriceRepository implements domain.PriceRepository by querying
+// several providers concurrently and agreeing on a single rate. Quotes that
+// deviate from the median by more than the tolerance are discarded.
+type ConsensusPriceRepository struct {
+	providers []NamedProvider
+	method    ConsensusMethod
+	tolerance domain.Amount
+	minQuotes int
+	now       func() time.Time
+}
+
+// ConsensusOption configures optional behaviour of ConsensusPriceRepository
+type ConsensusOption func(*ConsensusPriceRepository)
+
+// WithConsensusMethod selects how accepted quotes are combined
+func WithConsensusMethod(method ConsensusMethod) ConsensusOption {
+	return func(c *ConsensusPriceRepository) {
+		c.method = method
+	}
+}
+
+// WithTolerance sets the largest accepted relative deviation from the median, e.g. 0.01 for 1%
+func WithTolerance(tolerance domain.Amount) ConsensusOption {
+	return func(c *ConsensusPriceRepository) {
+		c.tolerance = tolerance
+	}
+}
+
+// WithMinQuotes sets how many accepted quotes a consensus needs
+func WithMinQuotes(n int) ConsensusOption {
+	return func(c *ConsensusPriceRepository) {
+		c.minQuotes = n
+	}
+}
+
+// NewConsensusPriceRepository creates a consensus over providers. By default
+// it takes the median of at least two quotes within 1% of each other.
+func NewConsensusPriceRepository(providers []NamedProvider, opts ...ConsensusOption) *ConsensusPriceRepository {
+	c := &ConsensusPriceRepository{
+		providers: providers,
+		method:    ConsensusMedian,
+		tolerance: DefaultConsensusTolerance,
+		minQuotes: 2,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// GetConversionPrice queries every provider and returns their consensus
+func (c *ConsensusPriceRepository) GetConversionPrice(
+	ctx context.Context,
+	request *domain.ConversionRequest,
+) (*domain.ConversionResult, error) {
+	results := make([]*domain.ConversionResult, len(c.providers))
+	quotes := make([]domain.ProviderQuote, len(c.providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range c.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := provider.Repository.GetConversionPrice(ctx, request)
+			quotes[i] = domain.ProviderQuote{Source: provider.Name, Err: err}
+			if err == nil {
+				results[i] = result
+				quotes[i].Rate = result.ExchangeRate
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The median of every answer is the reference for outlier rejection
+	var answered []domain.Amount
+	var errs []error
+	for _, q := range quotes {
+		if q.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", q.Source, q.Err))
+			continue
+		}
+		answered = append(answered, q.Rate)
+	}
+	if len(answered) < c.minQuotes || len(answered) == 0 {
+		return nil, c.noConsensus(len(answered), errs)
+	}
+
+	reference, err := median(answered)
+	if err != nil {
+		return nil, err
+	}
+
+	var accepted []domain.Amount
+	for i := range quotes {
+		if quotes[i].Err != nil {
+			continue
+		}
+		deviation, err := relativeDeviation(quotes[i].Rate, reference)
+		if err != nil {
+			return nil, err
+		}
+		if deviation.Cmp(c.tolerance) > 0 {
+			quotes[i].Rejected = true
+			continue
+		}
+		accepted = append(accepted, quotes[i].Rate)
+	}
+	if len(accepted) < c.minQuotes || len(accepted) == 0 {
+		return nil, c.noConsensus(len(accepted), errs)
+	}
+
+	rate, err := c.combine(accepted)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deviations are reported relative to the final consensus
+	for i := range quotes {
+		if quotes[i].Err == nil {
+			if quotes[i].Deviation, err = relativeDeviation(quotes[i].Rate, rate); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return c.buildResult(request, rate, answered, quotes, results), nil
+}
+
+// noConsensus reports that fewer than the required quotes were usable
+func (c *ConsensusPriceRepository) noConsensus(usable int, errs []error) error {
+	err := fmt.Errorf("%w: %d of %d required quotes usable", domain.ErrNoConsensus, usable, c.minQuotes)
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", err, errors.Join(errs...))
+	}
+	return err
+}
+
+// combine turns the accepted quotes into the consensus rate
+func (c *ConsensusPriceRepository) combine(rates []domain.Amount) (domain.Amount, error) {
+	if c.method == ConsensusTrimmedMean {
+		return trimmedMean(rates)
+	}
+	return median(rates)
+}
+
+// buildResult assembles the consensus result, taking currency metadata from
+// the first provider that answered
+func (c *ConsensusPriceRepository) buildResult(
+	request *domain.ConversionRequest,
+	rate domain.Amount,
+	answered []domain.Amount,
+	quotes []domain.ProviderQuote,
+	results []*domain.ConversionResult,
+) *domain.ConversionResult {
+	from, to := *request.FromCurrency, *request.ToCurrency
+	var lastUpdated time.Time
+	for i, r := range results {
+		if r == nil || quotes[i].Rejected {
+			continue
+		}
+		if lastUpdated.IsZero() {
+			from, to = *r.FromCurrency, *r.ToCurrency
+		}
+		// The consensus is only as recent as its oldest quote
+		if lastUpdated.IsZero() || r.LastUpdated.Before(lastUpdated) {
+			lastUpdated = r.LastUpdated
+		}
+	}
+
+	sorted := sortedRates(answered)
+	result := domain.NewConversionResult(
+		request.Amount,
+		request.Amount.Mul(rate),
+		rate,
+		&from,
+		&to,
+		c.now(),
+		lastUpdated,
+	)
+	result.AsOf = request.At
+	result.Source = fmt.Sprintf("%s (%s)", consensusSource, c.method)
+	result.Quotes = quotes
+	result.Spread = sorted[len(sorted)-1].Sub(sorted[0])
+	return result
+}
+
+// relativeDeviation returns |rate - reference| / reference
+func relativeDeviation(rate, reference domain.Amount) (domain.Amount, error) {
+	return rate.Sub(reference).Abs().Div(reference)
+}
+
+// sortedRates returns a sorted copy of rates
+func sortedRates(rates []domain.Amount) []domain.Amount {
+	sorted := append([]domain.Amount(nil), rates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	return sorted
+}
+
+// median returns the middle rate, averaging the two middle ones for even counts
+func median(rates []domain.Amount) (domain.Amount, error) {
+	sorted := sortedRates(rates)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid], nil
+	}
+	return sorted[mid-1].Add(sorted[mid]).Div(domain.NewAmountFromInt(2))
+}
+
+// trimmedMean averages the rates after dropping a quarter of them at each end
+func trimmedMean(rates []domain.Amount) (domain.Amount, error) {
+	sorted := sortedRates(rates)
+	trim := int(float64(len(sorted)) * trimFraction)
+	kept := sorted[trim : len(sorted)-trim]
+
+	sum := domain.NewAmountFromInt(0)
+	for _, r := range kept {
+		sum = sum.Add(r)
+	}
+	return sum.Div(domain.NewAmountFromInt(int64(len(kept))))
+}
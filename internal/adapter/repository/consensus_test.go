@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// quoting builds named stub providers quoting the given rates; an empty rate fails
+func quoting(rates map[string]string) []NamedProvider {
+	var providers []NamedProvider
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		rate, ok := rates[name]
+		if !ok {
+			continue
+		}
+		stub := &stubProvider{rate: rate}
+		if rate == "" {
+			stub.err = domain.ErrServerError
+		}
+		providers = append(providers, NamedProvider{Name: name, Repository: stub})
+	}
+	return providers
+}
+
+func TestConsensusPriceRepository_GetConversionPrice(t *testing.T) {
+	tests := []struct {
+		name         string
+		rates        map[string]string
+		opts         []ConsensusOption
+		wantRate     string
+		wantSpread   string
+		wantRejected []string
+		wantErr      error
+	}{
+		{
+			name:       "median of agreeing quotes",
+			rates:      map[string]string{"a": "1.0850", "b": "1.0852", "c": "1.0849"},
+			wantRate:   "1.0850",
+			wantSpread: "0.0003",
+		},
+		{
+			name:         "outlier is rejected",
+			rates:        map[string]string{"a": "1.0850", "b": "1.0852", "c": "1.20"},
+			wantRate:     "1.0851",
+			wantSpread:   "0.1150",
+			wantRejected: []string{"c"},
+		},
+		{
+			name:       "failed providers are ignored",
+			rates:      map[string]string{"a": "1.0850", "b": "", "c": "1.0852"},
+			wantRate:   "1.0851",
+			wantSpread: "0.0002",
+		},
+		{
+			name:  "trimmed mean drops the extremes",
+			rates: map[string]string{"a": "100", "b": "101", "c": "102", "d": "103", "e": "100.5"},
+			opts: []ConsensusOption{
+				WithConsensusMethod(ConsensusTrimmedMean),
+				WithTolerance(domain.MustParseAmount("0.05")),
+			},
+			wantRate:   "101.1666666666666666666666666666667",
+			wantSpread: "3",
+		},
+		{
+			name:    "too few quotes",
+			rates:   map[string]string{"a": "1.0850", "b": ""},
+			wantErr: domain.ErrNoConsensus,
+		},
+		{
+			name:    "quotes too far apart",
+			rates:   map[string]string{"a": "1.00", "b": "1.10"},
+			wantErr: domain.ErrNoConsensus,
+		},
+		{
+			name:     "a single quote when allowed",
+			rates:    map[string]string{"a": "1.0850", "b": ""},
+			opts:     []ConsensusOption{WithMinQuotes(1)},
+			wantRate: "1.0850",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewConsensusPriceRepository(quoting(tt.rates), tt.opts...)
+
+			result, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1000", "EUR", "USD"))
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRate, result.ExchangeRate.String())
+			assert.True(t, result.ConvertedAmount.Equal(result.ExchangeRate.Mul(domain.NewAmountFromInt(1000))))
+			if tt.wantSpread != "" {
+				assert.True(t, domain.MustParseAmount(tt.wantSpread).Equal(result.Spread), "spread %s", result.Spread)
+			}
+			assert.Len(t, result.Quotes, len(tt.rates))
+
+			var rejected []string
+			for _, q := range result.Quotes {
+				if q.Rejected {
+					rejected = append(rejected, q.Source)
+				}
+			}
+			assert.Equal(t, tt.wantRejected, rejected)
+		})
+	}
+}
+
+func TestConsensusPriceRepository_ReportsDeviations(t *testing.T) {
+	repo := NewConsensusPriceRepository(quoting(map[string]string{"a": "100", "b": "100.5", "c": "", "d": "99.5"}))
+
+	result, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "EUR", "USD"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "consensus (median)", result.Source)
+	require.Len(t, result.Quotes, 4)
+	assert.Equal(t, "a", result.Quotes[0].Source)
+	assert.Equal(t, "0", result.Quotes[0].Deviation.String())
+	assert.Equal(t, "0.005", result.Quotes[1].Deviation.String())
+	assert.ErrorIs(t, result.Quotes[2].Err, domain.ErrServerError)
+	assert.Equal(t, "1.0", result.Spread.String())
+}
+
+func TestParseConsensusMethod(t *testing.T) {
+	method, err := ParseConsensusMethod("trimmed-mean")
+	assert.NoError(t, err)
+	assert.Equal(t, ConsensusTrimmedMean, method)
+
+	_, err = ParseConsensusMethod("mode")
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contractStandIn describes a registered provider and an httptest stand-in
+// for its upstream API. The stand-in prices one unit of from at rate in to.
+type contractStandIn struct {
+	provider string
+	apiKey   string
+	from, to string
+	rate     string
+	handler  func(t *testing.T, rate domain.Amount) http.HandlerFunc
+}
+
+// contractStandIns lists every registered provider. Adding a provider
+// without adding its stand-in here fails TestPriceRepositoryContract.
+var contractStandIns = []contractStandIn{
+	{
+		provider: "coinmarketcap",
+		apiKey:   "test-key",
+		from:     "BTC",
+		to:       "USD",
+		rate:     "67000.125",
+		handler:  coinMarketCapStandIn,
+	},
+	{
+		provider: "ecb",
+		from:     "USD",
+		to:       "GBP",
+		rate:     "0.85405",
+		handler:  ecbStandIn,
+	},
+}
+
+// coinMarketCapStandIn serves /v1/tools/price-conversion for a single rate
+func coinMarketCapStandIn(t *testing.T, rate domain.Amount) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		amount, err := domain.ParseAmount(r.URL.Query().Get("amount"))
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"status": {"error_code": 0}, "data": {
+			"symbol": %q, "id": 1, "name": "Bitcoin", "amount": %s,
+			"quote": {%q: {"price": %s, "last_updated": "2025-11-08T12:34:56Z"}}
+		}}`, r.URL.Query().Get("symbol"), amount, r.URL.Query().Get("convert"), amount.Mul(rate))
+	}
+}
+
+// TestPriceRepositoryContract runs the behaviour every PriceRepository must
+// provide against each registered provider
+func TestPriceRepositoryContract(t *testing.T) {
+	covered := make(map[string]bool)
+	for _, standIn := range contractStandIns {
+		covered[standIn.provider] = true
+		t.Run(standIn.provider, func(t *testing.T) {
+			runPriceRepositoryContract(t, standIn)
+		})
+	}
+
+	for _, name := range Providers() {
+		assert.True(t, covered[name], "provider %q has no contract stand-in", name)
+	}
+}
+
+// runPriceRepositoryContract checks one provider against the shared contract
+func runPriceRepositoryContract(t *testing.T, standIn contractStandIn) {
+	rate := domain.MustParseAmount(standIn.rate)
+
+	newRepository := func(t *testing.T, handler http.Handler) domain.PriceRepository {
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+
+		repo, err := NewProvider(standIn.provider, server.Client(), ProviderSettings{
+			APIKey: standIn.apiKey,
+			APIURL: server.URL,
+		})
+		require.NoError(t, err)
+		return repo
+	}
+
+	t.Run("converts a supported pair", func(t *testing.T) {
+		repo := newRepository(t, standIn.handler(t, rate))
+		request := newRequest(t, "2.5", standIn.from, standIn.to)
+
+		result, err := repo.GetConversionPrice(context.Background(), request)
+
+		require.NoError(t, err)
+		assert.True(t, request.Amount.Equal(result.OriginalAmount), "original amount %s", result.OriginalAmount)
+		assert.True(t, request.Amount.Mul(rate).Equal(result.ConvertedAmount), "converted amount %s", result.ConvertedAmount)
+		assert.True(t, rate.Equal(result.ExchangeRate), "exchange rate %s", result.ExchangeRate)
+		assert.True(t, request.FromCurrency.Equals(result.FromCurrency))
+		assert.True(t, request.ToCurrency.Equals(result.ToCurrency))
+		assert.False(t, result.Timestamp.IsZero())
+	})
+
+	t.Run("rate does not depend on the amount", func(t *testing.T) {
+		repo := newRepository(t, standIn.handler(t, rate))
+
+		small, err := repo.GetConversionPrice(context.Background(), newRequest(t, "0.001", standIn.from, standIn.to))
+		require.NoError(t, err)
+		large, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1000000", standIn.from, standIn.to))
+		require.NoError(t, err)
+
+		assert.True(t, small.ExchangeRate.Equal(large.ExchangeRate), "%s != %s", small.ExchangeRate, large.ExchangeRate)
+	})
+
+	t.Run("reports upstream failures as domain errors", func(t *testing.T) {
+		repo := newRepository(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+
+		// Providers may retry; the deadline only bounds how long that takes
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		_, err := repo.GetConversionPrice(ctx, newRequest(t, "1", standIn.from, standIn.to))
+		assert.ErrorIs(t, err, domain.ErrServerError)
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		repo := newRepository(t, standIn.handler(t, rate))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := repo.GetConversionPrice(ctx, newRequest(t, "1", standIn.from, standIn.to))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+const (
+	// ledgerDayFormat and ledgerMonthFormat key the ledger in UTC
+	ledgerDayFormat   = time.DateOnly
+	ledgerMonthFormat = "2006-01"
+
+	// ledgerRetention is how long daily totals are kept
+	ledgerRetention = 62 * 24 * time.Hour
+
+	// ledgerLockTimeout bounds how long Record waits for another process to
+	// release the ledger file; ledgerLockStale is the age after which a lock
+	// left behind by a crashed process is broken
+	ledgerLockTimeout = 5 * time.Second
+	ledgerLockStale   = 10 * time.Second
+)
+
+// ledgerFile is the persisted form of a CreditLedger
+type ledgerFile struct {
+	Days   map[string]int `json:"days"`
+	Months map[string]int `json:"months"`
+}
+
+// CreditLedger accumulates the API credits reported by CoinMarketCap per UTC
+// day and month and refuses calls that would exceed the configured budgets.
+// The ledger is persisted so every run counts against the same budget.
+type CreditLedger struct {
+	path          string
+	dailyBudget   int
+	monthlyBudget int
+	now           func() time.Time
+
+	mu     sync.Mutex
+	ledger ledgerFile
+}
+
+// NewCreditLedger creates a ledger persisted at path, or kept in memory when
+// path is empty. A zero budget is unlimited.
+func NewCreditLedger(path string, dailyBudget, monthlyBudget int) *CreditLedger {
+	return &CreditLedger{
+		path:          path,
+		dailyBudget:   dailyBudget,
+		monthlyBudget: monthlyBudget,
+		now:           time.Now,
+		ledger:        ledgerFile{Days: map[string]int{}, Months: map[string]int{}},
+	}
+}
+
+// Check returns ErrCreditBudgetExceeded when spending credits now would
+// exceed the daily or monthly budget
+func (l *CreditLedger) Check(credits int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.load()
+
+	usage := l.usage()
+	if usage.DailyBudget > 0 && usage.DayCredits+credits > usage.DailyBudget {
+		return fmt.Errorf("%w: %d of %d daily credits used", domain.ErrCreditBudgetExceeded, usage.DayCredits, usage.DailyBudget)
+	}
+	if usage.MonthlyBudget > 0 && usage.MonthCredits+credits > usage.MonthlyBudget {
+		return fmt.Errorf("%w: %d of %d monthly credits used", domain.ErrCreditBudgetExceeded, usage.MonthCredits, usage.MonthlyBudget)
+	}
+	return nil
+}
+
+// Record adds credits spent now to the ledger. The ledger file is read,
+// updated and written under an exclusive lock so that processes recording at
+// the same time do not lose each other's credits.
+func (l *CreditLedger) Record(credits int) {
+	if credits <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Without the lock the credits are still recorded, at the risk of a race
+	if l.path != "" {
+		if unlock, err := lockFile(l.path); err == nil {
+			defer unlock()
+		}
+	}
+	l.load()
+
+	now := l.now().UTC()
+	l.ledger.Days[now.Format(ledgerDayFormat)] += credits
+	l.ledger.Months[now.Format(ledgerMonthFormat)] += credits
+	l.prune(now)
+
+	// A failure to persist only loses track of these credits
+	_ = l.persist()
+}
+
+// Usage returns the credits spent in the current day and month
+func (l *CreditLedger) Usage() domain.CreditUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.load()
+
+	return l.usage()
+}
+
+// usage computes the current usage; callers hold l.mu
+func (l *CreditLedger) usage() domain.CreditUsage {
+	now := l.now().UTC()
+	return domain.CreditUsage{
+		Day:           time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		DayCredits:    l.ledger.Days[now.Format(ledgerDayFormat)],
+		MonthCredits:  l.ledger.Months[now.Format(ledgerMonthFormat)],
+		DailyBudget:   l.dailyBudget,
+		MonthlyBudget: l.monthlyBudget,
+	}
+}
+
+// load re-reads the ledger file so credits spent by other processes count;
+// callers hold l.mu
+func (l *CreditLedger) load() {
+	if l.path == "" {
+		return
+	}
+
+	// A missing or corrupt ledger keeps the totals known to this process
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return
+	}
+	var stored ledgerFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return
+	}
+
+	// Totals only grow, so the larger one is the most recent
+	for day, credits := range stored.Days {
+		l.ledger.Days[day] = max(l.ledger.Days[day], credits)
+	}
+	for month, credits := range stored.Months {
+		l.ledger.Months[month] = max(l.ledger.Months[month], credits)
+	}
+}
+
+// prune drops totals too old to matter; callers hold l.mu
+func (l *CreditLedger) prune(now time.Time) {
+	oldestDay := now.Add(-ledgerRetention).Format(ledgerDayFormat)
+	for day := range l.ledger.Days {
+		if day < oldestDay {
+			delete(l.ledger.Days, day)
+		}
+	}
+
+	oldestMonth := now.AddDate(-1, 0, 0).Format(ledgerMonthFormat)
+	for month := range l.ledger.Months {
+		if month < oldestMonth {
+			delete(l.ledger.Months, month)
+		}
+	}
+}
+
+// persist atomically replaces the ledger file; callers hold l.mu
+func (l *CreditLedger) persist() error {
+	if l.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(l.ledger)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(l.path, data)
+}
+
+// lockFile takes an exclusive lock on path by creating path.lock, waiting up
+// to ledgerLockTimeout while another process holds it. The returned function
+// releases the lock.
+func lockFile(path string) (func(), error) {
+	lock := path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lock), 0o755); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(ledgerLockTimeout)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > ledgerLockStale {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another process", path)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreditLedger_Budgets(t *testing.T) {
+	now := time.Date(2026, 3, 30, 23, 0, 0, 0, time.UTC)
+	ledger := NewCreditLedger("", 12, 18)
+	ledger.now = func() time.Time { return now }
+
+	require.NoError(t, ledger.Check(12))
+	ledger.Record(8)
+	assert.NoError(t, ledger.Check(4))
+	err := ledger.Check(5)
+	assert.ErrorIs(t, err, domain.ErrCreditBudgetExceeded)
+	assert.Contains(t, err.Error(), "8 of 12 daily credits used")
+
+	// A new day resets the daily total but not the monthly one
+	now = now.Add(24 * time.Hour)
+	ledger.Record(8)
+	assert.NoError(t, ledger.Check(2))
+	err = ledger.Check(3)
+	assert.ErrorIs(t, err, domain.ErrCreditBudgetExceeded)
+	assert.Contains(t, err.Error(), "16 of 18 monthly credits used")
+
+	// A new month starts over
+	now = now.Add(2 * time.Hour)
+	assert.NoError(t, ledger.Check(12))
+	assert.Equal(t, domain.CreditUsage{
+		Day:           time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		DailyBudget:   12,
+		MonthlyBudget: 18,
+	}, ledger.Usage())
+}
+
+func TestCreditLedger_Unlimited(t *testing.T) {
+	ledger := NewCreditLedger("", 0, 0)
+	ledger.Record(1_000_000)
+	assert.NoError(t, ledger.Check(1_000_000))
+	assert.Equal(t, 1_000_000, ledger.Usage().MonthCredits)
+}
+
+func TestCreditLedger_SharedBetweenProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credits.json")
+	now := func() time.Time { return time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC) }
+
+	first := NewCreditLedger(path, 0, 100)
+	first.now = now
+	second := NewCreditLedger(path, 0, 100)
+	second.now = now
+
+	first.Record(3)
+	assert.Equal(t, 3, second.Usage().DayCredits)
+
+	second.Record(4)
+	assert.Equal(t, 7, first.Usage().DayCredits)
+	assert.Equal(t, 7, first.Usage().MonthCredits)
+}
+
+func TestCreditLedger_ConcurrentProcessesCountEveryCredit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credits.json")
+	now := func() time.Time { return time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC) }
+
+	const processes, records = 4, 50
+	var wg sync.WaitGroup
+	for range processes {
+		ledger := NewCreditLedger(path, 0, 0)
+		ledger.now = now
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range records {
+				ledger.Record(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	reader := NewCreditLedger(path, 0, 0)
+	reader.now = now
+	assert.Equal(t, processes*records, reader.Usage().DayCredits)
+	assert.NoFileExists(t, path+".lock")
+}
+
+func TestCreditLedger_Prunes(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	ledger := NewCreditLedger("", 0, 0)
+	ledger.now = func() time.Time { return now }
+
+	ledger.Record(1)
+	now = now.AddDate(1, 1, 0)
+	ledger.Record(1)
+
+	assert.Len(t, ledger.ledger.Days, 1)
+	assert.Len(t, ledger.ledger.Months, 1)
+}
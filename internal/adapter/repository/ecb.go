@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/kerimovkk/currency-conversion-utility/pkg/retry"
+)
+
+const (
+	// defaultECBBaseURL is where the ECB publishes its euro foreign exchange reference rates
+	defaultECBBaseURL = "https://www.ecb.europa.eu/stats/eurofxref"
+
+	// ecbDailyFeed holds the reference rates of the latest business day
+	ecbDailyFeed = "/eurofxref-daily.xml"
+
+	// ecbHistoryFeed holds the reference rates of the last 90 days
+	ecbHistoryFeed = "/eurofxref-hist-90d.xml"
+
+	// ecbFeedTTL is how long a downloaded feed is reused; rates change once a day
+	ecbFeedTTL = time.Hour
+
+	// ecbBaseCurrency is the currency all reference rates are quoted against
+	ecbBaseCurrency = "EUR"
+
+	// ecbSource names the ECB in registries and results
+	ecbSource = "ecb"
+)
+
+func init() {
+	Register(ecbSource, newECBProvider)
+}
+
+// newECBProvider is the ProviderFactory for the European Central Bank
+func newECBProvider(httpClient *http.Client, settings ProviderSettings) (domain.PriceRepository, error) {
+	baseURL := settings.APIURL
+	if baseURL == "" {
+		baseURL = defaultECBBaseURL
+	}
+	return NewECBRepository(httpClient, baseURL), nil
+}
+
+// ecbEnvelope mirrors the layout of the eurofxref XML feeds
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// ecbDay holds the reference rates of one business day, keyed by currency
+type ecbDay struct {
+	date  time.Time
+	rates map[string]domain.Amount
+}
+
+// ecbFeed is a parsed feed, newest day first
+type ecbFeed struct {
+	days      []ecbDay
+	fetchedAt time.Time
+}
+
+// ECBRepository implements domain.PriceRepository using the euro foreign
+// exchange reference rates of the European Central Bank. Any pair of
+// published currencies is triangulated through EUR.
+type ECBRepository struct {
+	httpClient *http.Client
+	baseURL    string
+	retry      *retry.Strategy
+	now        func() time.Time
+
+	mu    sync.Mutex
+	feeds map[string]*ecbFeed
+}
+
+// NewECBRepository creates a new ECB reference-rate client
+func NewECBRepository(httpClient *http.Client, baseURL string) *ECBRepository {
+	return &ECBRepository{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		retry:      retry.DefaultStrategy(),
+		now:        time.Now,
+		feeds:      make(map[string]*ecbFeed),
+	}
+}
+
+// GetConversionPrice converts using the reference rates of the latest
+// business day, or of the last business day on or before request.At
+func (e *ECBRepository) GetConversionPrice(
+	ctx context.Context,
+	request *domain.ConversionRequest,
+) (*domain.ConversionResult, error) {
+	path := ecbDailyFeed
+	if request.IsHistorical() {
+		path = ecbHistoryFeed
+	}
+
+	feed, err := e.feed(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := feed.day(request.At)
+	if err != nil {
+		return nil, err
+	}
+
+	fromRate, err := day.rate(request.FromCurrency)
+	if err != nil {
+		return nil, err
+	}
+	toRate, err := day.rate(request.ToCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Both rates are quoted per euro, so their ratio is the cross rate
+	exchangeRate, err := toRate.Div(fromRate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: zero rate for %s", domain.ErrInvalidResponse, request.FromCurrency)
+	}
+
+	from, to := *request.FromCurrency, *request.ToCurrency
+	result := domain.NewConversionResult(
+		request.Amount,
+		request.Amount.Mul(exchangeRate),
+		exchangeRate,
+		&from,
+		&to,
+		e.now(),
+		day.date,
+	)
+	result.AsOf = request.At
+	result.Source = ecbSource
+
+	return result, nil
+}
+
+// day returns the newest day on or before at; a zero at selects the newest day
+func (f *ecbFeed) day(at time.Time) (*ecbDay, error) {
+	for i := range f.days {
+		if at.IsZero() || !f.days[i].date.After(at) {
+			return &f.days[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no ECB reference rates for %s", domain.ErrInvalidTimestamp, at.Format(time.DateOnly))
+}
+
+// rate returns how many units of currency one euro buys
+func (d *ecbDay) rate(currency *domain.Currency) (domain.Amount, error) {
+	if currency.Symbol == ecbBaseCurrency {
+		return domain.NewAmountFromInt(1), nil
+	}
+	rate, ok := d.rates[currency.Symbol]
+	if !ok {
+		return domain.Amount{}, fmt.Errorf("%w: ECB publishes no reference rate for %s", domain.ErrUnsupportedPair, currency)
+	}
+	return rate, nil
+}
+
+// feed returns the parsed feed at path, downloading it when not cached yet
+func (e *ECBRepository) feed(ctx context.Context, path string) (*ecbFeed, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if cached, ok := e.feeds[path]; ok && e.now().Sub(cached.fetchedAt) < ecbFeedTTL {
+		return cached, nil
+	}
+
+	// Execute with retry logic
+	feed, err := retry.DoValue(ctx, e.retry, e.shouldRetry, func(ctx context.Context) (*ecbFeed, error) {
+		return e.fetchFeed(ctx, path)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	e.feeds[path] = feed
+	return feed, nil
+}
+
+// fetchFeed downloads and parses one eurofxref feed
+func (e *ECBRepository) fetchFeed(ctx context.Context, path string) (*ecbFeed, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", e.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrAPIFailure, err)
+	}
+	req.Header.Set("Accept", "application/xml")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrNetworkFailure, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read response body", domain.ErrAPIFailure)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, e.handleHTTPError(resp.StatusCode)
+	}
+
+	return parseECBFeed(body, e.now())
+}
+
+// parseECBFeed decodes a eurofxref document into days sorted newest first
+func parseECBFeed(body []byte, fetchedAt time.Time) (*ecbFeed, error) {
+	var envelope ecbEnvelope
+	if err := xml.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidResponse, err)
+	}
+	if len(envelope.Days) == 0 {
+		return nil, fmt.Errorf("%w: feed contains no reference rates", domain.ErrInvalidResponse)
+	}
+
+	feed := &ecbFeed{fetchedAt: fetchedAt}
+	for _, d := range envelope.Days {
+		date, err := time.Parse(time.DateOnly, d.Time)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid date %q", domain.ErrInvalidResponse, d.Time)
+		}
+
+		day := ecbDay{date: date, rates: make(map[string]domain.Amount, len(d.Rates))}
+		for _, r := range d.Rates {
+			rate, err := domain.ParseAmount(r.Rate)
+			if err != nil || rate.Sign() <= 0 {
+				return nil, fmt.Errorf("%w: invalid rate %q for %s", domain.ErrInvalidResponse, r.Rate, r.Currency)
+			}
+			day.rates[r.Currency] = rate
+		}
+		feed.days = append(feed.days, day)
+	}
+
+	sort.Slice(feed.days, func(i, j int) bool { return feed.days[i].date.After(feed.days[j].date) })
+	return feed, nil
+}
+
+// handleHTTPError converts HTTP error codes to domain errors
+func (e *ECBRepository) handleHTTPError(statusCode int) error {
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return domain.ErrRateLimitExceeded
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return domain.ErrServerError
+	default:
+		return fmt.Errorf("%w: HTTP %d", domain.ErrAPIFailure, statusCode)
+	}
+}
+
+// shouldRetry determines if an error should trigger a retry
+func (e *ECBRepository) shouldRetry(err error) bool {
+	return errors.Is(err, domain.ErrRateLimitExceeded) ||
+		errors.Is(err, domain.ErrServerError) ||
+		errors.Is(err, domain.ErrNetworkFailure)
+}
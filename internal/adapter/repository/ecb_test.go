@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newECBFileServer serves the sample feeds in testdata/ecb and counts requests
+func newECBFileServer(t *testing.T, calls *int32) *httptest.Server {
+	t.Helper()
+
+	files := http.FileServer(http.Dir("testdata/ecb"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		files.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// ecbStandIn serves a daily feed in which one unit of from buys rate units of to
+func ecbStandIn(t *testing.T, rate domain.Amount) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, ecbDailyFeed, r.URL.Path)
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube><Cube time="2024-03-05">
+		<Cube currency="USD" rate="1"/>
+		<Cube currency="GBP" rate="%s"/>
+	</Cube></Cube>
+</gesmes:Envelope>`, rate)
+	}
+}
+
+func TestECBRepository_GetConversionPrice(t *testing.T) {
+	tests := []struct {
+		name          string
+		amount        string
+		from, to      string
+		at            time.Time
+		wantConverted string
+		wantRate      string
+		wantDate      time.Time
+		wantErr       error
+	}{
+		{
+			name:          "from the base currency",
+			amount:        "100",
+			from:          "EUR",
+			to:            "USD",
+			wantConverted: "108.500",
+			wantRate:      "1.085",
+			wantDate:      time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "triangulated through EUR",
+			amount:        "1",
+			from:          "USD",
+			to:            "GBP",
+			wantConverted: "0.7871428571428571428571428571428571",
+			wantRate:      "0.7871428571428571428571428571428571",
+			wantDate:      time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "historical date on a business day",
+			amount:        "2",
+			from:          "EUR",
+			to:            "CHF",
+			at:            time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC),
+			wantConverted: "1.9202",
+			wantRate:      "0.9601",
+			wantDate:      time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "historical weekend uses the previous business day",
+			amount:        "1",
+			from:          "USD",
+			to:            "EUR",
+			at:            time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+			wantConverted: "0.9233610341643582640812557710064635",
+			wantRate:      "0.9233610341643582640812557710064635",
+			wantDate:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "date before the 90-day feed",
+			amount:  "1",
+			from:    "EUR",
+			to:      "USD",
+			at:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			wantErr: domain.ErrInvalidTimestamp,
+		},
+		{
+			name:    "currency without a reference rate",
+			amount:  "1",
+			from:    "BTC",
+			to:      "EUR",
+			wantErr: domain.ErrUnsupportedPair,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := newECBFileServer(t, &calls)
+			repo := NewECBRepository(server.Client(), server.URL)
+
+			request := newRequest(t, tt.amount, tt.from, tt.to)
+			request.At = tt.at
+			result, err := repo.GetConversionPrice(context.Background(), request)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantConverted, result.ConvertedAmount.String())
+			assert.Equal(t, tt.wantRate, result.ExchangeRate.String())
+			assert.Equal(t, tt.wantDate, result.LastUpdated)
+			assert.Equal(t, tt.at, result.AsOf)
+			assert.Equal(t, "ecb", result.Source)
+		})
+	}
+}
+
+func TestECBRepository_ReusesDownloadedFeed(t *testing.T) {
+	var calls int32
+	server := newECBFileServer(t, &calls)
+	repo := NewECBRepository(server.Client(), server.URL)
+
+	for _, target := range []string{"USD", "GBP", "JPY"} {
+		_, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "EUR", target))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// The feed is downloaded again once it is older than a day's update
+	repo.now = func() time.Time { return time.Now().Add(2 * ecbFeedTTL) }
+	_, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "EUR", "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestParseECBFeed_RejectsMalformedDocuments(t *testing.T) {
+	for _, body := range []string{
+		`not xml`,
+		`<Envelope><Cube></Cube></Envelope>`,
+		`<Envelope><Cube><Cube time="yesterday"><Cube currency="USD" rate="1.1"/></Cube></Cube></Envelope>`,
+		`<Envelope><Cube><Cube time="2024-03-05"><Cube currency="USD" rate="abc"/></Cube></Cube></Envelope>`,
+	} {
+		_, err := parseECBFeed([]byte(body), time.Now())
+		assert.ErrorIs(t, err, domain.ErrInvalidResponse, body)
+	}
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// DefaultFailoverErrors are the errors that make FallbackPriceRepository try
+// the next provider when no policy is configured
+var DefaultFailoverErrors = []error{
+	domain.ErrServerError,
+	domain.ErrRateLimitExceeded,
+	domain.ErrNetworkFailure,
+	domain.ErrCircuitOpen,
+	domain.ErrCreditBudgetExceeded,
+	domain.ErrUnsupportedPair,
+}
+
+// DefaultUnhealthyCooldown is how long a failing provider is skipped
+const DefaultUnhealthyCooldown = time.Minute
+
+// pairErrors concern the requested pair rather than the provider's health
+var pairErrors = []error{
+	domain.ErrUnsupportedPair,
+	domain.ErrInvalidCurrency,
+}
+
+// NamedProvider is a price provider together with the name it reports as Source
+type NamedProvider struct {
+	Name       string
+	Repository domain.PriceRepository
+}
+
+// FallbackPriceRepository implements domain.PriceRepository over an ordered
+// list of providers. A provider failing with a failover error is marked
+// unhealthy for a cool-down period and the next one is tried. Unhealthy
+// providers are skipped until every healthy one has failed as well.
+type FallbackPriceRepository struct {
+	providers  []NamedProvider
+	failoverOn []error
+	cooldown   time.Duration
+	statePath  string
+	now        func() time.Time
+
+	mu             sync.Mutex
+	loaded         bool
+	unhealthyUntil map[string]time.Time
+}
+
+// FallbackOption configures optional behaviour of FallbackPriceRepository
+type FallbackOption func(*FallbackPriceRepository)
+
+// WithFailoverErrors replaces the errors that trigger failover
+func WithFailoverErrors(errs ...error) FallbackOption {
+	return func(f *FallbackPriceRepository) {
+		f.failoverOn = errs
+	}
+}
+
+// WithUnhealthyCooldown sets how long a failing provider is skipped
+func WithUnhealthyCooldown(cooldown time.Duration) FallbackOption {
+	return func(f *FallbackPriceRepository) {
+		f.cooldown = cooldown
+	}
+}
+
+// WithHealthState persists provider health at path so separate processes
+// skip a provider another one has just seen failing
+func WithHealthState(path string) FallbackOption {
+	return func(f *FallbackPriceRepository) {
+		f.statePath = path
+	}
+}
+
+// NewFallbackPriceRepository creates a fallback chain trying providers in order
+func NewFallbackPriceRepository(providers []NamedProvider, opts ...FallbackOption) *FallbackPriceRepository {
+	f := &FallbackPriceRepository{
+		providers:      providers,
+		failoverOn:     DefaultFailoverErrors,
+		cooldown:       DefaultUnhealthyCooldown,
+		now:            time.Now,
+		unhealthyUntil: make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// FailoverErrorsFromCodes maps domain error codes such as "server_error" to
+// the errors they name
+func FailoverErrorsFromCodes(codes []string) ([]error, error) {
+	errs := make([]error, 0, len(codes))
+	for _, code := range codes {
+		err, ok := domain.ErrorForCode(code)
+		if !ok {
+			return nil, fmt.Errorf("unknown error code %q in failover policy", code)
+		}
+		errs = append(errs, err)
+	}
+	return errs, nil
+}
+
+// GetConversionPrice asks each provider in turn until one answers
+func (f *FallbackPriceRepository) GetConversionPrice(
+	ctx context.Context,
+	request *domain.ConversionRequest,
+) (*domain.ConversionResult, error) {
+	results, err := f.GetConversionPrices(ctx, []*domain.ConversionRequest{request})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// GetConversionPrices asks each provider in turn to price all requests
+func (f *FallbackPriceRepository) GetConversionPrices(
+	ctx context.Context,
+	requests []*domain.ConversionRequest,
+) ([]*domain.ConversionResult, error) {
+	var errs []error
+
+	for _, provider := range f.order() {
+		results, err := fetchAll(ctx, provider.Repository, requests)
+		if err == nil {
+			f.markHealthy(provider.Name)
+			answered := make([]*domain.ConversionResult, len(results))
+			for i, result := range results {
+				copied := *result
+				copied.Source = provider.Name
+				answered[i] = &copied
+			}
+			return answered, nil
+		}
+
+		if !f.shouldFailover(err) || ctx.Err() != nil {
+			return nil, err
+		}
+		if !isAny(err, pairErrors) {
+			f.markUnhealthy(provider.Name)
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name, err))
+	}
+
+	return nil, fmt.Errorf("all price providers failed: %w", errors.Join(errs...))
+}
+
+// order returns healthy providers first, keeping the configured order otherwise
+func (f *FallbackPriceRepository) order() []NamedProvider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.load()
+
+	now := f.now()
+	healthy := make([]NamedProvider, 0, len(f.providers))
+	var unhealthy []NamedProvider
+	for _, provider := range f.providers {
+		if now.Before(f.unhealthyUntil[provider.Name]) {
+			unhealthy = append(unhealthy, provider)
+		} else {
+			healthy = append(healthy, provider)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// shouldFailover reports whether err lets the next provider try
+func (f *FallbackPriceRepository) shouldFailover(err error) bool {
+	return isAny(err, f.failoverOn)
+}
+
+// isAny reports whether err wraps any of targets
+func isAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// markUnhealthy skips the named provider until the cool-down has passed
+func (f *FallbackPriceRepository) markUnhealthy(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.unhealthyUntil[name] = f.now().Add(f.cooldown)
+	f.persist()
+}
+
+// markHealthy clears an earlier unhealthy mark
+func (f *FallbackPriceRepository) markHealthy(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.unhealthyUntil[name]; !ok {
+		return
+	}
+	delete(f.unhealthyUntil, name)
+	f.persist()
+}
+
+// load reads the health state file once; callers hold f.mu
+func (f *FallbackPriceRepository) load() {
+	if f.loaded || f.statePath == "" {
+		return
+	}
+	f.loaded = true
+
+	// A missing or corrupt state file means every provider is healthy
+	data, err := os.ReadFile(f.statePath)
+	if err != nil {
+		return
+	}
+	_ = json.Unmarshal(data, &f.unhealthyUntil)
+}
+
+// persist writes the health state file; callers hold f.mu
+func (f *FallbackPriceRepository) persist() {
+	if f.statePath == "" {
+		return
+	}
+
+	data, err := json.Marshal(f.unhealthyUntil)
+	if err != nil {
+		return
+	}
+	// Losing the state only means a failing provider is tried again
+	_ = writeFileAtomic(f.statePath, data)
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubProvider answers with a fixed rate or fails with err, counting calls
+type stubProvider struct {
+	rate  string
+	err   error
+	calls int
+}
+
+func (s *stubProvider) GetConversionPrice(
+	_ context.Context,
+	request *domain.ConversionRequest,
+) (*domain.ConversionResult, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	rate := domain.MustParseAmount(s.rate)
+	return domain.NewConversionResult(
+		request.Amount, request.Amount.Mul(rate), rate,
+		request.FromCurrency, request.ToCurrency, time.Now(), time.Now(),
+	), nil
+}
+
+func TestFallbackPriceRepository_FailsOver(t *testing.T) {
+	tests := []struct {
+		name        string
+		primaryErr  error
+		wantSource  string
+		wantErr     error
+		wantBackups int
+	}{
+		{
+			name:        "primary answers",
+			wantSource:  "primary",
+			wantBackups: 0,
+		},
+		{
+			name:        "server error falls through",
+			primaryErr:  domain.ErrServerError,
+			wantSource:  "backup",
+			wantBackups: 1,
+		},
+		{
+			name:        "wrapped rate limit falls through",
+			primaryErr:  fmt.Errorf("max retry attempts (4) exceeded: %w", domain.ErrRateLimitExceeded),
+			wantSource:  "backup",
+			wantBackups: 1,
+		},
+		{
+			name:        "unsupported pair falls through",
+			primaryErr:  domain.ErrUnsupportedPair,
+			wantSource:  "backup",
+			wantBackups: 1,
+		},
+		{
+			name:        "unauthorized is returned as is",
+			primaryErr:  domain.ErrUnauthorized,
+			wantErr:     domain.ErrUnauthorized,
+			wantBackups: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &stubProvider{rate: "2", err: tt.primaryErr}
+			backup := &stubProvider{rate: "3"}
+			repo := NewFallbackPriceRepository([]NamedProvider{
+				{Name: "primary", Repository: primary},
+				{Name: "backup", Repository: backup},
+			})
+
+			result, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "EUR", "USD"))
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantSource, result.Source)
+			}
+			assert.Equal(t, tt.wantBackups, backup.calls)
+		})
+	}
+}
+
+func TestFallbackPriceRepository_AllProvidersFail(t *testing.T) {
+	repo := NewFallbackPriceRepository([]NamedProvider{
+		{Name: "primary", Repository: &stubProvider{err: domain.ErrServerError}},
+		{Name: "backup", Repository: &stubProvider{err: domain.ErrNetworkFailure}},
+	})
+
+	_, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "EUR", "USD"))
+
+	assert.ErrorIs(t, err, domain.ErrServerError)
+	assert.ErrorIs(t, err, domain.ErrNetworkFailure)
+	assert.Contains(t, err.Error(), "primary")
+	assert.Contains(t, err.Error(), "backup")
+}
+
+func TestFallbackPriceRepository_SkipsUnhealthyProviders(t *testing.T) {
+	primary := &stubProvider{rate: "2", err: domain.ErrServerError}
+	backup := &stubProvider{rate: "3"}
+	statePath := filepath.Join(t.TempDir(), "health.json")
+	newRepo := func() *FallbackPriceRepository {
+		return NewFallbackPriceRepository([]NamedProvider{
+			{Name: "primary", Repository: primary},
+			{Name: "backup", Repository: backup},
+		}, WithUnhealthyCooldown(time.Minute), WithHealthState(statePath))
+	}
+
+	repo := newRepo()
+	_, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "EUR", "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, primary.calls)
+
+	// The primary is skipped during its cool-down, also by another process
+	primary.err = nil
+	for _, r := range []*FallbackPriceRepository{repo, newRepo()} {
+		result, err := r.GetConversionPrice(context.Background(), newRequest(t, "1", "EUR", "USD"))
+		require.NoError(t, err)
+		assert.Equal(t, "backup", result.Source)
+	}
+	assert.Equal(t, 1, primary.calls)
+
+	// After the cool-down it is tried first again
+	repo.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	result, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "EUR", "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, "primary", result.Source)
+}
+
+func TestFallbackPriceRepository_TriesUnhealthyProvidersLast(t *testing.T) {
+	primary := &stubProvider{rate: "2", err: domain.ErrServerError}
+	backup := &stubProvider{rate: "3", err: domain.ErrUnsupportedPair}
+	repo := NewFallbackPriceRepository([]NamedProvider{
+		{Name: "primary", Repository: primary},
+		{Name: "backup", Repository: backup},
+	})
+
+	_, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "EUR", "USD"))
+	require.Error(t, err)
+
+	// An unsupported pair says nothing about health, so only the primary is
+	// skipped; once the backup cannot help the primary is tried anyway
+	primary.err = nil
+	result, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "EUR", "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, "primary", result.Source)
+	assert.Equal(t, []int{2, 2}, []int{primary.calls, backup.calls})
+}
+
+func TestFallbackPriceRepository_ConfigurablePolicy(t *testing.T) {
+	failoverOn, err := FailoverErrorsFromCodes([]string{"unauthorized"})
+	require.NoError(t, err)
+
+	backup := &stubProvider{rate: "3"}
+	repo := NewFallbackPriceRepository([]NamedProvider{
+		{Name: "primary", Repository: &stubProvider{err: domain.ErrUnauthorized}},
+		{Name: "backup", Repository: backup},
+	}, WithFailoverErrors(failoverOn...))
+
+	result, err := repo.GetConversionPrice(context.Background(), newRequest(t, "1", "EUR", "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, "backup", result.Source)
+
+	_, err = FailoverErrorsFromCodes([]string{"bogus"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// ProviderSettings holds the settings of a single price provider
+type ProviderSettings struct {
+	APIKey string
+	APIURL string
+
+	// RequestsPerMinute limits API calls on the client side; zero is unlimited
+	RequestsPerMinute int
+
+	// CreditLedger is where spent API credits are recorded; empty keeps them in memory
+	CreditLedger string
+
+	// DailyCreditBudget and MonthlyCreditBudget cap spent API credits; zero is unlimited
+	DailyCreditBudget   int
+	MonthlyCreditBudget int
+}
+
+// ProviderFactory creates a price provider from its settings
+type ProviderFactory func(httpClient *http.Client, settings ProviderSettings) (domain.PriceRepository, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]ProviderFactory)
+)
+
+// Register makes a price provider available under name. Providers register
+// themselves from init functions; registering a name twice panics.
+func Register(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if factory == nil {
+		panic("repository: Register factory is nil")
+	}
+	if _, dup := providers[name]; dup {
+		panic("repository: Register called twice for provider " + name)
+	}
+	providers[name] = factory
+}
+
+// NewProvider creates the price provider registered under name
+func NewProvider(name string, httpClient *http.Client, settings ProviderSettings) (domain.PriceRepository, error) {
+	providersMu.RLock()
+	factory, ok := providers[name]
+	providersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %s)", domain.ErrUnknownProvider, name, strings.Join(Providers(), ", "))
+	}
+	return factory(httpClient, settings)
+}
+
+// Providers returns the names of all registered providers in sorted order
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
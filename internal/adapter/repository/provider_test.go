@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticRepository is a trivial provider used to exercise the registry
+type staticRepository struct{}
+
+func (staticRepository) GetConversionPrice(context.Context, *domain.ConversionRequest) (*domain.ConversionResult, error) {
+	return nil, domain.ErrAPIFailure
+}
+
+func TestRegister(t *testing.T) {
+	t.Cleanup(func() {
+		providersMu.Lock()
+		delete(providers, "static-test")
+		providersMu.Unlock()
+	})
+
+	var gotSettings ProviderSettings
+	Register("static-test", func(_ *http.Client, settings ProviderSettings) (domain.PriceRepository, error) {
+		gotSettings = settings
+		return staticRepository{}, nil
+	})
+
+	assert.Contains(t, Providers(), "static-test")
+	assert.Panics(t, func() {
+		Register("static-test", func(*http.Client, ProviderSettings) (domain.PriceRepository, error) {
+			return nil, nil
+		})
+	})
+
+	repo, err := NewProvider("static-test", http.DefaultClient, ProviderSettings{APIURL: "https://example.com"})
+	require.NoError(t, err)
+	assert.IsType(t, staticRepository{}, repo)
+	assert.Equal(t, "https://example.com", gotSettings.APIURL)
+}
+
+func TestNewProvider(t *testing.T) {
+	_, err := NewProvider("nope", http.DefaultClient, ProviderSettings{})
+	assert.ErrorIs(t, err, domain.ErrUnknownProvider)
+	assert.Contains(t, err.Error(), "coinmarketcap")
+
+	_, err = NewProvider("coinmarketcap", http.DefaultClient, ProviderSettings{})
+	assert.ErrorIs(t, err, domain.ErrAPIKeyMissing)
+
+	repo, err := NewProvider("coinmarketcap", http.DefaultClient, ProviderSettings{APIKey: "key", APIURL: "https://example.com"})
+	require.NoError(t, err)
+	assert.IsType(t, &CoinMarketCapRepository{}, repo)
+}
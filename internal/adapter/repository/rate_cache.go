@@ -0,0 +1,383 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+const (
+	// refreshTimeout bounds a background revalidation of stale rates
+	refreshTimeout = 30 * time.Second
+
+	// closeTimeout bounds how long Close waits for background revalidations,
+	// so a slow refresh does not hold up the exit of a CLI run
+	closeTimeout = 2 * time.Second
+
+	// maxHistoricalRates caps the persisted historical rates, about five
+	// yearly history runs, so the cache file does not grow without bound
+	maxHistoricalRates = 2000
+)
+
+// rateEntry is a cached exchange rate for one currency pair
+type rateEntry struct {
+	From        *domain.Currency `json:"from"`
+	To          *domain.Currency `json:"to"`
+	Rate        domain.Amount    `json:"rate"`
+	LastUpdated time.Time        `json:"last_updated"`
+	FetchedAt   time.Time        `json:"fetched_at"`
+	Source      string           `json:"source,omitempty"`
+
+	// Historical marks rates for a fixed point in time, which never expire
+	// but are evicted, least recently fetched first, beyond maxHistorical
+	Historical bool `json:"historical,omitempty"`
+}
+
+// CachingPriceRepository decorates a domain.PriceRepository with a rate
+// cache. Rates rather than amounts are cached, keyed by currency pair, so
+// conversions of different amounts share one entry.
+//
+// Entries younger than ttl are served directly. Entries younger than
+// ttl+staleTTL are served as well while a background refresh replaces them.
+// Older entries are fetched synchronously.
+type CachingPriceRepository struct {
+	next      domain.PriceRepository
+	cachePath string
+	ttl       time.Duration
+	staleTTL  time.Duration
+	now       func() time.Time
+
+	// maxHistorical is the most historical rates kept in the cache file
+	maxHistorical int
+
+	mu         sync.Mutex
+	loaded     bool
+	entries    map[string]rateEntry
+	refreshing map[string]bool
+
+	refreshes     sync.WaitGroup
+	closeTimeout  time.Duration
+	closing       context.Context
+	stopRefreshes context.CancelFunc
+}
+
+// NewCachingPriceRepository wraps next with a rate cache persisted at
+// cachePath so separate processes share it. An empty cachePath keeps the
+// cache in memory only.
+func NewCachingPriceRepository(next domain.PriceRepository, cachePath string, ttl, staleTTL time.Duration) *CachingPriceRepository {
+	closing, stopRefreshes := context.WithCancel(context.Background())
+	return &CachingPriceRepository{
+		next:          next,
+		cachePath:     cachePath,
+		ttl:           ttl,
+		staleTTL:      staleTTL,
+		now:           time.Now,
+		maxHistorical: maxHistoricalRates,
+		entries:       make(map[string]rateEntry),
+		refreshing:    make(map[string]bool),
+		closeTimeout:  closeTimeout,
+		closing:       closing,
+		stopRefreshes: stopRefreshes,
+	}
+}
+
+// GetConversionPrice returns a conversion computed from the cached rate when
+// available, fetching it from the wrapped repository otherwise
+func (c *CachingPriceRepository) GetConversionPrice(
+	ctx context.Context,
+	request *domain.ConversionRequest,
+) (*domain.ConversionResult, error) {
+	results, err := c.GetConversionPrices(ctx, []*domain.ConversionRequest{request})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// GetConversionPrices serves every cached pair and fetches the remaining
+// ones from the wrapped repository in a single batch when it supports that
+func (c *CachingPriceRepository) GetConversionPrices(
+	ctx context.Context,
+	requests []*domain.ConversionRequest,
+) ([]*domain.ConversionResult, error) {
+	results := make([]*domain.ConversionResult, len(requests))
+	var misses []int
+	var stale []*domain.ConversionRequest
+
+	c.mu.Lock()
+	c.load()
+	now := c.now()
+	for i, request := range requests {
+		key := pairKey(request)
+		entry, ok := c.entries[key]
+		if !ok {
+			misses = append(misses, i)
+			continue
+		}
+
+		age := now.Sub(entry.FetchedAt)
+		switch {
+		case entry.Historical || age < c.ttl:
+			results[i] = entry.result(request, now)
+		case age < c.ttl+c.staleTTL:
+			results[i] = entry.result(request, now)
+			if !c.refreshing[key] {
+				c.refreshing[key] = true
+				stale = append(stale, request)
+			}
+		default:
+			misses = append(misses, i)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(stale) > 0 {
+		c.revalidate(ctx, stale)
+	}
+
+	if len(misses) == 0 {
+		return results, nil
+	}
+
+	missed := make([]*domain.ConversionRequest, len(misses))
+	for j, i := range misses {
+		missed[j] = requests[i]
+	}
+
+	fetched, err := c.fetch(ctx, missed)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range misses {
+		results[i] = fetched[j]
+	}
+	return results, nil
+}
+
+// Close waits up to closeTimeout for background refreshes to finish so their
+// rates are persisted, then cancels the remaining ones. Their stale entries
+// stay cached and are revalidated again by the next run.
+func (c *CachingPriceRepository) Close() error {
+	done := make(chan struct{})
+	go func() {
+		c.refreshes.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(c.closeTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		c.stopRefreshes()
+	}
+	return nil
+}
+
+// revalidate refreshes stale entries in the background
+func (c *CachingPriceRepository) revalidate(ctx context.Context, requests []*domain.ConversionRequest) {
+	c.refreshes.Add(1)
+	go func() {
+		defer c.refreshes.Done()
+
+		// The refresh outlives the request that triggered it, but not Close
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+		defer cancel()
+		stop := context.AfterFunc(c.closing, cancel)
+		defer stop()
+
+		// A failed refresh leaves the stale entry in place until it expires
+		_, _ = c.fetch(ctx, requests)
+
+		c.mu.Lock()
+		for _, request := range requests {
+			delete(c.refreshing, pairKey(request))
+		}
+		c.mu.Unlock()
+	}()
+}
+
+// fetch prices requests through the wrapped repository and caches the rates
+func (c *CachingPriceRepository) fetch(
+	ctx context.Context,
+	requests []*domain.ConversionRequest,
+) ([]*domain.ConversionResult, error) {
+	results, err := fetchAll(ctx, c.next, requests)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fetchedAt := c.now()
+	for i, request := range requests {
+		c.entries[pairKey(request)] = rateEntry{
+			From:        results[i].FromCurrency,
+			To:          results[i].ToCurrency,
+			Rate:        results[i].ExchangeRate,
+			LastUpdated: results[i].LastUpdated,
+			FetchedAt:   fetchedAt,
+			Source:      results[i].Source,
+			Historical:  request.IsHistorical(),
+		}
+	}
+
+	if c.cachePath != "" {
+		// A failure to persist only costs another API call later
+		_ = c.persist()
+	}
+	return results, nil
+}
+
+// fetchAll prices every request through repo, batching them when it supports that
+func fetchAll(
+	ctx context.Context,
+	repo domain.PriceRepository,
+	requests []*domain.ConversionRequest,
+) ([]*domain.ConversionResult, error) {
+	if multi, ok := repo.(domain.MultiPriceRepository); ok && len(requests) > 1 {
+		return multi.GetConversionPrices(ctx, requests)
+	}
+
+	results := make([]*domain.ConversionResult, 0, len(requests))
+	for _, request := range requests {
+		result, err := repo.GetConversionPrice(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// result rebuilds a conversion result for request from the cached rate
+func (e rateEntry) result(request *domain.ConversionRequest, now time.Time) *domain.ConversionResult {
+	from, to := *e.From, *e.To
+	result := domain.NewConversionResult(
+		request.Amount,
+		request.Amount.Mul(e.Rate),
+		e.Rate,
+		&from,
+		&to,
+		now,
+		e.LastUpdated,
+	)
+	result.AsOf = request.At
+	result.Source = e.Source
+	return result
+}
+
+// pairKey identifies the cache entry for a request
+func pairKey(request *domain.ConversionRequest) string {
+	key := currencyKey(request.FromCurrency) + "/" + currencyKey(request.ToCurrency)
+	if request.IsHistorical() {
+		key += "@" + request.At.UTC().Format(time.RFC3339)
+	}
+	return key
+}
+
+// currencyKey distinguishes pinned assets from ticker symbols
+func currencyKey(currency *domain.Currency) string {
+	switch {
+	case !currency.Pinned:
+		return currency.Symbol
+	case currency.CMCID != 0:
+		return "id:" + strconv.Itoa(currency.CMCID)
+	default:
+		return "slug:" + currency.Slug
+	}
+}
+
+// load reads the cache file once; callers hold c.mu
+func (c *CachingPriceRepository) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+
+	// A missing or corrupt cache file simply starts an empty cache
+	if entries, err := c.readCache(); err == nil {
+		c.merge(entries)
+	}
+}
+
+// persist merges the in-memory entries with those written by other processes
+// in the meantime and atomically replaces the cache file; callers hold c.mu
+func (c *CachingPriceRepository) persist() error {
+	if entries, err := c.readCache(); err == nil {
+		c.merge(entries)
+	}
+
+	// Drop live rates that are too old to be served again
+	now := c.now()
+	for key, entry := range c.entries {
+		if !entry.Historical && now.Sub(entry.FetchedAt) >= c.ttl+c.staleTTL {
+			delete(c.entries, key)
+		}
+	}
+	c.evictHistorical()
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(c.cachePath, data)
+}
+
+// evictHistorical drops the least recently fetched historical rates beyond
+// maxHistorical; callers hold c.mu
+func (c *CachingPriceRepository) evictHistorical() {
+	var historical []string
+	for key, entry := range c.entries {
+		if entry.Historical {
+			historical = append(historical, key)
+		}
+	}
+	if len(historical) <= c.maxHistorical {
+		return
+	}
+
+	sort.Slice(historical, func(i, j int) bool {
+		return c.entries[historical[i]].FetchedAt.Before(c.entries[historical[j]].FetchedAt)
+	})
+	for _, key := range historical[:len(historical)-c.maxHistorical] {
+		delete(c.entries, key)
+	}
+}
+
+// merge keeps the most recently fetched entry for every pair; callers hold c.mu
+func (c *CachingPriceRepository) merge(entries map[string]rateEntry) {
+	for key, entry := range entries {
+		if entry.From == nil || entry.To == nil {
+			continue
+		}
+		if current, ok := c.entries[key]; !ok || entry.FetchedAt.After(current.FetchedAt) {
+			c.entries[key] = entry
+		}
+	}
+}
+
+// readCache loads the entries from the cache file
+func (c *CachingPriceRepository) readCache() (map[string]rateEntry, error) {
+	if c.cachePath == "" {
+		return nil, os.ErrNotExist
+	}
+
+	data, err := os.ReadFile(c.cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries map[string]rateEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRepository prices every pair at a fixed rate and counts upstream calls
+type countingRepository struct {
+	mu    sync.Mutex
+	rate  domain.Amount
+	calls int
+	err   error
+}
+
+func (r *countingRepository) GetConversionPrice(
+	_ context.Context,
+	request *domain.ConversionRequest,
+) (*domain.ConversionResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	result := domain.NewConversionResult(
+		request.Amount, request.Amount.Mul(r.rate), r.rate,
+		request.FromCurrency, request.ToCurrency, time.Now(), time.Now(),
+	)
+	result.AsOf = request.At
+	return result, nil
+}
+
+func (r *countingRepository) setRate(rate string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rate = domain.MustParseAmount(rate)
+}
+
+func (r *countingRepository) callCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+// newConvertServer quotes every requested symbol at 1.5 and records the convert lists
+func newConvertServer(t *testing.T, calls *[]string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		convert := r.URL.Query().Get("convert")
+		*calls = append(*calls, convert)
+
+		quotes := make([]string, 0)
+		for _, symbol := range strings.Split(convert, ",") {
+			quotes = append(quotes, fmt.Sprintf(`%q: {"price": 1.5}`, symbol))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"status": {"error_code": 0}, "data": {
+			"symbol": "BTC", "id": 1, "name": "Bitcoin", "amount": 1,
+			"quote": {%s}
+		}}`, strings.Join(quotes, ", "))
+	}))
+}
+
+func TestCachingPriceRepository_SharesRateAcrossAmounts(t *testing.T) {
+	upstream := &countingRepository{rate: domain.MustParseAmount("67000.5")}
+	cache := NewCachingPriceRepository(upstream, "", time.Minute, time.Minute)
+
+	first, err := cache.GetConversionPrice(context.Background(), newRequest(t, "1", "BTC", "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, "67000.5", first.ConvertedAmount.String())
+
+	second, err := cache.GetConversionPrice(context.Background(), newRequest(t, "2.5", "BTC", "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, "167501.25", second.ConvertedAmount.String())
+	assert.Equal(t, "2.5", second.OriginalAmount.String())
+	assert.Equal(t, "67000.5", second.ExchangeRate.String())
+	assert.Equal(t, 1, upstream.callCount())
+
+	// A different pair is a separate entry
+	_, err = cache.GetConversionPrice(context.Background(), newRequest(t, "1", "BTC", "EUR"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, upstream.callCount())
+}
+
+func TestCachingPriceRepository_StaleWhileRevalidate(t *testing.T) {
+	upstream := &countingRepository{rate: domain.MustParseAmount("100")}
+	cache := NewCachingPriceRepository(upstream, "", time.Minute, 5*time.Minute)
+
+	start := time.Now()
+	cache.now = func() time.Time { return start }
+	_, err := cache.GetConversionPrice(context.Background(), newRequest(t, "1", "ETH", "USD"))
+	require.NoError(t, err)
+
+	// Within the stale window the old rate is served and refreshed in the background
+	upstream.setRate("110")
+	cache.now = func() time.Time { return start.Add(2 * time.Minute) }
+	stale, err := cache.GetConversionPrice(context.Background(), newRequest(t, "1", "ETH", "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, "100", stale.ConvertedAmount.String())
+
+	require.NoError(t, cache.Close())
+	assert.Equal(t, 2, upstream.callCount())
+
+	refreshed, err := cache.GetConversionPrice(context.Background(), newRequest(t, "1", "ETH", "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, "110", refreshed.ConvertedAmount.String())
+	assert.Equal(t, 2, upstream.callCount())
+
+	// Past the stale window the rate is fetched synchronously
+	upstream.setRate("120")
+	cache.now = func() time.Time { return start.Add(time.Hour) }
+	expired, err := cache.GetConversionPrice(context.Background(), newRequest(t, "1", "ETH", "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, "120", expired.ConvertedAmount.String())
+	assert.Equal(t, 3, upstream.callCount())
+}
+
+// blockingRepository answers only once its context is done
+type blockingRepository struct {
+	err chan error
+}
+
+func (r *blockingRepository) GetConversionPrice(
+	ctx context.Context,
+	_ *domain.ConversionRequest,
+) (*domain.ConversionResult, error) {
+	<-ctx.Done()
+	r.err <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func TestCachingPriceRepository_CloseCancelsSlowRefresh(t *testing.T) {
+	upstream := &countingRepository{rate: domain.MustParseAmount("100")}
+	cache := NewCachingPriceRepository(upstream, "", time.Minute, 5*time.Minute)
+	cache.closeTimeout = 10 * time.Millisecond
+
+	start := time.Now()
+	cache.now = func() time.Time { return start }
+	_, err := cache.GetConversionPrice(context.Background(), newRequest(t, "1", "ETH", "USD"))
+	require.NoError(t, err)
+
+	blocking := &blockingRepository{err: make(chan error, 1)}
+	cache.next = blocking
+	cache.now = func() time.Time { return start.Add(2 * time.Minute) }
+	stale, err := cache.GetConversionPrice(context.Background(), newRequest(t, "1", "ETH", "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, "100", stale.ConvertedAmount.String())
+
+	closed := time.Now()
+	require.NoError(t, cache.Close())
+	assert.Less(t, time.Since(closed), refreshTimeout)
+	assert.ErrorIs(t, <-blocking.err, context.Canceled)
+
+	// The stale rate is still cached for the next run
+	cache.refreshes.Wait()
+	entry, ok := cache.entries[pairKey(newRequest(t, "1", "ETH", "USD"))]
+	require.True(t, ok)
+	assert.Equal(t, "100", entry.Rate.String())
+}
+
+func TestCachingPriceRepository_FailedFetchIsNotCached(t *testing.T) {
+	upstream := &countingRepository{err: domain.ErrServerError}
+	cache := NewCachingPriceRepository(upstream, "", time.Minute, time.Minute)
+
+	_, err := cache.GetConversionPrice(context.Background(), newRequest(t, "1", "BTC", "USD"))
+	assert.ErrorIs(t, err, domain.ErrServerError)
+
+	_, err = cache.GetConversionPrice(context.Background(), newRequest(t, "1", "BTC", "USD"))
+	assert.ErrorIs(t, err, domain.ErrServerError)
+	assert.Equal(t, 2, upstream.callCount())
+}
+
+func TestCachingPriceRepository_KeysPinnedAndHistoricalSeparately(t *testing.T) {
+	upstream := &countingRepository{rate: domain.MustParseAmount("3000")}
+	cache := NewCachingPriceRepository(upstream, "", time.Minute, 0)
+
+	historical := newRequest(t, "1", "ETH", "USD")
+	historical.At = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	requests := []*domain.ConversionRequest{
+		newRequest(t, "1", "ETH", "USD"),
+		newRequest(t, "1", "id:1027", "USD"),
+		historical,
+	}
+	for _, request := range requests {
+		_, err := cache.GetConversionPrice(context.Background(), request)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, upstream.callCount())
+
+	// Historical rates never expire
+	cache.now = func() time.Time { return time.Now().Add(24 * time.Hour) }
+	result, err := cache.GetConversionPrice(context.Background(), historical)
+	require.NoError(t, err)
+	assert.Equal(t, historical.At, result.AsOf)
+	assert.Equal(t, 3, upstream.callCount())
+}
+
+func TestCachingPriceRepository_SharedThroughFile(t *testing.T) {
+	upstream := &countingRepository{rate: domain.MustParseAmount("0.92")}
+	cachePath := filepath.Join(t.TempDir(), "cache", "rates.json")
+
+	first := NewCachingPriceRepository(upstream, cachePath, time.Minute, time.Minute)
+	_, err := first.GetConversionPrice(context.Background(), newRequest(t, "10", "USD", "EUR"))
+	require.NoError(t, err)
+	assert.FileExists(t, cachePath)
+
+	// Another process picks up the persisted rate
+	second := NewCachingPriceRepository(upstream, cachePath, time.Minute, time.Minute)
+	result, err := second.GetConversionPrice(context.Background(), newRequest(t, "100", "USD", "EUR"))
+	require.NoError(t, err)
+	assert.Equal(t, "92.00", result.ConvertedAmount.String())
+	assert.Equal(t, "US Dollar", result.FromCurrency.Name)
+	assert.Equal(t, 1, upstream.callCount())
+}
+
+func TestCachingPriceRepository_EvictsOldestHistoricalRates(t *testing.T) {
+	upstream := &countingRepository{rate: domain.MustParseAmount("3000")}
+	cachePath := filepath.Join(t.TempDir(), "rates.json")
+	cache := NewCachingPriceRepository(upstream, cachePath, time.Minute, 0)
+	cache.maxHistorical = 2
+
+	fetchedAt := time.Now()
+	cache.now = func() time.Time { return fetchedAt }
+
+	var requests []*domain.ConversionRequest
+	for day := 1; day <= 3; day++ {
+		request := newRequest(t, "1", "ETH", "USD")
+		request.At = time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC)
+		requests = append(requests, request)
+
+		_, err := cache.GetConversionPrice(context.Background(), request)
+		require.NoError(t, err)
+		fetchedAt = fetchedAt.Add(time.Second)
+	}
+
+	// The least recently fetched point is gone from the file
+	reloaded := NewCachingPriceRepository(upstream, cachePath, time.Minute, 0)
+	entries, err := reloaded.readCache()
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.NotContains(t, entries, pairKey(requests[0]))
+	assert.Contains(t, entries, pairKey(requests[2]))
+}
+
+func TestCachingPriceRepository_BatchesMisses(t *testing.T) {
+	var calls []string
+	server := newConvertServer(t, &calls)
+	defer server.Close()
+
+	cmc := NewCoinMarketCapRepository(server.Client(), "test-key", server.URL)
+	cache := NewCachingPriceRepository(cmc, "", time.Minute, time.Minute)
+
+	_, err := cache.GetConversionPrice(context.Background(), newRequest(t, "1", "BTC", "USD"))
+	require.NoError(t, err)
+
+	results, err := cache.GetConversionPrices(context.Background(), []*domain.ConversionRequest{
+		newRequest(t, "1", "BTC", "USD"),
+		newRequest(t, "1", "BTC", "EUR"),
+		newRequest(t, "1", "BTC", "GBP"),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, []string{"USD", "EUR,GBP"}, calls)
+}
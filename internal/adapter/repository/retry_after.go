@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unixThreshold separates reset headers given as Unix timestamps from those
+// given in seconds from now
+const unixThreshold = 1_000_000_000
+
+// suggestedDelay reads how long the server asks clients to wait before the
+// next request. Retry-After is preferred; the rate limit reset headers sent
+// by CoinMarketCap and most other APIs are used otherwise. Zero means no
+// suggestion.
+func suggestedDelay(header http.Header, now time.Time) time.Duration {
+	if value := strings.TrimSpace(header.Get("Retry-After")); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return positive(time.Duration(seconds) * time.Second)
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return positive(at.Sub(now))
+		}
+	}
+
+	for _, name := range []string{"X-RateLimit-Reset", "RateLimit-Reset"} {
+		value := strings.TrimSpace(header.Get(name))
+		if value == "" {
+			continue
+		}
+		if reset, err := time.Parse(time.RFC3339, value); err == nil {
+			return positive(reset.Sub(now))
+		}
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		if seconds >= unixThreshold {
+			return positive(time.Unix(seconds, 0).Sub(now))
+		}
+		return positive(time.Duration(seconds) * time.Second)
+	}
+
+	return 0
+}
+
+// positive clamps negative delays, e.g. reset times already in the past, to zero
+func positive(d time.Duration) time.Duration {
+	return max(d, 0)
+}
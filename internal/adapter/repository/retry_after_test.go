@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestedDelay(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   time.Duration
+	}{
+		{"no headers", nil, 0},
+		{"retry-after seconds", map[string]string{"Retry-After": "42"}, 42 * time.Second},
+		{"retry-after date", map[string]string{"Retry-After": "Sun, 01 Mar 2026 12:00:30 GMT"}, 30 * time.Second},
+		{"retry-after in the past", map[string]string{"Retry-After": "Sun, 01 Mar 2026 11:59:00 GMT"}, 0},
+		{"retry-after preferred", map[string]string{"Retry-After": "5", "X-RateLimit-Reset": "50"}, 5 * time.Second},
+		{"reset seconds", map[string]string{"X-RateLimit-Reset": "17"}, 17 * time.Second},
+		{"reset unix time", map[string]string{"X-RateLimit-Reset": "1772366445"}, 45 * time.Second},
+		{"reset RFC3339", map[string]string{"X-RateLimit-Reset": "2026-03-01T12:01:00Z"}, time.Minute},
+		{"draft reset header", map[string]string{"RateLimit-Reset": "9"}, 9 * time.Second},
+		{"malformed", map[string]string{"Retry-After": "soon"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for name, value := range tt.header {
+				header.Set(name, value)
+			}
+			assert.Equal(t, tt.want, suggestedDelay(header, now))
+		})
+	}
+}
@@ -0,0 +1,464 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/kerimovkk/currency-conversion-utility/pkg/retry"
+)
+
+const (
+	// mapPageSize is the largest page /v1/cryptocurrency/map returns per credit
+	mapPageSize = 5000
+
+	// mapRetryInterval is how long a failed map download is remembered before
+	// the next lookup tries again
+	mapRetryInterval = time.Minute
+
+	// maxSuggestions limits the number of "did you mean" candidates
+	maxSuggestions = 3
+
+	// maxSuggestionDistance is the largest edit distance offered as a suggestion
+	maxSuggestionDistance = 2
+)
+
+// CryptoMapEntry represents an entry returned by /v1/cryptocurrency/map
+type CryptoMapEntry struct {
+	ID       int    `json:"id"`
+	Rank     int    `json:"rank"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Slug     string `json:"slug"`
+	IsActive int    `json:"is_active"`
+}
+
+// FiatMapEntry represents an entry returned by /v1/fiat/map
+type FiatMapEntry struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Sign   string `json:"sign"`
+	Symbol string `json:"symbol"`
+}
+
+// symbolMaps is the on-disk representation of the downloaded maps
+type symbolMaps struct {
+	FetchedAt time.Time        `json:"fetched_at"`
+	Crypto    []CryptoMapEntry `json:"crypto"`
+	Fiat      []FiatMapEntry   `json:"fiat"`
+}
+
+// SymbolResolver implements domain.CurrencyResolver using the CoinMarketCap
+// crypto and fiat maps. Symbols known to the built-in registry are resolved
+// locally; anything else is looked up in the maps, which are cached on disk.
+type SymbolResolver struct {
+	cmc       *CoinMarketCapRepository
+	registry  *domain.Registry
+	cachePath string
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu           sync.Mutex
+	loaded       bool
+	loadErr      error
+	retryAt      time.Time
+	cryptoBySym  map[string][]CryptoMapEntry
+	cryptoByID   map[int]CryptoMapEntry
+	cryptoBySlug map[string]CryptoMapEntry
+	fiatBySym    map[string]FiatMapEntry
+	fiatByID     map[int]FiatMapEntry
+}
+
+// NewSymbolResolver creates a resolver that downloads maps through cmc and
+// caches them at cachePath for ttl. An empty cachePath disables persistence.
+func NewSymbolResolver(cmc *CoinMarketCapRepository, cachePath string, ttl time.Duration) *SymbolResolver {
+	return &SymbolResolver{
+		cmc:       cmc,
+		registry:  domain.DefaultRegistry(),
+		cachePath: cachePath,
+		ttl:       ttl,
+		now:       time.Now,
+	}
+}
+
+// Resolve validates and normalizes a symbol or explicit identifier
+func (r *SymbolResolver) Resolve(ctx context.Context, symbol string) (*domain.Currency, error) {
+	input := strings.TrimSpace(symbol)
+
+	currency, err := domain.NewCurrency(input)
+	if err != nil && !isPlainSymbol(input) {
+		return nil, err
+	}
+	if err == nil && currency.Symbol != "" {
+		return currency, nil
+	}
+
+	loadErr := r.load(ctx)
+
+	// Explicit ID or slug that the registry does not know
+	if currency != nil {
+		if c, ok := r.lookupPinned(currency); ok {
+			return c, nil
+		}
+		return currency, nil
+	}
+
+	upper := strings.ToUpper(input)
+	if c, ok := r.bySymbol(upper); ok {
+		return c, nil
+	}
+
+	msg := fmt.Sprintf("unknown currency %s", upper)
+	if suggestions := r.suggest(upper); len(suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, ", "))
+	}
+	if loadErr != nil {
+		msg += fmt.Sprintf(" [symbol map unavailable: %v]", loadErr)
+	}
+	return nil, fmt.Errorf("%w: %s", domain.ErrInvalidCurrency, msg)
+}
+
+// isPlainSymbol reports whether input looks like a ticker rather than an explicit identifier
+func isPlainSymbol(input string) bool {
+	if strings.Contains(input, ":") {
+		return false
+	}
+	return len(input) >= 2 && len(input) <= 10
+}
+
+// lookupPinned fills in an explicit ID or slug from the maps
+func (r *SymbolResolver) lookupPinned(currency *domain.Currency) (*domain.Currency, bool) {
+	if currency.CMCID != 0 {
+		return r.byID(currency.CMCID)
+	}
+	return r.bySlug(currency.Slug)
+}
+
+// bySymbol looks a ticker up in the fiat map, then the crypto map (best rank wins)
+func (r *SymbolResolver) bySymbol(symbol string) (*domain.Currency, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.fiatBySym[symbol]; ok {
+		return r.fiatCurrency(f), true
+	}
+	if entries := r.cryptoBySym[symbol]; len(entries) > 0 {
+		return r.cryptoCurrency(entries[0]), true
+	}
+	return nil, false
+}
+
+// byID looks up a CoinMarketCap ID in both maps
+func (r *SymbolResolver) byID(id int) (*domain.Currency, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if e, ok := r.cryptoByID[id]; ok {
+		return r.cryptoCurrency(e), true
+	}
+	if f, ok := r.fiatByID[id]; ok {
+		return r.fiatCurrency(f), true
+	}
+	return nil, false
+}
+
+// bySlug looks up a CoinMarketCap slug in the crypto map
+func (r *SymbolResolver) bySlug(slug string) (*domain.Currency, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if e, ok := r.cryptoBySlug[slug]; ok {
+		return r.cryptoCurrency(e), true
+	}
+	return nil, false
+}
+
+// cryptoCurrency converts a map entry into a Currency pinned to its ID so that
+// the price lookup uses exactly the asset that was resolved
+func (r *SymbolResolver) cryptoCurrency(e CryptoMapEntry) *domain.Currency {
+	c, ok := r.registry.LookupCMCID(e.ID)
+	if !ok {
+		c = &domain.Currency{
+			Symbol:   e.Symbol,
+			Name:     e.Name,
+			Kind:     domain.CurrencyKindCrypto,
+			Decimals: domain.DefaultCryptoDecimals,
+			CMCID:    e.ID,
+			Slug:     e.Slug,
+		}
+	}
+	c.Pinned = true
+	return c
+}
+
+// fiatCurrency converts a fiat map entry into a Currency, keeping ISO metadata when known
+func (r *SymbolResolver) fiatCurrency(f FiatMapEntry) *domain.Currency {
+	c, ok := r.registry.Lookup(f.Symbol)
+	if !ok {
+		c = &domain.Currency{
+			Symbol:   f.Symbol,
+			Name:     f.Name,
+			Kind:     domain.CurrencyKindFiat,
+			Decimals: 2,
+		}
+	}
+	c.CMCID = f.ID
+	return c
+}
+
+// suggest returns the closest known symbols to an unknown one
+func (r *SymbolResolver) suggest(symbol string) []string {
+	type candidate struct {
+		symbol   string
+		distance int
+		rank     int
+	}
+
+	seen := make(map[string]bool)
+	var candidates []candidate
+	add := func(s string, rank int) {
+		if seen[s] {
+			return
+		}
+		seen[s] = true
+		if d := levenshtein(symbol, s); d <= maxSuggestionDistance {
+			candidates = append(candidates, candidate{symbol: s, distance: d, rank: rank})
+		}
+	}
+
+	// Registry symbols rank ahead of everything in the maps
+	for _, c := range r.registry.All() {
+		add(c.Symbol, 0)
+	}
+
+	r.mu.Lock()
+	for s := range r.fiatBySym {
+		add(s, 0)
+	}
+	for s, entries := range r.cryptoBySym {
+		add(s, rankOrder(entries[0].Rank))
+	}
+	r.mu.Unlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		if candidates[i].rank != candidates[j].rank {
+			return candidates[i].rank < candidates[j].rank
+		}
+		return candidates[i].symbol < candidates[j].symbol
+	})
+
+	suggestions := make([]string, 0, maxSuggestions)
+	for _, c := range candidates {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, c.symbol)
+	}
+	return suggestions
+}
+
+// rankOrder sorts unranked assets (rank 0) after ranked ones
+func rankOrder(rank int) int {
+	if rank <= 0 {
+		return int(^uint(0) >> 1)
+	}
+	return rank
+}
+
+// load makes the maps available, reading the cache file when it is fresh and
+// downloading them otherwise. A stale cache is used if the download fails,
+// and the download is tried again once mapRetryInterval has passed.
+func (r *SymbolResolver) load(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.loaded {
+		return nil
+	}
+	if r.loadErr != nil && r.now().Before(r.retryAt) {
+		return r.loadErr
+	}
+
+	cached, cacheErr := r.readCache()
+	if cacheErr == nil && r.now().Sub(cached.FetchedAt) < r.ttl {
+		r.index(cached)
+		r.loaded = true
+		return nil
+	}
+
+	fresh, err := r.download(ctx)
+	if err != nil {
+		if cacheErr == nil {
+			r.index(cached)
+		} else {
+			r.index(&symbolMaps{})
+		}
+		// A cancelled caller says nothing about the API, so the next call retries at once
+		r.loadErr = err
+		r.retryAt = r.now()
+		if ctx.Err() == nil {
+			r.retryAt = r.retryAt.Add(mapRetryInterval)
+		}
+		return err
+	}
+
+	r.index(fresh)
+	r.loaded = true
+	r.loadErr = nil
+	if r.cachePath != "" {
+		// A failure to persist only costs a re-download next time
+		_ = r.writeCache(fresh)
+	}
+	return nil
+}
+
+// download fetches both maps from CoinMarketCap
+func (r *SymbolResolver) download(ctx context.Context) (*symbolMaps, error) {
+	maps := &symbolMaps{FetchedAt: r.now()}
+
+	for start := 1; ; start += mapPageSize {
+		params := url.Values{}
+		params.Add("start", strconv.Itoa(start))
+		params.Add("limit", strconv.Itoa(mapPageSize))
+
+		var page []CryptoMapEntry
+		if err := r.fetch(ctx, "/v1/cryptocurrency/map", params, &page); err != nil {
+			return nil, err
+		}
+		maps.Crypto = append(maps.Crypto, page...)
+		if len(page) < mapPageSize {
+			break
+		}
+	}
+
+	params := url.Values{}
+	params.Add("limit", strconv.Itoa(mapPageSize))
+	if err := r.fetch(ctx, "/v1/fiat/map", params, &maps.Fiat); err != nil {
+		return nil, err
+	}
+
+	return maps, nil
+}
+
+// fetch performs a map request with the repository's retry strategy
+func (r *SymbolResolver) fetch(ctx context.Context, path string, params url.Values, dst interface{}) error {
+	data, err := retry.DoValue(ctx, r.cmc.retry, r.cmc.shouldRetry, func(ctx context.Context) (json.RawMessage, error) {
+		return r.cmc.get(ctx, path, params)
+	})
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("%w: failed to parse %s", domain.ErrInvalidResponse, path)
+	}
+	return nil
+}
+
+// index builds the lookup tables from downloaded maps; callers hold r.mu
+func (r *SymbolResolver) index(maps *symbolMaps) {
+	r.cryptoBySym = make(map[string][]CryptoMapEntry)
+	r.cryptoByID = make(map[int]CryptoMapEntry, len(maps.Crypto))
+	r.cryptoBySlug = make(map[string]CryptoMapEntry, len(maps.Crypto))
+	r.fiatBySym = make(map[string]FiatMapEntry, len(maps.Fiat))
+	r.fiatByID = make(map[int]FiatMapEntry, len(maps.Fiat))
+
+	for _, e := range maps.Crypto {
+		sym := strings.ToUpper(e.Symbol)
+		r.cryptoBySym[sym] = append(r.cryptoBySym[sym], e)
+		r.cryptoByID[e.ID] = e
+		r.cryptoBySlug[e.Slug] = e
+	}
+	for sym := range r.cryptoBySym {
+		entries := r.cryptoBySym[sym]
+		sort.SliceStable(entries, func(i, j int) bool {
+			return rankOrder(entries[i].Rank) < rankOrder(entries[j].Rank)
+		})
+	}
+	for _, f := range maps.Fiat {
+		r.fiatBySym[strings.ToUpper(f.Symbol)] = f
+		r.fiatByID[f.ID] = f
+	}
+}
+
+// readCache loads the maps from the cache file
+func (r *SymbolResolver) readCache() (*symbolMaps, error) {
+	if r.cachePath == "" {
+		return nil, os.ErrNotExist
+	}
+
+	data, err := os.ReadFile(r.cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var maps symbolMaps
+	if err := json.Unmarshal(data, &maps); err != nil {
+		return nil, err
+	}
+	return &maps, nil
+}
+
+// writeCache atomically replaces the cache file
+func (r *SymbolResolver) writeCache(maps *symbolMaps) error {
+	data, err := json.Marshal(maps)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(r.cachePath, data)
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
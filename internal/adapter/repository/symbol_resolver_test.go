@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/kerimovkk/currency-conversion-utility/pkg/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMapServer serves canned crypto and fiat maps and counts requests
+func newMapServer(t *testing.T, calls *int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/cryptocurrency/map":
+			_, _ = w.Write([]byte(`{"status": {"error_code": 0}, "data": [
+				{"id": 5000, "rank": 900, "name": "Gala Clone", "symbol": "GALA", "slug": "gala-clone", "is_active": 1},
+				{"id": 7080, "rank": 80, "name": "Gala", "symbol": "GALA", "slug": "gala", "is_active": 1},
+				{"id": 1027, "rank": 2, "name": "Ethereum", "symbol": "ETH", "slug": "ethereum", "is_active": 1}
+			]}`))
+		case "/v1/fiat/map":
+			_, _ = w.Write([]byte(`{"status": {"error_code": 0}, "data": [
+				{"id": 2781, "name": "United States Dollar", "sign": "$", "symbol": "USD"}
+			]}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// newTestResolver creates a resolver with a fast retry strategy
+func newTestResolver(server *httptest.Server, cachePath string, ttl time.Duration) *SymbolResolver {
+	cmc := NewCoinMarketCapRepository(server.Client(), "test-key", server.URL)
+	cmc.retry = &retry.Strategy{MaxAttempts: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+	return NewSymbolResolver(cmc, cachePath, ttl)
+}
+
+func TestSymbolResolver_RegistrySymbolsNeedNoDownload(t *testing.T) {
+	var calls int32
+	server := newMapServer(t, &calls)
+	defer server.Close()
+
+	resolver := newTestResolver(server, "", time.Hour)
+	currency, err := resolver.Resolve(context.Background(), " btc ")
+
+	require.NoError(t, err)
+	assert.Equal(t, "BTC", currency.Symbol)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestSymbolResolver_ResolvesFromMapByRank(t *testing.T) {
+	var calls int32
+	server := newMapServer(t, &calls)
+	defer server.Close()
+
+	resolver := newTestResolver(server, "", time.Hour)
+	currency, err := resolver.Resolve(context.Background(), "gala")
+
+	require.NoError(t, err)
+	assert.Equal(t, "GALA", currency.Symbol)
+	assert.Equal(t, 7080, currency.CMCID)
+	assert.Equal(t, "Gala", currency.Name)
+	assert.True(t, currency.Pinned)
+	assert.Equal(t, int32(domain.DefaultCryptoDecimals), currency.Precision())
+}
+
+func TestSymbolResolver_ResolvesUnknownExplicitIdentifiers(t *testing.T) {
+	var calls int32
+	server := newMapServer(t, &calls)
+	defer server.Close()
+
+	resolver := newTestResolver(server, "", time.Hour)
+
+	byID, err := resolver.Resolve(context.Background(), "id:5000")
+	require.NoError(t, err)
+	assert.Equal(t, "GALA", byID.Symbol)
+	assert.Equal(t, "Gala Clone", byID.Name)
+
+	bySlug, err := resolver.Resolve(context.Background(), "slug:gala")
+	require.NoError(t, err)
+	assert.Equal(t, 7080, bySlug.CMCID)
+}
+
+func TestSymbolResolver_SuggestsNearMisses(t *testing.T) {
+	var calls int32
+	server := newMapServer(t, &calls)
+	defer server.Close()
+
+	resolver := newTestResolver(server, "", time.Hour)
+	_, err := resolver.Resolve(context.Background(), "GALAA")
+
+	assert.ErrorIs(t, err, domain.ErrInvalidCurrency)
+	assert.Contains(t, err.Error(), "did you mean GALA")
+}
+
+func TestSymbolResolver_MalformedInputSkipsMaps(t *testing.T) {
+	var calls int32
+	server := newMapServer(t, &calls)
+	defer server.Close()
+
+	resolver := newTestResolver(server, "", time.Hour)
+	_, err := resolver.Resolve(context.Background(), "id:abc")
+
+	assert.ErrorIs(t, err, domain.ErrInvalidCurrency)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestSymbolResolver_PersistsMapsWithTTL(t *testing.T) {
+	var calls int32
+	server := newMapServer(t, &calls)
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "nested", "symbols.json")
+
+	first := newTestResolver(server, cachePath, time.Hour)
+	_, err := first.Resolve(context.Background(), "GALA")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.FileExists(t, cachePath)
+
+	// A second process reads the fresh cache instead of downloading
+	second := newTestResolver(server, cachePath, time.Hour)
+	_, err = second.Resolve(context.Background(), "GALA")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// Once the TTL has passed the maps are downloaded again
+	expired := newTestResolver(server, cachePath, time.Hour)
+	expired.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = expired.Resolve(context.Background(), "GALA")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestSymbolResolver_UsesStaleCacheWhenDownloadFails(t *testing.T) {
+	var calls int32
+	server := newMapServer(t, &calls)
+	cachePath := filepath.Join(t.TempDir(), "symbols.json")
+
+	_, err := newTestResolver(server, cachePath, time.Hour).Resolve(context.Background(), "GALA")
+	require.NoError(t, err)
+	server.Close()
+
+	// Resolve after the TTL has passed with the API unreachable
+	offline := newTestResolver(server, cachePath, time.Hour)
+	offline.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	currency, err := offline.Resolve(context.Background(), "GALA")
+
+	require.NoError(t, err)
+	assert.Equal(t, 7080, currency.CMCID)
+}
+
+func TestSymbolResolver_RetriesFailedDownload(t *testing.T) {
+	var calls, failing int32 = 0, 1
+	maps := newMapServer(t, &calls)
+	defer maps.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		maps.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	resolver := newTestResolver(server, "", time.Hour)
+	resolver.now = func() time.Time { return now }
+
+	_, err := resolver.Resolve(context.Background(), "GALA")
+	assert.ErrorContains(t, err, "symbol map unavailable")
+
+	// The failure is remembered for a while instead of downloading on every lookup
+	atomic.StoreInt32(&failing, 0)
+	_, err = resolver.Resolve(context.Background(), "GALA")
+	assert.Error(t, err)
+	assert.Zero(t, atomic.LoadInt32(&calls))
+
+	// After that the download is tried again
+	now = now.Add(mapRetryInterval)
+	currency, err := resolver.Resolve(context.Background(), "GALA")
+	require.NoError(t, err)
+	assert.Equal(t, 7080, currency.CMCID)
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("BTC", "BTC"))
+	assert.Equal(t, 1, levenshtein("BTC", "BTCC"))
+	assert.Equal(t, 1, levenshtein("ETG", "ETH"))
+	assert.Equal(t, 2, levenshtein("USD", "UDS"))
+	assert.Equal(t, 3, levenshtein("", "EUR"))
+}
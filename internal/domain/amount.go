@@ -0,0 +1,349 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DivisionPrecision is the number of significant digits kept when dividing
+// two amounts whose quotient is not a terminating decimal (34 digits, the
+// precision of IEEE 754 decimal128)
+const DivisionPrecision = 34
+
+// maxExponent bounds the exponent accepted by ParseAmount so that inputs such
+// as "1e999999999" cannot allocate unbounded memory
+const maxExponent = 1000
+
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+)
+
+// Amount is an arbitrary-precision decimal number used for all monetary values.
+// Its value is coef × 10^-scale. Amounts are immutable; the zero value is 0.
+type Amount struct {
+	coef  *big.Int
+	scale int32
+}
+
+// NewAmount creates an Amount equal to unscaled × 10^-scale
+func NewAmount(unscaled *big.Int, scale int32) Amount {
+	if scale < 0 {
+		return Amount{coef: new(big.Int).Mul(unscaled, pow10(int(-scale)))}
+	}
+	return Amount{coef: new(big.Int).Set(unscaled), scale: scale}
+}
+
+// NewAmountFromInt creates an Amount holding an integer value
+func NewAmountFromInt(v int64) Amount {
+	return Amount{coef: big.NewInt(v)}
+}
+
+// ParseAmount parses a decimal string such as "123.45", "-0.001" or "1.5e-3"
+// without going through binary floating point
+func ParseAmount(s string) (Amount, error) {
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return Amount{}, fmt.Errorf("%w: %q", ErrMalformedAmount, s)
+	}
+
+	negative := false
+	switch str[0] {
+	case '+':
+		str = str[1:]
+	case '-':
+		negative = true
+		str = str[1:]
+	}
+
+	mantissa, exponent, hasExponent := str, "", false
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		mantissa, exponent, hasExponent = str[:i], str[i+1:], true
+	}
+
+	intPart, fracPart := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		intPart, fracPart = mantissa[:i], mantissa[i+1:]
+	}
+
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Amount{}, fmt.Errorf("%w: %q", ErrMalformedAmount, s)
+	}
+
+	exp := 0
+	if hasExponent {
+		e, ok := parseExponent(exponent)
+		if !ok {
+			return Amount{}, fmt.Errorf("%w: %q", ErrMalformedAmount, s)
+		}
+		exp = e
+	}
+
+	coef, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("%w: %q", ErrMalformedAmount, s)
+	}
+	if negative {
+		coef.Neg(coef)
+	}
+
+	return NewAmount(coef, int32(len(fracPart)-exp)), nil
+}
+
+// MustParseAmount is like ParseAmount but panics if s cannot be parsed.
+// It is intended for constants and tests.
+func MustParseAmount(s string) Amount {
+	a, err := ParseAmount(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// isDigits reports whether s consists solely of ASCII digits (empty is allowed)
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseExponent parses an optionally signed decimal exponent within ±maxExponent
+func parseExponent(s string) (int, bool) {
+	negative := false
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "" || !isDigits(s) {
+		return 0, false
+	}
+
+	exp := 0
+	for i := 0; i < len(s); i++ {
+		exp = exp*10 + int(s[i]-'0')
+		if exp > maxExponent {
+			return 0, false
+		}
+	}
+
+	if negative {
+		exp = -exp
+	}
+	return exp, true
+}
+
+// pow10 returns 10^n as a new big.Int
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// digitCount returns the number of decimal digits in |v| (0 has one digit)
+func digitCount(v *big.Int) int {
+	if v.Sign() == 0 {
+		return 1
+	}
+	return len(new(big.Int).Abs(v).String())
+}
+
+// unscaled returns the coefficient, treating the zero value as 0
+func (a Amount) unscaled() *big.Int {
+	if a.coef == nil {
+		return new(big.Int)
+	}
+	return a.coef
+}
+
+// rescale returns the coefficient of a expressed at a larger scale
+func (a Amount) rescale(scale int32) *big.Int {
+	return new(big.Int).Mul(a.unscaled(), pow10(int(scale-a.scale)))
+}
+
+// Scale returns the number of digits after the decimal point
+func (a Amount) Scale() int32 {
+	return a.scale
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of a
+func (a Amount) Sign() int {
+	return a.unscaled().Sign()
+}
+
+// IsZero reports whether a equals zero
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+// Cmp compares a and b and returns -1, 0 or +1
+func (a Amount) Cmp(b Amount) int {
+	scale := max(a.scale, b.scale)
+	return a.rescale(scale).Cmp(b.rescale(scale))
+}
+
+// Equal reports whether a and b represent the same value, regardless of scale
+func (a Amount) Equal(b Amount) bool {
+	return a.Cmp(b) == 0
+}
+
+// Neg returns -a
+func (a Amount) Neg() Amount {
+	return Amount{coef: new(big.Int).Neg(a.unscaled()), scale: a.scale}
+}
+
+// Abs returns |a|
+func (a Amount) Abs() Amount {
+	return Amount{coef: new(big.Int).Abs(a.unscaled()), scale: a.scale}
+}
+
+// Add returns a + b
+func (a Amount) Add(b Amount) Amount {
+	scale := max(a.scale, b.scale)
+	return Amount{coef: new(big.Int).Add(a.rescale(scale), b.rescale(scale)), scale: scale}
+}
+
+// Sub returns a - b
+func (a Amount) Sub(b Amount) Amount {
+	return a.Add(b.Neg())
+}
+
+// Mul returns a × b exactly
+func (a Amount) Mul(b Amount) Amount {
+	return Amount{coef: new(big.Int).Mul(a.unscaled(), b.unscaled()), scale: a.scale + b.scale}
+}
+
+// Div returns a ÷ b. Terminating quotients are exact; otherwise the result is
+// rounded half-even to DivisionPrecision significant digits.
+func (a Amount) Div(b Amount) (Amount, error) {
+	if b.IsZero() {
+		return Amount{}, ErrDivisionByZero
+	}
+	if a.IsZero() {
+		return Amount{}, nil
+	}
+
+	// Pick a result scale that leaves DivisionPrecision significant digits.
+	// The quotient lies in [10^(m-1), 10^(m+1)); comparing the normalised
+	// coefficients tells which of the two decades it falls into.
+	da, db := digitCount(a.unscaled()), digitCount(b.unscaled())
+	magnitude := da - int(a.scale) - db + int(b.scale)
+	absA := new(big.Int).Abs(a.unscaled())
+	absB := new(big.Int).Abs(b.unscaled())
+	if absA.Mul(absA, pow10(db)).Cmp(absB.Mul(absB, pow10(da))) >= 0 {
+		magnitude++
+	}
+	scale := max(DivisionPrecision-magnitude, 0)
+
+	num := new(big.Int).Set(a.unscaled())
+	den := new(big.Int).Set(b.unscaled())
+	if shift := scale - int(a.scale) + int(b.scale); shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+
+	q := quoRound(num, den, RoundHalfEven)
+	return Amount{coef: q, scale: int32(scale)}.Normalize(), nil
+}
+
+// quoRound returns num ÷ den rounded to an integer using the given mode
+func quoRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	negative := num.Sign()*den.Sign() < 0
+	if mode.roundAway(q, r, den, negative) {
+		if negative {
+			q.Sub(q, bigOne)
+		} else {
+			q.Add(q, bigOne)
+		}
+	}
+	return q
+}
+
+// Round returns a rounded to the given number of decimal places using mode.
+// Amounts that already have no more than places decimals are returned unchanged.
+func (a Amount) Round(places int32, mode RoundingMode) Amount {
+	places = max(places, 0)
+	if places >= a.scale {
+		return a
+	}
+
+	q := quoRound(a.unscaled(), pow10(int(a.scale-places)), mode)
+	return Amount{coef: q, scale: places}
+}
+
+// StringFixed returns a rounded half-even to exactly places decimal places,
+// padding with zeros
+func (a Amount) StringFixed(places int32) string {
+	rounded := a.Round(places, RoundHalfEven)
+	places = max(places, 0)
+	return Amount{coef: rounded.rescale(places), scale: places}.String()
+}
+
+// Normalize returns a with trailing fractional zeros removed
+func (a Amount) Normalize() Amount {
+	coef := new(big.Int).Set(a.unscaled())
+	scale := a.scale
+	if coef.Sign() == 0 {
+		return Amount{coef: coef}
+	}
+
+	r := new(big.Int)
+	for scale > 0 {
+		q, m := new(big.Int).QuoRem(coef, bigTen, r)
+		if m.Sign() != 0 {
+			break
+		}
+		coef = q
+		scale--
+	}
+	return Amount{coef: coef, scale: scale}
+}
+
+// String returns the plain decimal representation of a, e.g. "-0.0015"
+func (a Amount) String() string {
+	coef := a.unscaled()
+	digits := new(big.Int).Abs(coef).String()
+
+	var b strings.Builder
+	if coef.Sign() < 0 {
+		b.WriteByte('-')
+	}
+
+	if a.scale == 0 {
+		b.WriteString(digits)
+		return b.String()
+	}
+
+	scale := int(a.scale)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	b.WriteString(digits[:len(digits)-scale])
+	b.WriteByte('.')
+	b.WriteString(digits[len(digits)-scale:])
+	return b.String()
+}
+
+// MarshalJSON encodes a as a JSON string so that no precision is lost
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + a.String() + `"`), nil
+}
+
+// UnmarshalJSON decodes a JSON number or string into a
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, `"`), `"`)
+
+	parsed, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
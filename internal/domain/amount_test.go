@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "integer", input: "100", want: "100"},
+		{name: "decimal", input: "123.45", want: "123.45"},
+		{name: "leading dot", input: ".5", want: "0.5"},
+		{name: "trailing dot", input: "5.", want: "5"},
+		{name: "negative", input: "-0.001", want: "-0.001"},
+		{name: "explicit plus sign", input: "+42", want: "42"},
+		{name: "keeps trailing zeros", input: "1.500", want: "1.500"},
+		{name: "negative exponent", input: "1.5e-3", want: "0.0015"},
+		{name: "positive exponent", input: "2E3", want: "2000"},
+		{name: "one wei", input: "0.000000000000000001", want: "0.000000000000000001"},
+		{name: "surrounding spaces", input: " 7 ", want: "7"},
+		{name: "empty", input: "", wantErr: true},
+		{name: "only dot", input: ".", wantErr: true},
+		{name: "letters", input: "abc", wantErr: true},
+		{name: "two dots", input: "1.2.3", wantErr: true},
+		{name: "missing exponent digits", input: "1e", wantErr: true},
+		{name: "exponent too large", input: "1e100000", wantErr: true},
+		{name: "hex", input: "0x10", wantErr: true},
+		{name: "infinity", input: "Inf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAmount(tt.input)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrMalformedAmount)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+}
+
+func TestAmount_ZeroValue(t *testing.T) {
+	var zero Amount
+
+	assert.True(t, zero.IsZero())
+	assert.Equal(t, "0", zero.String())
+	assert.Equal(t, "5", zero.Add(NewAmountFromInt(5)).String())
+}
+
+func TestAmount_Arithmetic(t *testing.T) {
+	a := MustParseAmount("1.25")
+	b := MustParseAmount("0.005")
+
+	assert.Equal(t, "1.255", a.Add(b).String())
+	assert.Equal(t, "1.245", a.Sub(b).String())
+	assert.Equal(t, "0.00625", a.Mul(b).String())
+	assert.Equal(t, "-1.25", a.Neg().String())
+	assert.Equal(t, "1.25", a.Neg().Abs().String())
+}
+
+func TestAmount_Cmp(t *testing.T) {
+	assert.Equal(t, 0, MustParseAmount("1.50").Cmp(MustParseAmount("1.5")))
+	assert.Equal(t, -1, MustParseAmount("0.1").Cmp(MustParseAmount("0.11")))
+	assert.Equal(t, 1, MustParseAmount("-1").Cmp(MustParseAmount("-2")))
+	assert.True(t, MustParseAmount("100").Equal(MustParseAmount("1e2")))
+}
+
+func TestAmount_Div(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "terminating quotient is exact", a: "10", b: "4", want: "2.5"},
+		{name: "rate from converted amount", a: "2934500", b: "100", want: "29345"},
+		{
+			name: "non-terminating quotient keeps 34 significant digits",
+			a:    "1", b: "3",
+			want: "0.3333333333333333333333333333333333",
+		},
+		{
+			name: "rounds half-even at the last digit",
+			a:    "2", b: "3",
+			want: "0.6666666666666666666666666666666667",
+		},
+		{name: "negative dividend", a: "-1", b: "8", want: "-0.125"},
+		{name: "integer digits are never dropped", a: "1e40", b: "3", want: "3333333333333333333333333333333333333333"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MustParseAmount(tt.a).Div(MustParseAmount(tt.b))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestAmount_DivByZero(t *testing.T) {
+	_, err := NewAmountFromInt(1).Div(Amount{})
+	assert.ErrorIs(t, err, ErrDivisionByZero)
+}
+
+func TestAmount_NoPrecisionLossForWei(t *testing.T) {
+	// 1234.567890123456789012 ETH expressed down to the last wei
+	eth := MustParseAmount("1234.567890123456789012")
+	wei := MustParseAmount("0.000000000000000001")
+
+	sum := eth.Add(wei)
+	assert.Equal(t, "1234.567890123456789013", sum.String())
+	assert.Equal(t, "1234.567890123456789012", sum.Sub(wei).String())
+
+	// Price of the whole position at an exact rate must not drift
+	rate := MustParseAmount("3456.789012345678901234")
+	value := eth.Mul(rate)
+	assert.Equal(t, "4267640.717573552823470138119579545924440808", value.String())
+
+	back, err := value.Div(rate)
+	assert.NoError(t, err)
+	assert.Equal(t, eth.String(), back.String())
+}
+
+func TestAmount_JSON(t *testing.T) {
+	var payload struct {
+		Number Amount `json:"number"`
+		String Amount `json:"string"`
+	}
+
+	err := json.Unmarshal([]byte(`{"number": 0.123456789012345678, "string": "42.000000000000000001"}`), &payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.123456789012345678", payload.Number.String())
+	assert.Equal(t, "42.000000000000000001", payload.String.String())
+
+	out, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"number": "0.123456789012345678", "string": "42.000000000000000001"}`, string(out))
+}
+
+func TestAmount_Round(t *testing.T) {
+	tests := []struct {
+		input  string
+		places int32
+		want   string
+	}{
+		{"1.005", 2, "1.00"},
+		{"1.015", 2, "1.02"},
+		{"1.0151", 2, "1.02"},
+		{"-2.5", 0, "-2"},
+		{"-3.5", 0, "-4"},
+		{"0.00421337123", 8, "0.00421337"},
+		{"12.3", 5, "12.3"},
+	}
+
+	for _, tt := range tests {
+		got := MustParseAmount(tt.input).Round(tt.places, RoundHalfEven)
+		assert.Equal(t, tt.want, got.String(), "Round(%s, %d)", tt.input, tt.places)
+	}
+}
+
+func TestAmount_StringFixed(t *testing.T) {
+	assert.Equal(t, "12.30", MustParseAmount("12.3").StringFixed(2))
+	assert.Equal(t, "2934500.00", MustParseAmount("2934500").StringFixed(2))
+	assert.Equal(t, "1234", MustParseAmount("1234.5").StringFixed(0))
+}
@@ -4,14 +4,17 @@ import "time"
 
 // ConversionRequest represents a request to convert one currency to another
 type ConversionRequest struct {
-	Amount       float64
+	Amount       Amount
 	FromCurrency *Currency
 	ToCurrency   *Currency
+
+	// At requests historical prices at that point in time; zero means latest
+	At time.Time
 }
 
 // NewConversionRequest creates a new ConversionRequest with validation
-func NewConversionRequest(amount float64, from, to *Currency) (*ConversionRequest, error) {
-	if amount <= 0 {
+func NewConversionRequest(amount Amount, from, to *Currency) (*ConversionRequest, error) {
+	if amount.Sign() <= 0 {
 		return nil, ErrInvalidAmount
 	}
 
@@ -26,20 +29,76 @@ func NewConversionRequest(amount float64, from, to *Currency) (*ConversionReques
 	}, nil
 }
 
+// IsHistorical reports whether the request asks for prices at a past point in time
+func (r *ConversionRequest) IsHistorical() bool {
+	return !r.At.IsZero()
+}
+
 // ConversionResult represents the result of a currency conversion
 type ConversionResult struct {
-	OriginalAmount   float64
-	ConvertedAmount  float64
-	FromCurrency     *Currency
-	ToCurrency       *Currency
-	ExchangeRate     float64
-	Timestamp        time.Time
-	LastUpdated      time.Time
+	OriginalAmount  Amount
+	ConvertedAmount Amount
+	FromCurrency    *Currency
+	ToCurrency      *Currency
+	ExchangeRate    Amount
+	Timestamp       time.Time
+	LastUpdated     time.Time
+
+	// AsOf is the point in time the prices were requested for; zero for latest prices
+	AsOf time.Time
+
+	// Source names the price provider that answered, e.g. "coinmarketcap"
+	Source string
+
+	// Quotes holds the individual provider quotes behind a consensus rate
+	Quotes []ProviderQuote
+
+	// Spread is the difference between the highest and lowest quoted rate
+	Spread Amount
+
+	// Legs holds the individual conversions of a result routed through bridge currencies
+	Legs []ConversionLeg
+}
+
+// ConversionLeg is one step of a conversion routed through bridge currencies
+type ConversionLeg struct {
+	FromCurrency *Currency
+	ToCurrency   *Currency
+	ExchangeRate Amount
+	Source       string
+}
+
+// Path returns the currencies a routed conversion passed through, from
+// source to target, or nil for a direct conversion
+func (r *ConversionResult) Path() []*Currency {
+	if len(r.Legs) == 0 {
+		return nil
+	}
+	path := []*Currency{r.Legs[0].FromCurrency}
+	for _, leg := range r.Legs {
+		path = append(path, leg.ToCurrency)
+	}
+	return path
+}
+
+// ProviderQuote is the rate one provider quoted for a consensus conversion
+type ProviderQuote struct {
+	Source string
+	Rate   Amount
+
+	// Deviation is the relative difference from the consensus rate, e.g. 0.015 for 1.5%
+	Deviation Amount
+
+	// Rejected is set when the quote deviated beyond the tolerance and was discarded
+	Rejected bool
+
+	// Err is set when the provider failed to quote
+	Err error
 }
 
 // NewConversionResult creates a new ConversionResult
 func NewConversionResult(
-	originalAmount, convertedAmount, exchangeRate float64,
+	originalAmount, convertedAmount, exchangeRate Amount,
 	from, to *Currency,
 	timestamp, lastUpdated time.Time,
 ) *ConversionResult {
@@ -13,42 +13,42 @@ func TestNewConversionRequest(t *testing.T) {
 
 	tests := []struct {
 		name    string
-		amount  float64
+		amount  Amount
 		from    *Currency
 		to      *Currency
 		wantErr bool
 	}{
 		{
 			name:    "valid conversion request",
-			amount:  100.0,
+			amount:  MustParseAmount("100"),
 			from:    usd,
 			to:      btc,
 			wantErr: false,
 		},
 		{
 			name:    "zero amount",
-			amount:  0,
+			amount:  Amount{},
 			from:    usd,
 			to:      btc,
 			wantErr: true,
 		},
 		{
 			name:    "negative amount",
-			amount:  -50.0,
+			amount:  MustParseAmount("-50"),
 			from:    usd,
 			to:      btc,
 			wantErr: true,
 		},
 		{
 			name:    "nil from currency",
-			amount:  100.0,
+			amount:  MustParseAmount("100"),
 			from:    nil,
 			to:      btc,
 			wantErr: true,
 		},
 		{
 			name:    "nil to currency",
-			amount:  100.0,
+			amount:  MustParseAmount("100"),
 			from:    usd,
 			to:      nil,
 			wantErr: true,
@@ -78,14 +78,38 @@ func TestNewConversionResult(t *testing.T) {
 	usd, _ := NewCurrency("USD")
 	now := time.Now()
 
-	result := NewConversionResult(100.0, 0.0025, 0.000025, usd, btc, now, now)
+	result := NewConversionResult(
+		MustParseAmount("100"),
+		MustParseAmount("0.0025"),
+		MustParseAmount("0.000025"),
+		usd, btc, now, now,
+	)
 
 	assert.NotNil(t, result)
-	assert.Equal(t, 100.0, result.OriginalAmount)
-	assert.Equal(t, 0.0025, result.ConvertedAmount)
-	assert.Equal(t, 0.000025, result.ExchangeRate)
+	assert.Equal(t, "100", result.OriginalAmount.String())
+	assert.Equal(t, "0.0025", result.ConvertedAmount.String())
+	assert.Equal(t, "0.000025", result.ExchangeRate.String())
 	assert.Equal(t, usd, result.FromCurrency)
 	assert.Equal(t, btc, result.ToCurrency)
 	assert.Equal(t, now, result.Timestamp)
 	assert.Equal(t, now, result.LastUpdated)
 }
+
+func TestConversionResult_Path(t *testing.T) {
+	eth, _ := NewCurrency("ETH")
+	usd, _ := NewCurrency("USD")
+	chf, _ := NewCurrency("CHF")
+
+	direct := &ConversionResult{FromCurrency: eth, ToCurrency: chf}
+	assert.Nil(t, direct.Path())
+
+	routed := &ConversionResult{
+		FromCurrency: eth,
+		ToCurrency:   chf,
+		Legs: []ConversionLeg{
+			{FromCurrency: eth, ToCurrency: usd, ExchangeRate: MustParseAmount("3000")},
+			{FromCurrency: usd, ToCurrency: chf, ExchangeRate: MustParseAmount("0.88")},
+		},
+	}
+	assert.Equal(t, []*Currency{eth, usd, chf}, routed.Path())
+}
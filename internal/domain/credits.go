@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// CreditUsage reports the API credits spent in the current day and month
+// against the configured budgets
+type CreditUsage struct {
+	// Day is the start of the current UTC day
+	Day time.Time
+
+	DayCredits   int
+	MonthCredits int
+
+	// DailyBudget and MonthlyBudget are zero when unlimited
+	DailyBudget   int
+	MonthlyBudget int
+}
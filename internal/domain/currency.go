@@ -1,17 +1,87 @@
 package domain
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 )
 
-// Currency represents a currency symbol (e.g., BTC, USD, EUR)
+// CurrencyKind distinguishes government-issued currencies from crypto assets
+type CurrencyKind string
+
+const (
+	// CurrencyKindFiat is an ISO 4217 currency
+	CurrencyKindFiat CurrencyKind = "fiat"
+
+	// CurrencyKindCrypto is a crypto asset listed on CoinMarketCap
+	CurrencyKindCrypto CurrencyKind = "crypto"
+)
+
+const (
+	// idPrefix selects an asset by its CoinMarketCap ID, e.g. "id:1027"
+	idPrefix = "id:"
+
+	// slugPrefix selects an asset by its CoinMarketCap slug, e.g. "slug:ethereum"
+	slugPrefix = "slug:"
+)
+
+// DefaultCryptoDecimals is the precision of crypto assets missing from the registry
+const DefaultCryptoDecimals = 8
+
+// Currency represents a currency symbol (e.g., BTC, USD, EUR) and its metadata
 type Currency struct {
 	Symbol string
+	Name   string
+	Kind   CurrencyKind
+
+	// NumericCode is the ISO 4217 numeric code (fiat only), e.g. "840"
+	NumericCode string
+
+	// Decimals is the ISO 4217 minor units for fiat or the asset decimals for crypto
+	Decimals int
+
+	// CMCID is the CoinMarketCap asset ID, when known
+	CMCID int
+
+	// Slug is the CoinMarketCap URL slug (crypto only), e.g. "ethereum"
+	Slug string
+
+	// Sign is the currency sign written before amounts, e.g. "€" or "₿", when known
+	Sign string
+
+	// Pinned is set when the asset was requested by explicit ID or slug
+	// rather than by a ticker symbol that may be shared by several assets
+	Pinned bool
 }
 
-// NewCurrency creates a new Currency with validation
+// NewCurrency creates a new Currency with validation against the default registry.
+// Besides ticker symbols it accepts explicit identifiers of the form "id:1027"
+// and "slug:ethereum".
 func NewCurrency(symbol string) (*Currency, error) {
-	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	return newCurrency(symbol, false)
+}
+
+// NewUnlistedCurrency creates a Currency like NewCurrency, but accepts ticker
+// symbols missing from the registry as crypto assets. It serves callers that
+// have no symbol map to validate such symbols against.
+func NewUnlistedCurrency(symbol string) (*Currency, error) {
+	return newCurrency(symbol, true)
+}
+
+// newCurrency creates a Currency, accepting unknown tickers as crypto assets
+// when unlisted is set
+func newCurrency(symbol string, unlisted bool) (*Currency, error) {
+	symbol = strings.TrimSpace(symbol)
+	lower := strings.ToLower(symbol)
+
+	switch {
+	case strings.HasPrefix(lower, idPrefix):
+		return newCurrencyByID(lower[len(idPrefix):])
+	case strings.HasPrefix(lower, slugPrefix):
+		return newCurrencyBySlug(lower[len(slugPrefix):])
+	}
+
+	symbol = strings.ToUpper(symbol)
 
 	if symbol == "" {
 		return nil, ErrInvalidCurrency
@@ -22,12 +92,68 @@ func NewCurrency(symbol string) (*Currency, error) {
 		return nil, ErrInvalidCurrency
 	}
 
-	return &Currency{Symbol: symbol}, nil
+	currency, ok := DefaultRegistry().Lookup(symbol)
+	if !ok && unlisted {
+		return &Currency{Symbol: symbol, Kind: CurrencyKindCrypto, Decimals: DefaultCryptoDecimals}, nil
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown currency %s", ErrInvalidCurrency, symbol)
+	}
+
+	return currency, nil
+}
+
+// newCurrencyByID creates a pinned Currency from a CoinMarketCap ID
+func newCurrencyByID(raw string) (*Currency, error) {
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("%w: invalid asset ID %q", ErrInvalidCurrency, raw)
+	}
+
+	currency, ok := DefaultRegistry().LookupCMCID(id)
+	if !ok {
+		currency = &Currency{CMCID: id, Kind: CurrencyKindCrypto, Decimals: DefaultCryptoDecimals}
+	}
+	currency.Pinned = true
+
+	return currency, nil
+}
+
+// newCurrencyBySlug creates a pinned Currency from a CoinMarketCap slug
+func newCurrencyBySlug(slug string) (*Currency, error) {
+	if slug == "" || strings.ContainsAny(slug, " \t/?&") {
+		return nil, fmt.Errorf("%w: invalid asset slug %q", ErrInvalidCurrency, slug)
+	}
+
+	currency, ok := DefaultRegistry().LookupSlug(slug)
+	if !ok {
+		currency = &Currency{Slug: slug, Kind: CurrencyKindCrypto, Decimals: DefaultCryptoDecimals}
+	}
+	currency.Pinned = true
+
+	return currency, nil
 }
 
-// String returns the currency symbol as a string
+// String returns the currency symbol as a string, falling back to the
+// explicit identifier when the symbol is not known yet
 func (c *Currency) String() string {
-	return c.Symbol
+	switch {
+	case c.Symbol != "":
+		return c.Symbol
+	case c.CMCID != 0:
+		return idPrefix + strconv.Itoa(c.CMCID)
+	default:
+		return slugPrefix + c.Slug
+	}
+}
+
+// DisplaySign returns the currency sign, falling back to the symbol for
+// currencies without one
+func (c *Currency) DisplaySign() string {
+	if c.Sign != "" {
+		return c.Sign
+	}
+	return c.String()
 }
 
 // Equals checks if two currencies are equal
@@ -35,5 +161,18 @@ func (c *Currency) Equals(other *Currency) bool {
 	if other == nil {
 		return false
 	}
-	return c.Symbol == other.Symbol
+	if c.CMCID != 0 && other.CMCID != 0 {
+		return c.CMCID == other.CMCID
+	}
+	return c.String() == other.String()
+}
+
+// Precision returns the natural number of decimal places for amounts in this currency
+func (c *Currency) Precision() int32 {
+	return int32(c.Decimals)
+}
+
+// IsFiat reports whether the currency is an ISO 4217 currency
+func (c *Currency) IsFiat() bool {
+	return c.Kind == CurrencyKindFiat
 }
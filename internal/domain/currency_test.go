@@ -43,6 +43,12 @@ func TestNewCurrency(t *testing.T) {
 			want:    "",
 			wantErr: true,
 		},
+		{
+			name:    "unknown symbol",
+			symbol:  "ZZZ",
+			want:    "",
+			wantErr: true,
+		},
 		{
 			name:    "too long symbol",
 			symbol:  "VERYLONGCURRENCYSYMBOL",
@@ -56,7 +62,7 @@ func TestNewCurrency(t *testing.T) {
 			got, err := NewCurrency(tt.symbol)
 
 			if tt.wantErr {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, ErrInvalidCurrency)
 				assert.Nil(t, got)
 			} else {
 				assert.NoError(t, err)
@@ -67,11 +73,49 @@ func TestNewCurrency(t *testing.T) {
 	}
 }
 
+func TestNewUnlistedCurrency(t *testing.T) {
+	listed, err := NewUnlistedCurrency("eth")
+	assert.NoError(t, err)
+	assert.Equal(t, "Ethereum", listed.Name)
+
+	unlisted, err := NewUnlistedCurrency(" wif2 ")
+	assert.NoError(t, err)
+	assert.Equal(t, &Currency{Symbol: "WIF2", Kind: CurrencyKindCrypto, Decimals: DefaultCryptoDecimals}, unlisted)
+
+	for _, symbol := range []string{"", "B", "VERYLONGCURRENCYSYMBOL", "id:abc"} {
+		_, err := NewUnlistedCurrency(symbol)
+		assert.ErrorIs(t, err, ErrInvalidCurrency, symbol)
+	}
+}
+
 func TestCurrency_String(t *testing.T) {
 	currency, _ := NewCurrency("BTC")
 	assert.Equal(t, "BTC", currency.String())
 }
 
+func TestCurrency_DisplaySign(t *testing.T) {
+	tests := []struct {
+		symbol string
+		want   string
+	}{
+		{symbol: "USD", want: "$"},
+		{symbol: "EUR", want: "€"},
+		{symbol: "JPY", want: "¥"},
+		{symbol: "CNY", want: "CN¥"},
+		{symbol: "BTC", want: "₿"},
+		{symbol: "CHF", want: "CHF"},
+		{symbol: "DOGE", want: "DOGE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			currency, err := NewCurrency(tt.symbol)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, currency.DisplaySign())
+		})
+	}
+}
+
 func TestCurrency_Equals(t *testing.T) {
 	btc1, _ := NewCurrency("BTC")
 	btc2, _ := NewCurrency("BTC")
@@ -81,3 +125,71 @@ func TestCurrency_Equals(t *testing.T) {
 	assert.False(t, btc1.Equals(eth))
 	assert.False(t, btc1.Equals(nil))
 }
+
+func TestNewCurrency_Metadata(t *testing.T) {
+	usd, err := NewCurrency("usd")
+	assert.NoError(t, err)
+	assert.Equal(t, "US Dollar", usd.Name)
+	assert.Equal(t, "840", usd.NumericCode)
+	assert.Equal(t, int32(2), usd.Precision())
+	assert.True(t, usd.IsFiat())
+
+	jpy, err := NewCurrency("JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), jpy.Precision())
+
+	eth, err := NewCurrency("ETH")
+	assert.NoError(t, err)
+	assert.Equal(t, CurrencyKindCrypto, eth.Kind)
+	assert.Equal(t, 1027, eth.CMCID)
+	assert.Equal(t, int32(18), eth.Precision())
+	assert.False(t, eth.IsFiat())
+}
+
+func TestNewCurrency_ExplicitIdentifiers(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantSymbol string
+		wantID     int
+		wantSlug   string
+		wantString string
+		wantErr    bool
+	}{
+		{name: "known ID", input: "id:1027", wantSymbol: "ETH", wantID: 1027, wantSlug: "ethereum", wantString: "ETH"},
+		{name: "unknown ID", input: "ID:424242", wantID: 424242, wantString: "id:424242"},
+		{name: "known slug", input: "slug:Bitcoin", wantSymbol: "BTC", wantID: 1, wantSlug: "bitcoin", wantString: "BTC"},
+		{name: "unknown slug", input: "slug:some-token", wantSlug: "some-token", wantString: "slug:some-token"},
+		{name: "non-numeric ID", input: "id:eth", wantErr: true},
+		{name: "zero ID", input: "id:0", wantErr: true},
+		{name: "empty slug", input: "slug:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCurrency(tt.input)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCurrency)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, got.Pinned)
+			assert.Equal(t, tt.wantSymbol, got.Symbol)
+			assert.Equal(t, tt.wantID, got.CMCID)
+			assert.Equal(t, tt.wantSlug, got.Slug)
+			assert.Equal(t, tt.wantString, got.String())
+		})
+	}
+
+	// Assets missing from the registry round like crypto rather than to integers
+	for _, input := range []string{"id:424242", "slug:some-token"} {
+		got, err := NewCurrency(input)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(DefaultCryptoDecimals), got.Precision(), input)
+	}
+
+	// Registry entries are never handed out pinned
+	eth, _ := NewCurrency("ETH")
+	assert.False(t, eth.Pinned)
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "errors"
+
+// errorCodes assigns a stable machine-readable code to every domain error.
+// The order decides which code wins when an error wraps several of them.
+var errorCodes = []struct {
+	code string
+	err  error
+}{
+	{"invalid_currency", ErrInvalidCurrency},
+	{"invalid_amount", ErrInvalidAmount},
+	{"malformed_amount", ErrMalformedAmount},
+	{"malformed_input", ErrMalformedInput},
+	{"division_by_zero", ErrDivisionByZero},
+	{"invalid_rounding_mode", ErrInvalidRoundingMode},
+	{"invalid_timestamp", ErrInvalidTimestamp},
+	{"unknown_provider", ErrUnknownProvider},
+	{"no_consensus", ErrNoConsensus},
+	{"unsupported_pair", ErrUnsupportedPair},
+	{"api_key_missing", ErrAPIKeyMissing},
+	{"unauthorized", ErrUnauthorized},
+	{"forbidden", ErrForbidden},
+	{"credit_budget_exceeded", ErrCreditBudgetExceeded},
+	{"rate_limit_exceeded", ErrRateLimitExceeded},
+	{"circuit_open", ErrCircuitOpen},
+	{"server_error", ErrServerError},
+	{"network_failure", ErrNetworkFailure},
+	{"invalid_response", ErrInvalidResponse},
+	{"api_failure", ErrAPIFailure},
+}
+
+// UnknownErrorCode is reported for errors that wrap no domain error
+const UnknownErrorCode = "unknown_error"
+
+// ErrorCode returns the machine-readable code of the domain error wrapped by err
+func ErrorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return UnknownErrorCode
+}
+
+// ErrorForCode returns the domain error with the given code
+func ErrorForCode(code string) (error, bool) {
+	for _, c := range errorCodes {
+		if c.code == code {
+			return c.err, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, "server_error", ErrorCode(ErrServerError))
+	assert.Equal(t, "invalid_currency", ErrorCode(fmt.Errorf("%w: unknown currency ZZZ", ErrInvalidCurrency)))
+	assert.Equal(t, "rate_limit_exceeded", ErrorCode(errors.Join(errors.New("other"), ErrRateLimitExceeded)))
+	assert.Equal(t, UnknownErrorCode, ErrorCode(errors.New("boom")))
+	assert.Equal(t, UnknownErrorCode, ErrorCode(nil))
+}
+
+func TestErrorForCode(t *testing.T) {
+	for _, c := range errorCodes {
+		err, ok := ErrorForCode(c.code)
+		assert.True(t, ok, c.code)
+		assert.Equal(t, c.code, ErrorCode(err))
+	}
+
+	_, ok := ErrorForCode("no_such_code")
+	assert.False(t, ok)
+}
@@ -9,6 +9,31 @@ var (
 	// ErrInvalidAmount indicates that the provided amount is invalid (e.g., negative or zero)
 	ErrInvalidAmount = errors.New("invalid amount: must be greater than zero")
 
+	// ErrMalformedAmount indicates that an amount could not be parsed as a decimal number
+	ErrMalformedAmount = errors.New("malformed amount: not a decimal number")
+
+	// ErrMalformedInput indicates input that does not have the expected shape,
+	// e.g. a batch line with the wrong number of fields
+	ErrMalformedInput = errors.New("malformed input")
+
+	// ErrDivisionByZero indicates an attempt to divide an amount by zero
+	ErrDivisionByZero = errors.New("division by zero")
+
+	// ErrInvalidRoundingMode indicates that a rounding mode name is not recognised
+	ErrInvalidRoundingMode = errors.New("invalid rounding mode")
+
+	// ErrInvalidTimestamp indicates an as-of timestamp that is malformed or in the future
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+	// ErrUnknownProvider indicates that no price provider is registered under the requested name
+	ErrUnknownProvider = errors.New("unknown price provider")
+
+	// ErrUnsupportedPair indicates that a provider cannot price the requested currency pair
+	ErrUnsupportedPair = errors.New("unsupported currency pair")
+
+	// ErrNoConsensus indicates that too few price providers agreed on a rate
+	ErrNoConsensus = errors.New("no consensus between price providers")
+
 	// ErrAPIKeyMissing indicates that the API key is not configured
 	ErrAPIKeyMissing = errors.New("API key is missing")
 
@@ -21,12 +46,18 @@ var (
 	// ErrForbidden indicates insufficient permissions (403)
 	ErrForbidden = errors.New("forbidden: API key does not have access to this endpoint")
 
+	// ErrCreditBudgetExceeded indicates that a call would spend more API credits than budgeted
+	ErrCreditBudgetExceeded = errors.New("API credit budget exceeded")
+
 	// ErrRateLimitExceeded indicates rate limit was exceeded (429)
 	ErrRateLimitExceeded = errors.New("rate limit exceeded: too many requests")
 
 	// ErrServerError indicates server-side error (5xx)
 	ErrServerError = errors.New("server error: please try again later")
 
+	// ErrCircuitOpen indicates that calls to a failing provider are suspended for a cool-down period
+	ErrCircuitOpen = errors.New("provider temporarily unavailable: too many recent failures")
+
 	// ErrNetworkFailure indicates network connectivity issues
 	ErrNetworkFailure = errors.New("network failure: could not connect to API")
 
@@ -11,5 +11,21 @@ type CurrencyConverter interface {
 // PriceRepository defines the interface for fetching price data from external sources
 type PriceRepository interface {
 	// GetConversionPrice fetches the conversion price from the external API
-	GetConversionPrice(ctx context.Context, amount float64, from, to string) (*ConversionResult, error)
+	GetConversionPrice(ctx context.Context, request *ConversionRequest) (*ConversionResult, error)
+}
+
+// CurrencyResolver validates user input and turns it into a Currency
+type CurrencyResolver interface {
+	// Resolve normalizes a symbol or explicit identifier into a known currency
+	Resolve(ctx context.Context, symbol string) (*Currency, error)
+}
+
+// MultiPriceRepository is implemented by repositories that can price several
+// conversion targets of the same source amount in a single upstream call
+type MultiPriceRepository interface {
+	PriceRepository
+
+	// GetConversionPrices prices requests that share amount and source currency
+	// and returns the results in the order of requests
+	GetConversionPrices(ctx context.Context, requests []*ConversionRequest) ([]*ConversionResult, error)
 }
@@ -0,0 +1,167 @@
+package domain
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+//go:embed data/currencies.json
+var currencyData []byte
+
+// registryFile mirrors the layout of data/currencies.json
+type registryFile struct {
+	Fiat []struct {
+		Code       string `json:"code"`
+		Numeric    string `json:"numeric"`
+		Name       string `json:"name"`
+		MinorUnits int    `json:"minor_units"`
+		Sign       string `json:"sign"`
+	} `json:"fiat"`
+	Crypto []struct {
+		Symbol   string `json:"symbol"`
+		Name     string `json:"name"`
+		Slug     string `json:"slug"`
+		Decimals int    `json:"decimals"`
+		CMCID    int    `json:"cmc_id"`
+		Sign     string `json:"sign"`
+	} `json:"crypto"`
+}
+
+// Registry holds metadata for every currency the application knows about
+type Registry struct {
+	mu       sync.RWMutex
+	bySymbol map[string]*Currency
+	byCMCID  map[int]*Currency
+	bySlug   map[string]*Currency
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		bySymbol: make(map[string]*Currency),
+		byCMCID:  make(map[int]*Currency),
+		bySlug:   make(map[string]*Currency),
+	}
+}
+
+// LoadRegistry builds a Registry from a JSON document in the format of data/currencies.json
+func LoadRegistry(data []byte) (*Registry, error) {
+	var file registryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse currency data: %w", err)
+	}
+
+	r := NewRegistry()
+	for _, f := range file.Fiat {
+		r.Register(&Currency{
+			Symbol:      f.Code,
+			Name:        f.Name,
+			Kind:        CurrencyKindFiat,
+			NumericCode: f.Numeric,
+			Decimals:    f.MinorUnits,
+			Sign:        f.Sign,
+		})
+	}
+	for _, c := range file.Crypto {
+		r.Register(&Currency{
+			Symbol:   c.Symbol,
+			Name:     c.Name,
+			Slug:     c.Slug,
+			Kind:     CurrencyKindCrypto,
+			Decimals: c.Decimals,
+			CMCID:    c.CMCID,
+			Sign:     c.Sign,
+		})
+	}
+
+	return r, nil
+}
+
+var (
+	defaultRegistry     *Registry
+	defaultRegistryOnce sync.Once
+)
+
+// DefaultRegistry returns the registry loaded from the embedded currency data
+func DefaultRegistry() *Registry {
+	defaultRegistryOnce.Do(func() {
+		r, err := LoadRegistry(currencyData)
+		if err != nil {
+			panic(err)
+		}
+		defaultRegistry = r
+	})
+	return defaultRegistry
+}
+
+// Register adds or replaces a currency in the registry
+func (r *Registry) Register(c *Currency) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := *c
+	entry.Pinned = false
+	r.bySymbol[entry.Symbol] = &entry
+	if entry.CMCID != 0 {
+		r.byCMCID[entry.CMCID] = &entry
+	}
+	if entry.Slug != "" {
+		r.bySlug[entry.Slug] = &entry
+	}
+}
+
+// Lookup returns a copy of the currency registered under symbol
+func (r *Registry) Lookup(symbol string) (*Currency, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.bySymbol[symbol]
+	if !ok {
+		return nil, false
+	}
+	entry := *c
+	return &entry, true
+}
+
+// LookupCMCID returns a copy of the currency with the given CoinMarketCap ID
+func (r *Registry) LookupCMCID(id int) (*Currency, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.byCMCID[id]
+	if !ok {
+		return nil, false
+	}
+	entry := *c
+	return &entry, true
+}
+
+// LookupSlug returns a copy of the currency with the given CoinMarketCap slug
+func (r *Registry) LookupSlug(slug string) (*Currency, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.bySlug[slug]
+	if !ok {
+		return nil, false
+	}
+	entry := *c
+	return &entry, true
+}
+
+// All returns copies of every registered currency sorted by symbol
+func (r *Registry) All() []*Currency {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]*Currency, 0, len(r.bySymbol))
+	for _, c := range r.bySymbol {
+		entry := *c
+		all = append(all, &entry)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Symbol < all[j].Symbol })
+	return all
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRegistry(t *testing.T) {
+	r := DefaultRegistry()
+
+	eur, ok := r.Lookup("EUR")
+	require.True(t, ok)
+	assert.Equal(t, "Euro", eur.Name)
+	assert.Equal(t, "978", eur.NumericCode)
+
+	bhd, ok := r.Lookup("BHD")
+	require.True(t, ok)
+	assert.Equal(t, 3, bhd.Decimals)
+
+	btc, ok := r.LookupCMCID(1)
+	require.True(t, ok)
+	assert.Equal(t, "BTC", btc.Symbol)
+	assert.Equal(t, 8, btc.Decimals)
+
+	_, ok = r.Lookup("ZZZ")
+	assert.False(t, ok)
+}
+
+func TestRegistry_LookupReturnsCopy(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&Currency{Symbol: "ABC", Name: "Alpha", Kind: CurrencyKindCrypto, CMCID: 42})
+
+	c, ok := r.Lookup("ABC")
+	require.True(t, ok)
+	c.Name = "mutated"
+
+	again, _ := r.Lookup("ABC")
+	assert.Equal(t, "Alpha", again.Name)
+}
+
+func TestLoadRegistry(t *testing.T) {
+	r, err := LoadRegistry([]byte(`{
+		"fiat": [{"code": "USD", "numeric": "840", "name": "US Dollar", "minor_units": 2}],
+		"crypto": [{"symbol": "ETH", "name": "Ethereum", "decimals": 18, "cmc_id": 1027}]
+	}`))
+	require.NoError(t, err)
+
+	all := r.All()
+	require.Len(t, all, 2)
+	assert.Equal(t, "ETH", all[0].Symbol)
+	assert.Equal(t, "USD", all[1].Symbol)
+
+	_, err = LoadRegistry([]byte(`not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RoundingMode determines how an amount is rounded to a number of decimal places
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest neighbour, ties to the even digit (banker's rounding)
+	RoundHalfEven RoundingMode = iota
+
+	// RoundHalfUp rounds to the nearest neighbour, ties away from zero
+	RoundHalfUp
+
+	// RoundDown truncates toward zero
+	RoundDown
+
+	// RoundUp rounds away from zero
+	RoundUp
+
+	// RoundCeiling rounds toward positive infinity
+	RoundCeiling
+
+	// RoundFloor rounds toward negative infinity
+	RoundFloor
+)
+
+var roundingModeNames = map[RoundingMode]string{
+	RoundHalfEven: "half-even",
+	RoundHalfUp:   "half-up",
+	RoundDown:     "down",
+	RoundUp:       "up",
+	RoundCeiling:  "ceiling",
+	RoundFloor:    "floor",
+}
+
+// RoundingModeNames lists the accepted rounding mode names in declaration order
+func RoundingModeNames() []string {
+	names := make([]string, 0, len(roundingModeNames))
+	for m := RoundHalfEven; m <= RoundFloor; m++ {
+		names = append(names, roundingModeNames[m])
+	}
+	return names
+}
+
+// ParseRoundingMode converts a name such as "half-even" into a RoundingMode
+func ParseRoundingMode(name string) (RoundingMode, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for mode, n := range roundingModeNames {
+		if n == name {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q (expected one of %s)",
+		ErrInvalidRoundingMode, name, strings.Join(RoundingModeNames(), ", "))
+}
+
+// String returns the name of the rounding mode
+func (m RoundingMode) String() string {
+	if name, ok := roundingModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(m))
+}
+
+// roundAway reports whether a truncated quotient q with non-zero remainder r
+// must be incremented in magnitude. negative is the sign of the exact quotient.
+func (m RoundingMode) roundAway(q, r, den *big.Int, negative bool) bool {
+	if r.Sign() == 0 {
+		return false
+	}
+
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	half := twice.Cmp(new(big.Int).Abs(den))
+
+	switch m {
+	case RoundHalfUp:
+		return half >= 0
+	case RoundDown:
+		return false
+	case RoundUp:
+		return true
+	case RoundCeiling:
+		return !negative
+	case RoundFloor:
+		return negative
+	default:
+		return half > 0 || half == 0 && q.Bit(0) == 1
+	}
+}
+
+// PrecisionFromCurrency makes a RoundingPolicy use the natural precision of the currency
+const PrecisionFromCurrency int32 = -1
+
+// RoundingPolicy describes how converted amounts are rounded
+type RoundingPolicy struct {
+	Mode RoundingMode
+
+	// Precision is the number of decimal places, or PrecisionFromCurrency
+	Precision int32
+}
+
+// NewRoundingPolicy creates a policy rounding to the currency's natural precision
+func NewRoundingPolicy(mode RoundingMode) RoundingPolicy {
+	return RoundingPolicy{Mode: mode, Precision: PrecisionFromCurrency}
+}
+
+// WithPrecision returns a copy of the policy that rounds to an explicit number of places
+func (p RoundingPolicy) WithPrecision(places int32) RoundingPolicy {
+	p.Precision = places
+	return p
+}
+
+// Places returns the number of decimal places the policy rounds amounts of c to
+func (p RoundingPolicy) Places(c *Currency) int32 {
+	if p.Precision == PrecisionFromCurrency && c != nil {
+		return c.Precision()
+	}
+	return max(p.Precision, 0)
+}
+
+// Apply rounds an amount denominated in c according to the policy. Fiat
+// amounts are padded with zeros to exactly the policy's places, e.g. 65000.00.
+func (p RoundingPolicy) Apply(a Amount, c *Currency) Amount {
+	places := p.Places(c)
+	rounded := a.Round(places, p.Mode)
+	if c == nil || !c.IsFiat() || rounded.scale >= places {
+		return rounded
+	}
+	return Amount{coef: rounded.rescale(places), scale: places}
+}
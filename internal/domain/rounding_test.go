@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmount_RoundModes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  map[RoundingMode]string
+	}{
+		{
+			input: "5.5",
+			want: map[RoundingMode]string{
+				RoundHalfEven: "6", RoundHalfUp: "6", RoundDown: "5",
+				RoundUp: "6", RoundCeiling: "6", RoundFloor: "5",
+			},
+		},
+		{
+			input: "2.5",
+			want: map[RoundingMode]string{
+				RoundHalfEven: "2", RoundHalfUp: "3", RoundDown: "2",
+				RoundUp: "3", RoundCeiling: "3", RoundFloor: "2",
+			},
+		},
+		{
+			input: "1.6",
+			want: map[RoundingMode]string{
+				RoundHalfEven: "2", RoundHalfUp: "2", RoundDown: "1",
+				RoundUp: "2", RoundCeiling: "2", RoundFloor: "1",
+			},
+		},
+		{
+			input: "1.1",
+			want: map[RoundingMode]string{
+				RoundHalfEven: "1", RoundHalfUp: "1", RoundDown: "1",
+				RoundUp: "2", RoundCeiling: "2", RoundFloor: "1",
+			},
+		},
+		{
+			input: "1.0",
+			want: map[RoundingMode]string{
+				RoundHalfEven: "1", RoundHalfUp: "1", RoundDown: "1",
+				RoundUp: "1", RoundCeiling: "1", RoundFloor: "1",
+			},
+		},
+		{
+			input: "-1.1",
+			want: map[RoundingMode]string{
+				RoundHalfEven: "-1", RoundHalfUp: "-1", RoundDown: "-1",
+				RoundUp: "-2", RoundCeiling: "-1", RoundFloor: "-2",
+			},
+		},
+		{
+			input: "-2.5",
+			want: map[RoundingMode]string{
+				RoundHalfEven: "-2", RoundHalfUp: "-3", RoundDown: "-2",
+				RoundUp: "-3", RoundCeiling: "-2", RoundFloor: "-3",
+			},
+		},
+		{
+			input: "-5.5",
+			want: map[RoundingMode]string{
+				RoundHalfEven: "-6", RoundHalfUp: "-6", RoundDown: "-5",
+				RoundUp: "-6", RoundCeiling: "-5", RoundFloor: "-6",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for mode, want := range tt.want {
+			t.Run(tt.input+"/"+mode.String(), func(t *testing.T) {
+				got := MustParseAmount(tt.input).Round(0, mode)
+				assert.Equal(t, want, got.String())
+			})
+		}
+	}
+}
+
+func TestAmount_RoundModesAtPrecision(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		places int32
+		mode   RoundingMode
+		want   string
+	}{
+		{"half-even to cents", "10.125", 2, RoundHalfEven, "10.12"},
+		{"half-up to cents", "10.125", 2, RoundHalfUp, "10.13"},
+		{"down keeps satoshis", "0.123456789", 8, RoundDown, "0.12345678"},
+		{"up to satoshis", "0.123456781", 8, RoundUp, "0.12345679"},
+		{"ceiling negative", "-0.129", 2, RoundCeiling, "-0.12"},
+		{"floor negative", "-0.121", 2, RoundFloor, "-0.13"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MustParseAmount(tt.input).Round(tt.places, tt.mode)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for _, name := range RoundingModeNames() {
+		mode, err := ParseRoundingMode(name)
+		assert.NoError(t, err)
+		assert.Equal(t, name, mode.String())
+	}
+
+	mode, err := ParseRoundingMode(" Half-Up ")
+	assert.NoError(t, err)
+	assert.Equal(t, RoundHalfUp, mode)
+
+	_, err = ParseRoundingMode("bankers")
+	assert.ErrorIs(t, err, ErrInvalidRoundingMode)
+}
+
+func TestRoundingPolicy_Apply(t *testing.T) {
+	usd, _ := NewCurrency("USD")
+	btc, _ := NewCurrency("BTC")
+	jpy, _ := NewCurrency("JPY")
+
+	tests := []struct {
+		name     string
+		policy   RoundingPolicy
+		amount   string
+		currency *Currency
+		want     string
+	}{
+		{"banker's rounding to USD minor units", NewRoundingPolicy(RoundHalfEven), "12.345", usd, "12.34"},
+		{"half-up to explicit 2 decimals", NewRoundingPolicy(RoundHalfUp).WithPrecision(2), "12.345", btc, "12.35"},
+		{"truncation to BTC decimals", NewRoundingPolicy(RoundDown), "0.123456789", btc, "0.12345678"},
+		{"JPY has no minor units", NewRoundingPolicy(RoundHalfEven), "1234.5", jpy, "1234"},
+		{"explicit precision overrides currency", NewRoundingPolicy(RoundFloor).WithPrecision(4), "1.23456", usd, "1.2345"},
+		{"fiat padded to minor units", NewRoundingPolicy(RoundHalfEven), "65000", usd, "65000.00"},
+		{"fiat padded to explicit precision", NewRoundingPolicy(RoundHalfEven).WithPrecision(4), "1.5", usd, "1.5000"},
+		{"explicit zero precision drops fiat minor units", NewRoundingPolicy(RoundHalfEven).WithPrecision(0), "65000.00", usd, "65000"},
+		{"crypto is not padded", NewRoundingPolicy(RoundHalfEven), "1.5", btc, "1.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.Apply(MustParseAmount(tt.amount), tt.currency)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
@@ -3,35 +3,387 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
 )
 
+// appName names the per-user cache directory
+const appName = "currency-conversion-utility"
+
+// DefaultProvider is the price provider used when none is selected
+const DefaultProvider = "coinmarketcap"
+
+// providerEnvPrefix prefixes per-provider settings such as PROVIDER_ECB_API_URL
+const providerEnvPrefix = "PROVIDER_"
+
+// templateEnvPrefix prefixes named output templates such as TEMPLATE_SHORT
+const templateEnvPrefix = "TEMPLATE_"
+
+// ProviderConfig holds the settings of a single price provider
+type ProviderConfig struct {
+	APIKey string
+	APIURL string
+
+	// RequestsPerMinute limits API calls on the client side; zero is unlimited
+	RequestsPerMinute int
+
+	// CreditLedger is where spent API credits are recorded; empty keeps them in memory
+	CreditLedger string
+
+	// DailyCreditBudget and MonthlyCreditBudget cap spent API credits; zero is unlimited
+	DailyCreditBudget   int
+	MonthlyCreditBudget int
+}
+
 // Config holds application configuration
 type Config struct {
 	APIKey string
 	APIURL string
+
+	// Provider names the price provider to use
+	Provider string
+
+	// Providers holds per-provider settings keyed by provider name
+	Providers map[string]ProviderConfig
+
+	// FallbackProviders are tried in order when Provider fails
+	FallbackProviders []string
+
+	// FailoverOn lists the error codes that make the next provider try; empty uses the default policy
+	FailoverOn []string
+
+	// ProviderCooldown is how long a failing provider is skipped
+	ProviderCooldown time.Duration
+
+	// ConsensusProviders are queried together for consensus pricing
+	ConsensusProviders []string
+
+	// ConsensusMethod combines accepted quotes: "median" or "trimmed-mean"
+	ConsensusMethod string
+
+	// ConsensusTolerance is the largest accepted relative deviation from the median
+	ConsensusTolerance domain.Amount
+
+	// ConsensusMinQuotes is how many accepted quotes a consensus needs
+	ConsensusMinQuotes int
+
+	// BridgeCurrencies are the currencies a pair without a direct quote is routed through
+	BridgeCurrencies []string
+
+	// CacheDir holds persistent caches; empty disables them
+	CacheDir string
+
+	// SymbolMapTTL is how long downloaded symbol maps stay fresh
+	SymbolMapTTL time.Duration
+
+	// RateCacheTTL is how long cached exchange rates stay fresh; zero disables the rate cache
+	RateCacheTTL time.Duration
+
+	// RateCacheStaleTTL is how long expired rates are still served while being refreshed
+	RateCacheStaleTTL time.Duration
+
+	// RateCachePersist shares the rate cache between processes through a file in CacheDir
+	RateCachePersist bool
+
+	// Templates holds the named output templates selectable with --format, keyed by name
+	Templates map[string]string
 }
 
 // Load loads configuration from environment variables
 // It attempts to load .env file if present, but doesn't fail if it's missing
 func Load() (*Config, error) {
+	return LoadWithProvider("")
+}
+
+// LoadWithProvider loads configuration like Load, selecting provider instead
+// of the PROVIDER environment variable when it is not empty
+func LoadWithProvider(provider string) (*Config, error) {
+	cfg, err := LoadSettings(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	// The CoinMarketCap key is only needed when CoinMarketCap is used
+	if cfg.APIKey == "" && cfg.Provider == DefaultProvider {
+		return nil, fmt.Errorf("CMC_API_KEY environment variable is required")
+	}
+	return cfg, nil
+}
+
+// LoadSettings loads configuration like LoadWithProvider without requiring
+// the API key of the provider, for commands that never call it
+func LoadSettings(provider string) (*Config, error) {
 	// Try to load .env file
 	_ = godotenv.Load()
 
-	apiKey := os.Getenv("CMC_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("CMC_API_KEY environment variable is required")
+	if provider == "" {
+		provider = os.Getenv("PROVIDER")
+	}
+	if provider == "" {
+		provider = DefaultProvider
 	}
 
+	apiKey := os.Getenv("CMC_API_KEY")
+
 	apiURL := os.Getenv("CMC_API_URL")
 	if apiURL == "" {
 		// Default to sandbox URL if not specified
 		apiURL = "https://sandbox-api.coinmarketcap.com"
 	}
 
+	cacheDir := os.Getenv("CACHE_DIR")
+	if cacheDir == "" {
+		// Caching is simply disabled when no user cache directory exists
+		if userCache, err := os.UserCacheDir(); err == nil {
+			cacheDir = filepath.Join(userCache, appName)
+		}
+	}
+
+	symbolMapTTL, err := durationEnv("SYMBOL_MAP_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	rateCacheTTL, err := durationEnv("RATE_CACHE_TTL", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rateCacheStaleTTL, err := durationEnv("RATE_CACHE_STALE_TTL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	rateCachePersist, err := boolEnv("RATE_CACHE_PERSIST", false)
+	if err != nil {
+		return nil, err
+	}
+
+	providerCooldown, err := durationEnv("PROVIDER_COOLDOWN", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	consensusMethod := os.Getenv("CONSENSUS_METHOD")
+	if consensusMethod == "" {
+		consensusMethod = "median"
+	}
+
+	consensusTolerance := domain.MustParseAmount("0.01")
+	if value := os.Getenv("CONSENSUS_TOLERANCE"); value != "" {
+		consensusTolerance, err = domain.ParseAmount(value)
+		if err != nil || consensusTolerance.Sign() < 0 {
+			return nil, fmt.Errorf("CONSENSUS_TOLERANCE must be a non-negative fraction such as 0.01, got %q", value)
+		}
+	}
+
+	consensusMinQuotes, err := intEnv("CONSENSUS_MIN_QUOTES", 2)
+	if err != nil {
+		return nil, err
+	}
+
+	bridgeCurrencies := []string{"USD", "USDT", "BTC", "EUR"}
+	if bridges := listEnv("BRIDGE_CURRENCIES"); len(bridges) > 0 {
+		bridgeCurrencies = bridges
+		for i, bridge := range bridgeCurrencies {
+			bridgeCurrencies[i] = strings.ToUpper(bridge)
+		}
+	}
+
+	providers := providerEnv()
+	cmc := providers[DefaultProvider]
+	if cmc.APIKey == "" {
+		cmc.APIKey = apiKey
+	}
+	if cmc.APIURL == "" {
+		cmc.APIURL = apiURL
+	}
+	if cmc.RequestsPerMinute, err = limitEnv("CMC_REQUESTS_PER_MINUTE", 30); err != nil {
+		return nil, err
+	}
+	if cmc.DailyCreditBudget, err = limitEnv("CMC_DAILY_CREDIT_BUDGET", 0); err != nil {
+		return nil, err
+	}
+	if cmc.MonthlyCreditBudget, err = limitEnv("CMC_MONTHLY_CREDIT_BUDGET", 0); err != nil {
+		return nil, err
+	}
+	if cacheDir != "" {
+		cmc.CreditLedger = filepath.Join(cacheDir, CreditLedgerFile)
+	}
+	providers[DefaultProvider] = cmc
+
 	return &Config{
-		APIKey: apiKey,
-		APIURL: apiURL,
+		APIKey:             apiKey,
+		APIURL:             apiURL,
+		Provider:           provider,
+		Providers:          providers,
+		FallbackProviders:  listEnv("PROVIDER_FALLBACK"),
+		FailoverOn:         listEnv("FAILOVER_ON"),
+		ProviderCooldown:   providerCooldown,
+		ConsensusProviders: listEnv("CONSENSUS_PROVIDERS"),
+		ConsensusMethod:    consensusMethod,
+		ConsensusTolerance: consensusTolerance,
+		ConsensusMinQuotes: consensusMinQuotes,
+		BridgeCurrencies:   bridgeCurrencies,
+		CacheDir:           cacheDir,
+		SymbolMapTTL:       symbolMapTTL,
+		RateCacheTTL:       rateCacheTTL,
+		RateCacheStaleTTL:  rateCacheStaleTTL,
+		RateCachePersist:   rateCachePersist,
+		Templates:          templateEnv(),
 	}, nil
 }
+
+// ProviderConfig returns the settings of the named provider
+func (c *Config) ProviderConfig(name string) ProviderConfig {
+	return c.Providers[name]
+}
+
+// providerEnv collects PROVIDER_<NAME>_API_KEY and PROVIDER_<NAME>_API_URL
+// settings. Provider names are lower-cased, with underscores read as dashes.
+func providerEnv() map[string]ProviderConfig {
+	providers := make(map[string]ProviderConfig)
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, providerEnvPrefix) || value == "" {
+			continue
+		}
+		key = strings.TrimPrefix(key, providerEnvPrefix)
+
+		for _, suffix := range []string{"_API_KEY", "_API_URL"} {
+			upper, ok := strings.CutSuffix(key, suffix)
+			if !ok || upper == "" {
+				continue
+			}
+
+			name := strings.ReplaceAll(strings.ToLower(upper), "_", "-")
+			pc := providers[name]
+			if suffix == "_API_KEY" {
+				pc.APIKey = value
+			} else {
+				pc.APIURL = value
+			}
+			providers[name] = pc
+		}
+	}
+	return providers
+}
+
+// templateEnv collects TEMPLATE_<NAME> output templates. Names are
+// lower-cased, with underscores read as dashes.
+func templateEnv() map[string]string {
+	templates := make(map[string]string)
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		name, ok := strings.CutPrefix(key, templateEnvPrefix)
+		if !ok || name == "" || value == "" {
+			continue
+		}
+		templates[strings.ReplaceAll(strings.ToLower(name), "_", "-")] = value
+	}
+	return templates
+}
+
+// The files this tool writes to CacheDir
+const (
+	SymbolMapFile      = "cmc-symbols.json"
+	ProviderHealthFile = "provider-health.json"
+	CreditLedgerFile   = "cmc-credits.json"
+)
+
+// RateCacheFile names the persisted rate cache of a provider
+func RateCacheFile(provider string) string {
+	return "rates-" + provider + ".json"
+}
+
+// IsCacheFile reports whether name is one of the files this tool writes to
+// CacheDir; other files in the directory belong to someone else
+func IsCacheFile(name string) bool {
+	switch name {
+	case SymbolMapFile, ProviderHealthFile, CreditLedgerFile:
+		return true
+	}
+	return strings.HasPrefix(name, "rates-") && strings.HasSuffix(name, ".json")
+}
+
+// CachePath returns the path of a file inside CacheDir, or "" when caching is disabled
+func (c *Config) CachePath(name string) string {
+	if c.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(c.CacheDir, name)
+}
+
+// durationEnv reads a time.Duration such as "24h" from an environment variable
+func durationEnv(name string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative duration such as 30m or 24h, got %q", name, value)
+	}
+	return d, nil
+}
+
+// boolEnv reads a boolean such as "true" or "0" from an environment variable
+func boolEnv(name string, fallback bool) (bool, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, got %q", name, value)
+	}
+	return b, nil
+}
+
+// listEnv reads a comma-separated list such as "ecb, coinmarketcap" from an
+// environment variable, dropping empty entries
+func listEnv(name string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// intEnv reads a positive integer from an environment variable
+func intEnv(name string, fallback int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", name, value)
+	}
+	return n, nil
+}
+
+// limitEnv reads a non-negative integer limit from an environment variable,
+// where zero means unlimited
+func limitEnv(name string, fallback int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be zero (unlimited) or a positive integer, got %q", name, value)
+	}
+	return n, nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -68,3 +69,246 @@ func TestLoad(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_SymbolMapTTL(t *testing.T) {
+	os.Setenv("CMC_API_KEY", "test-api-key")
+	defer os.Unsetenv("CMC_API_KEY")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, cfg.SymbolMapTTL)
+
+	os.Setenv("SYMBOL_MAP_TTL", "90m")
+	cfg, err = Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, cfg.SymbolMapTTL)
+
+	os.Setenv("SYMBOL_MAP_TTL", "tomorrow")
+	defer os.Unsetenv("SYMBOL_MAP_TTL")
+	_, err = Load()
+	assert.Error(t, err)
+}
+
+func TestLoad_RateCache(t *testing.T) {
+	os.Setenv("CMC_API_KEY", "test-api-key")
+	defer os.Unsetenv("CMC_API_KEY")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), cfg.RateCacheTTL)
+	assert.Equal(t, 5*time.Minute, cfg.RateCacheStaleTTL)
+	assert.False(t, cfg.RateCachePersist)
+
+	os.Setenv("RATE_CACHE_TTL", "1m")
+	os.Setenv("RATE_CACHE_PERSIST", "true")
+	defer os.Unsetenv("RATE_CACHE_TTL")
+	defer os.Unsetenv("RATE_CACHE_PERSIST")
+	cfg, err = Load()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, cfg.RateCacheTTL)
+	assert.True(t, cfg.RateCachePersist)
+
+	os.Setenv("RATE_CACHE_PERSIST", "sometimes")
+	_, err = Load()
+	assert.Error(t, err)
+}
+
+func TestLoad_Providers(t *testing.T) {
+	os.Setenv("CMC_API_KEY", "cmc-key")
+	os.Setenv("CMC_API_URL", "https://cmc.example.com")
+	os.Setenv("PROVIDER_OPEN_RATES_API_KEY", "open-key")
+	os.Setenv("PROVIDER_OPEN_RATES_API_URL", "https://open.example.com")
+	defer os.Unsetenv("CMC_API_KEY")
+	defer os.Unsetenv("CMC_API_URL")
+	defer os.Unsetenv("PROVIDER_OPEN_RATES_API_KEY")
+	defer os.Unsetenv("PROVIDER_OPEN_RATES_API_URL")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultProvider, cfg.Provider)
+	assert.Equal(t, "cmc-key", cfg.ProviderConfig("coinmarketcap").APIKey)
+	assert.Equal(t, "https://cmc.example.com", cfg.ProviderConfig("coinmarketcap").APIURL)
+	assert.Equal(t, ProviderConfig{APIKey: "open-key", APIURL: "https://open.example.com"}, cfg.ProviderConfig("open-rates"))
+	assert.Equal(t, ProviderConfig{}, cfg.ProviderConfig("unknown"))
+
+	os.Setenv("PROVIDER", "open-rates")
+	defer os.Unsetenv("PROVIDER")
+	cfg, err = Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "open-rates", cfg.Provider)
+
+	cfg, err = LoadWithProvider("other")
+	assert.NoError(t, err)
+	assert.Equal(t, "other", cfg.Provider)
+}
+
+func TestLoad_Fallback(t *testing.T) {
+	os.Setenv("CMC_API_KEY", "test-api-key")
+	defer os.Unsetenv("CMC_API_KEY")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.FallbackProviders)
+	assert.Empty(t, cfg.FailoverOn)
+	assert.Equal(t, time.Minute, cfg.ProviderCooldown)
+
+	os.Setenv("PROVIDER_FALLBACK", " ECB, ,other ")
+	os.Setenv("FAILOVER_ON", "server_error,network_failure")
+	os.Setenv("PROVIDER_COOLDOWN", "30s")
+	defer os.Unsetenv("PROVIDER_FALLBACK")
+	defer os.Unsetenv("FAILOVER_ON")
+	defer os.Unsetenv("PROVIDER_COOLDOWN")
+
+	cfg, err = Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ecb", "other"}, cfg.FallbackProviders)
+	assert.Equal(t, []string{"server_error", "network_failure"}, cfg.FailoverOn)
+	assert.Equal(t, 30*time.Second, cfg.ProviderCooldown)
+}
+
+func TestLoad_Consensus(t *testing.T) {
+	os.Setenv("CMC_API_KEY", "test-api-key")
+	defer os.Unsetenv("CMC_API_KEY")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.ConsensusProviders)
+	assert.Equal(t, "median", cfg.ConsensusMethod)
+	assert.Equal(t, "0.01", cfg.ConsensusTolerance.String())
+	assert.Equal(t, 2, cfg.ConsensusMinQuotes)
+
+	os.Setenv("CONSENSUS_PROVIDERS", "coinmarketcap,ecb")
+	os.Setenv("CONSENSUS_METHOD", "trimmed-mean")
+	os.Setenv("CONSENSUS_TOLERANCE", "0.005")
+	os.Setenv("CONSENSUS_MIN_QUOTES", "3")
+	defer os.Unsetenv("CONSENSUS_PROVIDERS")
+	defer os.Unsetenv("CONSENSUS_METHOD")
+	defer os.Unsetenv("CONSENSUS_TOLERANCE")
+	defer os.Unsetenv("CONSENSUS_MIN_QUOTES")
+
+	cfg, err = Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"coinmarketcap", "ecb"}, cfg.ConsensusProviders)
+	assert.Equal(t, "trimmed-mean", cfg.ConsensusMethod)
+	assert.Equal(t, "0.005", cfg.ConsensusTolerance.String())
+	assert.Equal(t, 3, cfg.ConsensusMinQuotes)
+
+	os.Setenv("CONSENSUS_TOLERANCE", "-1")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Setenv("CONSENSUS_TOLERANCE", "0.01")
+	os.Setenv("CONSENSUS_MIN_QUOTES", "0")
+	_, err = Load()
+	assert.Error(t, err)
+}
+
+func TestLoad_BridgeCurrencies(t *testing.T) {
+	os.Setenv("CMC_API_KEY", "test-api-key")
+	defer os.Unsetenv("CMC_API_KEY")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"USD", "USDT", "BTC", "EUR"}, cfg.BridgeCurrencies)
+
+	os.Setenv("BRIDGE_CURRENCIES", "eth, usdc")
+	defer os.Unsetenv("BRIDGE_CURRENCIES")
+
+	cfg, err = Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ETH", "USDC"}, cfg.BridgeCurrencies)
+}
+
+func TestLoad_Templates(t *testing.T) {
+	os.Setenv("CMC_API_KEY", "test-api-key")
+	os.Setenv("TEMPLATE_SHORT", "{{.ConvertedAmount}}")
+	os.Setenv("TEMPLATE_RATE_ONLY", "{{.ExchangeRate}}")
+	os.Setenv("TEMPLATE_EMPTY", "")
+	defer os.Unsetenv("CMC_API_KEY")
+	defer os.Unsetenv("TEMPLATE_SHORT")
+	defer os.Unsetenv("TEMPLATE_RATE_ONLY")
+	defer os.Unsetenv("TEMPLATE_EMPTY")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"short":     "{{.ConvertedAmount}}",
+		"rate-only": "{{.ExchangeRate}}",
+	}, cfg.Templates)
+}
+
+func TestLoad_CreditLimits(t *testing.T) {
+	os.Setenv("CMC_API_KEY", "test-api-key")
+	os.Setenv("CACHE_DIR", "/tmp/ccu-cache")
+	defer os.Unsetenv("CMC_API_KEY")
+	defer os.Unsetenv("CACHE_DIR")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	cmc := cfg.ProviderConfig("coinmarketcap")
+	assert.Equal(t, 30, cmc.RequestsPerMinute)
+	assert.Equal(t, 0, cmc.DailyCreditBudget)
+	assert.Equal(t, 0, cmc.MonthlyCreditBudget)
+	assert.Equal(t, "/tmp/ccu-cache/cmc-credits.json", cmc.CreditLedger)
+
+	os.Setenv("CMC_REQUESTS_PER_MINUTE", "0")
+	os.Setenv("CMC_DAILY_CREDIT_BUDGET", "300")
+	os.Setenv("CMC_MONTHLY_CREDIT_BUDGET", "10000")
+	defer os.Unsetenv("CMC_REQUESTS_PER_MINUTE")
+	defer os.Unsetenv("CMC_DAILY_CREDIT_BUDGET")
+	defer os.Unsetenv("CMC_MONTHLY_CREDIT_BUDGET")
+
+	cfg, err = Load()
+	assert.NoError(t, err)
+	cmc = cfg.ProviderConfig("coinmarketcap")
+	assert.Equal(t, 0, cmc.RequestsPerMinute)
+	assert.Equal(t, 300, cmc.DailyCreditBudget)
+	assert.Equal(t, 10000, cmc.MonthlyCreditBudget)
+
+	os.Setenv("CMC_MONTHLY_CREDIT_BUDGET", "-1")
+	_, err = Load()
+	assert.Error(t, err)
+}
+
+func TestLoadWithProvider_KeyOnlyRequiredForCoinMarketCap(t *testing.T) {
+	os.Unsetenv("CMC_API_KEY")
+
+	_, err := LoadWithProvider("coinmarketcap")
+	assert.Error(t, err)
+
+	cfg, err := LoadWithProvider("open-rates")
+	assert.NoError(t, err)
+	assert.Equal(t, "open-rates", cfg.Provider)
+}
+
+func TestLoadSettings_KeyNotRequired(t *testing.T) {
+	os.Unsetenv("CMC_API_KEY")
+
+	cfg, err := LoadSettings("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultProvider, cfg.Provider)
+	assert.Empty(t, cfg.APIKey)
+}
+
+func TestConfig_CachePath(t *testing.T) {
+	os.Setenv("CMC_API_KEY", "test-api-key")
+	os.Setenv("CACHE_DIR", "/tmp/ccu-cache")
+	defer os.Unsetenv("CMC_API_KEY")
+	defer os.Unsetenv("CACHE_DIR")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/ccu-cache/symbols.json", cfg.CachePath("symbols.json"))
+
+	cfg.CacheDir = ""
+	assert.Equal(t, "", cfg.CachePath("symbols.json"))
+}
+
+func TestIsCacheFile(t *testing.T) {
+	for _, name := range []string{SymbolMapFile, ProviderHealthFile, CreditLedgerFile, RateCacheFile("ecb")} {
+		assert.True(t, IsCacheFile(name), name)
+	}
+	for _, name := range []string{".bashrc", "notes.txt", "rates.csv", "symbols.json"} {
+		assert.False(t, IsCacheFile(name), name)
+	}
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// DefaultBatchWorkers is how many pairs ExecuteBatch prices concurrently
+// when no worker count is given
+const DefaultBatchWorkers = 4
+
+// BatchItem is one conversion of a batch
+type BatchItem struct {
+	Amount     domain.Amount
+	FromSymbol string
+	ToSymbol   string
+}
+
+// BatchResult is the outcome of one BatchItem; either Result or Err is set
+type BatchResult struct {
+	Result *domain.ConversionResult
+	Err    error
+}
+
+// batchPair identifies the currency pair of batch items priced together
+type batchPair struct {
+	from, to string
+}
+
+// ExecuteBatch converts every item. Each distinct pair is priced once, by at
+// most workers concurrent lookups, and the other items of the pair are
+// converted at the same rate. Results are returned in the order of items; a
+// failed item does not stop the others.
+func (uc *ConvertCurrencyUseCase) ExecuteBatch(
+	ctx context.Context,
+	items []BatchItem,
+	workers int,
+	opts ...ExecuteOption,
+) []BatchResult {
+	results := make([]BatchResult, len(items))
+	o := NewExecuteOptions(opts...)
+	if err := validateAt(o.At); err != nil {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+
+	// Group items by pair so every pair is priced once
+	groups := make(map[batchPair][]int)
+	var pairs []batchPair
+	for i, item := range items {
+		if item.Amount.Sign() <= 0 {
+			results[i].Err = fmt.Errorf("%w: %s must be greater than zero", domain.ErrInvalidAmount, item.Amount)
+			continue
+		}
+		key := batchPair{from: normalizeSymbol(item.FromSymbol), to: normalizeSymbol(item.ToSymbol)}
+		if _, ok := groups[key]; !ok {
+			pairs = append(pairs, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	if workers < 1 {
+		workers = DefaultBatchWorkers
+	}
+
+	jobs := make(chan []int)
+	var wg sync.WaitGroup
+	for range min(workers, len(pairs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for indexes := range jobs {
+				uc.convertGroup(ctx, items, indexes, o.At, results)
+			}
+		}()
+	}
+	for _, key := range pairs {
+		jobs <- groups[key]
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// convertGroup prices the first of the items at indexes, which share a pair,
+// and converts the rest at its rate
+func (uc *ConvertCurrencyUseCase) convertGroup(
+	ctx context.Context,
+	items []BatchItem,
+	indexes []int,
+	at time.Time,
+	results []BatchResult,
+) {
+	first := items[indexes[0]]
+	priced, err := uc.execute(ctx, first.Amount, first.FromSymbol, first.ToSymbol, at)
+	for _, i := range indexes {
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Result = uc.round(rescale(priced, items[i].Amount))
+	}
+}
+
+// rescale returns result converted for amount at the same exchange rate
+func rescale(result *domain.ConversionResult, amount domain.Amount) *domain.ConversionResult {
+	if amount.Equal(result.OriginalAmount) {
+		return result
+	}
+
+	scaled := *result
+	scaled.OriginalAmount = amount
+	scaled.ConvertedAmount = amount.Mul(result.ExchangeRate)
+	return &scaled
+}
+
+// normalizeSymbol folds the spellings of a symbol that name the same currency
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
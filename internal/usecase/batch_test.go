@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertCurrencyUseCase_ExecuteBatch(t *testing.T) {
+	repo := &rateTable{rates: map[string]string{
+		"BTC/USD": "65000.12345",
+		"ETH/EUR": "3000",
+	}}
+	uc := NewConvertCurrencyUseCase(repo, WithRounding(domain.NewRoundingPolicy(domain.RoundHalfEven)))
+
+	items := []BatchItem{
+		{Amount: domain.MustParseAmount("1"), FromSymbol: "BTC", ToSymbol: "USD"},
+		{Amount: domain.MustParseAmount("2"), FromSymbol: "ETH", ToSymbol: "EUR"},
+		{Amount: domain.MustParseAmount("0.5"), FromSymbol: "btc", ToSymbol: "usd"},
+		{Amount: domain.MustParseAmount("10"), FromSymbol: "DOGE", ToSymbol: "CHF"},
+		{Amount: domain.MustParseAmount("0"), FromSymbol: "BTC", ToSymbol: "USD"},
+		{Amount: domain.MustParseAmount("3"), FromSymbol: "X", ToSymbol: "USD"},
+		{Amount: domain.MustParseAmount("0.25"), FromSymbol: "BTC", ToSymbol: "USD"},
+	}
+
+	results := uc.ExecuteBatch(context.Background(), items, 2)
+	require.Len(t, results, len(items))
+
+	converted := func(i int) string {
+		require.NoError(t, results[i].Err, "item %d", i)
+		return results[i].Result.ConvertedAmount.String()
+	}
+	assert.Equal(t, "65000.12", converted(0))
+	assert.Equal(t, "6000.00", converted(1))
+	assert.Equal(t, "32500.06", converted(2))
+	assert.Equal(t, "0.5", results[2].Result.OriginalAmount.String())
+	assert.Equal(t, "16250.03", converted(6))
+
+	assert.ErrorIs(t, results[3].Err, domain.ErrUnsupportedPair)
+	assert.ErrorIs(t, results[4].Err, domain.ErrInvalidAmount)
+	assert.ErrorIs(t, results[5].Err, domain.ErrInvalidCurrency)
+
+	// Every distinct pair is priced once
+	assert.ElementsMatch(t, []string{"BTC/USD", "ETH/EUR", "DOGE/CHF"}, repo.calls)
+}
+
+func TestConvertCurrencyUseCase_ExecuteBatchHistorical(t *testing.T) {
+	repo := &rateTable{rates: map[string]string{"BTC/USD": "65000"}}
+	uc := NewConvertCurrencyUseCase(repo)
+
+	items := []BatchItem{
+		{Amount: domain.MustParseAmount("1"), FromSymbol: "BTC", ToSymbol: "USD"},
+		{Amount: domain.MustParseAmount("2"), FromSymbol: "BTC", ToSymbol: "USD"},
+	}
+
+	results := uc.ExecuteBatch(context.Background(), items, 0, WithAsOf(time.Now().Add(time.Hour)))
+	for _, result := range results {
+		assert.ErrorIs(t, result.Err, domain.ErrInvalidTimestamp)
+	}
+	assert.Empty(t, repo.calls)
+}
+
+func TestConvertCurrencyUseCase_ExecuteBatchEmpty(t *testing.T) {
+	uc := NewConvertCurrencyUseCase(&rateTable{})
+	assert.Empty(t, uc.ExecuteBatch(context.Background(), nil, 4))
+}
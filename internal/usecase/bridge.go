@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+)
+
+// routableErrors mean a provider cannot quote the pair directly, so a route
+// through bridge currencies may still succeed
+var routableErrors = []error{
+	domain.ErrUnsupportedPair,
+	domain.ErrInvalidCurrency,
+}
+
+// WithBridgeCurrencies routes pairs the repository cannot quote directly
+// through the given currencies, trying single bridges in order before
+// chaining two of them
+func WithBridgeCurrencies(symbols ...string) Option {
+	return func(uc *ConvertCurrencyUseCase) {
+		uc.bridges = symbols
+	}
+}
+
+// convert prices request directly, falling back to a bridged route when the
+// pair is not quoted and bridges are configured
+func (uc *ConvertCurrencyUseCase) convert(
+	ctx context.Context,
+	request *domain.ConversionRequest,
+) (*domain.ConversionResult, error) {
+	result, err := uc.priceRepo.GetConversionPrice(ctx, request)
+	if err == nil || len(uc.bridges) == 0 || !isRoutable(err) {
+		return result, err
+	}
+
+	routed, routeErr := uc.convertViaBridges(ctx, request)
+	if routeErr != nil {
+		return nil, fmt.Errorf("%w (no route through %s: %w)", err, strings.Join(uc.bridges, ", "), routeErr)
+	}
+	return routed, nil
+}
+
+// convertViaBridges tries every route through the bridge currencies and
+// returns the first one whose legs are all quoted
+func (uc *ConvertCurrencyUseCase) convertViaBridges(
+	ctx context.Context,
+	request *domain.ConversionRequest,
+) (*domain.ConversionResult, error) {
+	bridges, err := uc.bridgeCurrencies(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	lastErr := domain.ErrUnsupportedPair
+	for _, route := range bridgeRoutes(bridges) {
+		result, err := uc.convertRoute(ctx, request, route)
+		if err == nil {
+			return result, nil
+		}
+		if !isRoutable(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// bridgeCurrencies resolves the configured bridges, leaving out the
+// currencies of the request itself
+func (uc *ConvertCurrencyUseCase) bridgeCurrencies(
+	ctx context.Context,
+	request *domain.ConversionRequest,
+) ([]*domain.Currency, error) {
+	bridges := make([]*domain.Currency, 0, len(uc.bridges))
+	for _, symbol := range uc.bridges {
+		bridge, err := uc.resolveCurrency(ctx, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("bridge currency %s: %w", symbol, err)
+		}
+		if bridge.Equals(request.FromCurrency) || bridge.Equals(request.ToCurrency) {
+			continue
+		}
+		bridges = append(bridges, bridge)
+	}
+	return bridges, nil
+}
+
+// bridgeRoutes lists the sequences of bridges to try: every single bridge in
+// the configured order, then every ordered pair of distinct bridges
+func bridgeRoutes(bridges []*domain.Currency) [][]*domain.Currency {
+	routes := make([][]*domain.Currency, 0, len(bridges)*len(bridges))
+	for _, bridge := range bridges {
+		routes = append(routes, []*domain.Currency{bridge})
+	}
+	for _, first := range bridges {
+		for _, second := range bridges {
+			if !first.Equals(second) {
+				routes = append(routes, []*domain.Currency{first, second})
+			}
+		}
+	}
+	return routes
+}
+
+// convertRoute converts request leg by leg through route, feeding each
+// converted amount into the next leg
+func (uc *ConvertCurrencyUseCase) convertRoute(
+	ctx context.Context,
+	request *domain.ConversionRequest,
+	route []*domain.Currency,
+) (*domain.ConversionResult, error) {
+	stops := append(append([]*domain.Currency{request.FromCurrency}, route...), request.ToCurrency)
+
+	amount := request.Amount
+	legs := make([]domain.ConversionLeg, 0, len(stops)-1)
+	var last *domain.ConversionResult
+	var lastUpdated time.Time
+	var sources []string
+
+	for i := 0; i < len(stops)-1; i++ {
+		leg, err := domain.NewConversionRequest(amount, stops[i], stops[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s to %s: %v", domain.ErrUnsupportedPair, stops[i], stops[i+1], err)
+		}
+		leg.At = request.At
+
+		result, err := uc.priceRepo.GetConversionPrice(ctx, leg)
+		if err != nil {
+			return nil, err
+		}
+
+		legs = append(legs, domain.ConversionLeg{
+			FromCurrency: result.FromCurrency,
+			ToCurrency:   result.ToCurrency,
+			ExchangeRate: result.ExchangeRate,
+			Source:       result.Source,
+		})
+		if i == 0 || result.LastUpdated.Before(lastUpdated) {
+			lastUpdated = result.LastUpdated
+		}
+		if result.Source != "" && !slices.Contains(sources, result.Source) {
+			sources = append(sources, result.Source)
+		}
+
+		amount = result.ConvertedAmount
+		last = result
+	}
+
+	rate, err := amount.Div(request.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	result := domain.NewConversionResult(
+		request.Amount,
+		amount,
+		rate,
+		legs[0].FromCurrency,
+		last.ToCurrency,
+		last.Timestamp,
+		lastUpdated,
+	)
+	result.AsOf = request.At
+	result.Source = strings.Join(sources, ", ")
+	result.Legs = legs
+	return result, nil
+}
+
+// isRoutable reports whether err leaves room for a bridged route
+func isRoutable(err error) bool {
+	for _, target := range routableErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rateTable quotes only the pairs it lists, keyed like "ETH/USD"
+type rateTable struct {
+	rates  map[string]string
+	source string
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *rateTable) GetConversionPrice(ctx context.Context, request *domain.ConversionRequest) (*domain.ConversionResult, error) {
+	pair := request.FromCurrency.String() + "/" + request.ToCurrency.String()
+	r.mu.Lock()
+	r.calls = append(r.calls, pair)
+	r.mu.Unlock()
+
+	rate, ok := r.rates[pair]
+	if !ok {
+		return nil, domain.ErrUnsupportedPair
+	}
+	result := domain.NewConversionResult(
+		request.Amount,
+		request.Amount.Mul(domain.MustParseAmount(rate)),
+		domain.MustParseAmount(rate),
+		request.FromCurrency,
+		request.ToCurrency,
+		time.Now(),
+		time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	)
+	result.Source = r.source
+	return result, nil
+}
+
+func TestConvertCurrencyUseCase_Bridges(t *testing.T) {
+	tests := []struct {
+		name      string
+		rates     map[string]string
+		bridges   []string
+		wantPath  []string
+		wantRate  string
+		wantTotal string
+		wantErr   error
+	}{
+		{
+			name:      "direct quote needs no bridge",
+			rates:     map[string]string{"ETH/GBP": "2500", "ETH/USD": "3000"},
+			bridges:   []string{"USD"},
+			wantRate:  "2500",
+			wantTotal: "5000",
+		},
+		{
+			name:      "single bridge",
+			rates:     map[string]string{"ETH/USD": "3000", "USD/GBP": "0.8"},
+			bridges:   []string{"USD", "EUR"},
+			wantPath:  []string{"ETH", "USD", "GBP"},
+			wantRate:  "2400",
+			wantTotal: "4800",
+		},
+		{
+			name:      "first working bridge in configured order",
+			rates:     map[string]string{"ETH/EUR": "2800", "EUR/GBP": "0.85", "ETH/USD": "3000"},
+			bridges:   []string{"USD", "EUR"},
+			wantPath:  []string{"ETH", "EUR", "GBP"},
+			wantRate:  "2380",
+			wantTotal: "4760",
+		},
+		{
+			name:      "two bridges",
+			rates:     map[string]string{"ETH/BTC": "0.05", "BTC/USD": "60000", "USD/GBP": "0.8"},
+			bridges:   []string{"BTC", "USD"},
+			wantPath:  []string{"ETH", "BTC", "USD", "GBP"},
+			wantRate:  "2400",
+			wantTotal: "4800",
+		},
+		{
+			name:    "no route",
+			rates:   map[string]string{"ETH/USD": "3000"},
+			bridges: []string{"USD"},
+			wantErr: domain.ErrUnsupportedPair,
+		},
+		{
+			name:    "bridging disabled",
+			rates:   map[string]string{"ETH/USD": "3000", "USD/GBP": "0.8"},
+			wantErr: domain.ErrUnsupportedPair,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &rateTable{rates: tt.rates, source: "test"}
+			uc := NewConvertCurrencyUseCase(repo, WithBridgeCurrencies(tt.bridges...))
+
+			result, err := uc.Execute(context.Background(), domain.MustParseAmount("2"), "ETH", "GBP")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var path []string
+			for _, currency := range result.Path() {
+				path = append(path, currency.String())
+			}
+			assert.Equal(t, tt.wantPath, path)
+			assert.Len(t, result.Legs, max(len(tt.wantPath)-1, 0))
+			assert.True(t, domain.MustParseAmount(tt.wantRate).Equal(result.ExchangeRate), "rate %s", result.ExchangeRate)
+			assert.True(t, domain.MustParseAmount(tt.wantTotal).Equal(result.ConvertedAmount), "total %s", result.ConvertedAmount)
+			assert.Equal(t, "ETH", result.FromCurrency.String())
+			assert.Equal(t, "GBP", result.ToCurrency.String())
+			assert.Equal(t, "test", result.Source)
+		})
+	}
+}
+
+func TestConvertCurrencyUseCase_BridgesNonRoutableError(t *testing.T) {
+	repo := &failingAfter{rateTable: rateTable{rates: map[string]string{"ETH/USD": "3000"}}}
+	uc := NewConvertCurrencyUseCase(repo, WithBridgeCurrencies("USD", "EUR"))
+
+	_, err := uc.Execute(context.Background(), domain.MustParseAmount("1"), "ETH", "GBP")
+
+	// A server error on a leg ends the search instead of trying EUR
+	assert.ErrorIs(t, err, domain.ErrServerError)
+	assert.Equal(t, []string{"ETH/GBP", "ETH/USD", "USD/GBP"}, repo.calls)
+}
+
+func TestConvertCurrencyUseCase_ExecuteMultiBridges(t *testing.T) {
+	repo := &rateTable{rates: map[string]string{"ETH/USD": "3000", "USD/GBP": "0.8"}}
+	uc := NewConvertCurrencyUseCase(repo, WithBridgeCurrencies("USD"))
+
+	results, err := uc.ExecuteMulti(context.Background(), domain.MustParseAmount("1"), "ETH", []string{"USD", "GBP"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Empty(t, results[0].Legs)
+	assert.Len(t, results[1].Legs, 2)
+	assert.True(t, domain.MustParseAmount("2400").Equal(results[1].ConvertedAmount))
+}
+
+// failingAfter answers like rateTable but fails the USD/GBP leg with a server error
+type failingAfter struct {
+	rateTable
+}
+
+func (r *failingAfter) GetConversionPrice(ctx context.Context, request *domain.ConversionRequest) (*domain.ConversionResult, error) {
+	if request.FromCurrency.String() == "USD" && request.ToCurrency.String() == "GBP" {
+		r.calls = append(r.calls, "USD/GBP")
+		return nil, domain.ErrServerError
+	}
+	return r.rateTable.GetConversionPrice(ctx, request)
+}
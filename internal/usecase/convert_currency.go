@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/kerimovkk/currency-conversion-utility/internal/domain"
 )
@@ -9,28 +11,99 @@ import (
 // ConvertCurrencyUseCase handles currency conversion business logic
 type ConvertCurrencyUseCase struct {
 	priceRepo domain.PriceRepository
+	resolver  domain.CurrencyResolver
+	rounding  *domain.RoundingPolicy
+	bridges   []string
+}
+
+// Option configures optional behaviour of ConvertCurrencyUseCase
+type Option func(*ConvertCurrencyUseCase)
+
+// WithRounding rounds every converted amount according to policy
+func WithRounding(policy domain.RoundingPolicy) Option {
+	return func(uc *ConvertCurrencyUseCase) {
+		uc.rounding = &policy
+	}
+}
+
+// WithCurrencyResolver validates and normalizes symbols through resolver
+// instead of the built-in currency registry
+func WithCurrencyResolver(resolver domain.CurrencyResolver) Option {
+	return func(uc *ConvertCurrencyUseCase) {
+		uc.resolver = resolver
+	}
 }
 
 // NewConvertCurrencyUseCase creates a new ConvertCurrencyUseCase instance
-func NewConvertCurrencyUseCase(priceRepo domain.PriceRepository) *ConvertCurrencyUseCase {
-	return &ConvertCurrencyUseCase{
+func NewConvertCurrencyUseCase(priceRepo domain.PriceRepository, opts ...Option) *ConvertCurrencyUseCase {
+	uc := &ConvertCurrencyUseCase{
 		priceRepo: priceRepo,
 	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// ExecuteOption configures a single call of Execute, ExecuteMulti or ExecuteBatch
+type ExecuteOption func(*ExecuteOptions)
+
+// ExecuteOptions holds the settings of one conversion call
+type ExecuteOptions struct {
+	// At selects historical prices for that point in time; zero means the latest
+	At time.Time
+}
+
+// WithAsOf converts at historical prices for the point in time at instead of
+// the latest ones; a zero at keeps the latest prices
+func WithAsOf(at time.Time) ExecuteOption {
+	return func(o *ExecuteOptions) {
+		o.At = at
+	}
+}
+
+// NewExecuteOptions applies opts in order to the default settings
+func NewExecuteOptions(opts ...ExecuteOption) ExecuteOptions {
+	var o ExecuteOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
 }
 
 // Execute performs currency conversion
 func (uc *ConvertCurrencyUseCase) Execute(
 	ctx context.Context,
-	amount float64,
+	amount domain.Amount,
+	fromSymbol, toSymbol string,
+	opts ...ExecuteOption,
+) (*domain.ConversionResult, error) {
+	o := NewExecuteOptions(opts...)
+	if err := validateAt(o.At); err != nil {
+		return nil, err
+	}
+
+	result, err := uc.execute(ctx, amount, fromSymbol, toSymbol, o.At)
+	if err != nil {
+		return nil, err
+	}
+	return uc.round(result), nil
+}
+
+// execute converts amount like Execute but leaves the result unrounded
+func (uc *ConvertCurrencyUseCase) execute(
+	ctx context.Context,
+	amount domain.Amount,
 	fromSymbol, toSymbol string,
+	at time.Time,
 ) (*domain.ConversionResult, error) {
 	// Validate and create currencies
-	fromCurrency, err := domain.NewCurrency(fromSymbol)
+	fromCurrency, err := uc.resolveCurrency(ctx, fromSymbol)
 	if err != nil {
 		return nil, err
 	}
 
-	toCurrency, err := domain.NewCurrency(toSymbol)
+	toCurrency, err := uc.resolveCurrency(ctx, toSymbol)
 	if err != nil {
 		return nil, err
 	}
@@ -40,17 +113,125 @@ func (uc *ConvertCurrencyUseCase) Execute(
 	if err != nil {
 		return nil, err
 	}
+	request.At = at
 
 	// Fetch conversion from repository
-	result, err := uc.priceRepo.GetConversionPrice(
-		ctx,
-		request.Amount,
-		request.FromCurrency.String(),
-		request.ToCurrency.String(),
-	)
+	return uc.convert(ctx, request)
+}
+
+// ExecuteMulti converts one amount into several target currencies. When the
+// repository supports it, all targets are priced with a single upstream call.
+func (uc *ConvertCurrencyUseCase) ExecuteMulti(
+	ctx context.Context,
+	amount domain.Amount,
+	fromSymbol string,
+	toSymbols []string,
+	opts ...ExecuteOption,
+) ([]*domain.ConversionResult, error) {
+	if len(toSymbols) == 0 {
+		return nil, domain.ErrInvalidCurrency
+	}
+	o := NewExecuteOptions(opts...)
+	if err := validateAt(o.At); err != nil {
+		return nil, err
+	}
+
+	fromCurrency, err := uc.resolveCurrency(ctx, fromSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]*domain.ConversionRequest, 0, len(toSymbols))
+	for _, toSymbol := range toSymbols {
+		toCurrency, err := uc.resolveCurrency(ctx, toSymbol)
+		if err != nil {
+			return nil, err
+		}
+
+		request, err := domain.NewConversionRequest(amount, fromCurrency, toCurrency)
+		if err != nil {
+			return nil, err
+		}
+		request.At = o.At
+		requests = append(requests, request)
+	}
+
+	results, err := uc.fetchAll(ctx, requests)
+	if err != nil && len(uc.bridges) > 0 && isRoutable(err) {
+		// Find out which targets need a bridged route
+		results, err = uc.convertEach(ctx, requests)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	for i, result := range results {
+		results[i] = uc.round(result)
+	}
+	return results, nil
+}
+
+// fetchAll prices every request, batching them when the repository allows it
+func (uc *ConvertCurrencyUseCase) fetchAll(
+	ctx context.Context,
+	requests []*domain.ConversionRequest,
+) ([]*domain.ConversionResult, error) {
+	if multi, ok := uc.priceRepo.(domain.MultiPriceRepository); ok && len(requests) > 1 {
+		return multi.GetConversionPrices(ctx, requests)
+	}
+
+	results := make([]*domain.ConversionResult, 0, len(requests))
+	for _, request := range requests {
+		result, err := uc.priceRepo.GetConversionPrice(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// convertEach prices requests one at a time so each can take its own route
+func (uc *ConvertCurrencyUseCase) convertEach(
+	ctx context.Context,
+	requests []*domain.ConversionRequest,
+) ([]*domain.ConversionResult, error) {
+	results := make([]*domain.ConversionResult, 0, len(requests))
+	for _, request := range requests {
+		result, err := uc.convert(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// validateAt rejects as-of timestamps in the future
+func validateAt(at time.Time) error {
+	if at.After(time.Now()) {
+		return fmt.Errorf("%w: %s is in the future", domain.ErrInvalidTimestamp, at.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// resolveCurrency turns user input into a Currency using the configured
+// resolver. Without one, symbols outside the registry are left for the
+// provider to validate.
+func (uc *ConvertCurrencyUseCase) resolveCurrency(ctx context.Context, symbol string) (*domain.Currency, error) {
+	if uc.resolver != nil {
+		return uc.resolver.Resolve(ctx, symbol)
+	}
+	return domain.NewUnlistedCurrency(symbol)
+}
+
+// round applies the configured rounding policy to a copy of the result
+func (uc *ConvertCurrencyUseCase) round(result *domain.ConversionResult) *domain.ConversionResult {
+	if uc.rounding == nil {
+		return result
+	}
+
+	rounded := *result
+	rounded.ConvertedAmount = uc.rounding.Apply(result.ConvertedAmount, result.ToCurrency)
+	return &rounded
 }
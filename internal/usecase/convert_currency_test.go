@@ -15,8 +15,8 @@ type MockPriceRepository struct {
 	mock.Mock
 }
 
-func (m *MockPriceRepository) GetConversionPrice(ctx context.Context, amount float64, from, to string) (*domain.ConversionResult, error) {
-	args := m.Called(ctx, amount, from, to)
+func (m *MockPriceRepository) GetConversionPrice(ctx context.Context, request *domain.ConversionRequest) (*domain.ConversionResult, error) {
+	args := m.Called(ctx, request.Amount, request.FromCurrency.String(), request.ToCurrency.String())
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -26,7 +26,7 @@ func (m *MockPriceRepository) GetConversionPrice(ctx context.Context, amount flo
 func TestConvertCurrencyUseCase_Execute(t *testing.T) {
 	tests := []struct {
 		name        string
-		amount      float64
+		amount      domain.Amount
 		fromSymbol  string
 		toSymbol    string
 		mockSetup   func(*MockPriceRepository)
@@ -35,28 +35,28 @@ func TestConvertCurrencyUseCase_Execute(t *testing.T) {
 	}{
 		{
 			name:       "successful conversion",
-			amount:     100.0,
+			amount:     domain.MustParseAmount("100"),
 			fromSymbol: "USD",
 			toSymbol:   "BTC",
 			mockSetup: func(m *MockPriceRepository) {
 				from, _ := domain.NewCurrency("USD")
 				to, _ := domain.NewCurrency("BTC")
 				result := domain.NewConversionResult(
-					100.0,
-					0.0025,
-					0.000025,
+					domain.MustParseAmount("100"),
+					domain.MustParseAmount("0.0025"),
+					domain.MustParseAmount("0.000025"),
 					from,
 					to,
 					time.Now(),
 					time.Now(),
 				)
-				m.On("GetConversionPrice", mock.Anything, 100.0, "USD", "BTC").Return(result, nil)
+				m.On("GetConversionPrice", mock.Anything, domain.MustParseAmount("100"), "USD", "BTC").Return(result, nil)
 			},
 			wantErr: false,
 		},
 		{
 			name:       "invalid amount (zero)",
-			amount:     0,
+			amount:     domain.Amount{},
 			fromSymbol: "USD",
 			toSymbol:   "BTC",
 			mockSetup: func(m *MockPriceRepository) {
@@ -67,7 +67,7 @@ func TestConvertCurrencyUseCase_Execute(t *testing.T) {
 		},
 		{
 			name:       "invalid amount (negative)",
-			amount:     -50.0,
+			amount:     domain.MustParseAmount("-50"),
 			fromSymbol: "USD",
 			toSymbol:   "BTC",
 			mockSetup: func(m *MockPriceRepository) {
@@ -78,7 +78,7 @@ func TestConvertCurrencyUseCase_Execute(t *testing.T) {
 		},
 		{
 			name:       "invalid from currency",
-			amount:     100.0,
+			amount:     domain.MustParseAmount("100"),
 			fromSymbol: "",
 			toSymbol:   "BTC",
 			mockSetup: func(m *MockPriceRepository) {
@@ -89,7 +89,7 @@ func TestConvertCurrencyUseCase_Execute(t *testing.T) {
 		},
 		{
 			name:       "invalid to currency",
-			amount:     100.0,
+			amount:     domain.MustParseAmount("100"),
 			fromSymbol: "USD",
 			toSymbol:   "X",
 			mockSetup: func(m *MockPriceRepository) {
@@ -100,11 +100,11 @@ func TestConvertCurrencyUseCase_Execute(t *testing.T) {
 		},
 		{
 			name:       "repository returns error",
-			amount:     100.0,
+			amount:     domain.MustParseAmount("100"),
 			fromSymbol: "USD",
 			toSymbol:   "BTC",
 			mockSetup: func(m *MockPriceRepository) {
-				m.On("GetConversionPrice", mock.Anything, 100.0, "USD", "BTC").
+				m.On("GetConversionPrice", mock.Anything, domain.MustParseAmount("100"), "USD", "BTC").
 					Return(nil, domain.ErrRateLimitExceeded)
 			},
 			wantErr:     true,
@@ -131,10 +131,207 @@ func TestConvertCurrencyUseCase_Execute(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
-				assert.Equal(t, tt.amount, result.OriginalAmount)
+				assert.True(t, tt.amount.Equal(result.OriginalAmount))
 			}
 
 			mockRepo.AssertExpectations(t)
 		})
 	}
 }
+
+func TestConvertCurrencyUseCase_ExecuteWithRounding(t *testing.T) {
+	from, _ := domain.NewCurrency("BTC")
+	to, _ := domain.NewCurrency("USD")
+	amount := domain.MustParseAmount("1")
+
+	tests := []struct {
+		name   string
+		policy domain.RoundingPolicy
+		want   string
+	}{
+		{"half-even to currency precision", domain.NewRoundingPolicy(domain.RoundHalfEven), "67000.12"},
+		{"half-up to currency precision", domain.NewRoundingPolicy(domain.RoundHalfUp), "67000.13"},
+		{"down to explicit precision", domain.NewRoundingPolicy(domain.RoundDown).WithPrecision(0), "67000"},
+		{"ceiling to explicit precision", domain.NewRoundingPolicy(domain.RoundCeiling).WithPrecision(1), "67000.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoResult := domain.NewConversionResult(
+				amount,
+				domain.MustParseAmount("67000.125"),
+				domain.MustParseAmount("67000.125"),
+				from, to, time.Now(), time.Now(),
+			)
+			mockRepo := new(MockPriceRepository)
+			mockRepo.On("GetConversionPrice", mock.Anything, amount, "BTC", "USD").Return(repoResult, nil)
+
+			uc := NewConvertCurrencyUseCase(mockRepo, WithRounding(tt.policy))
+			result, err := uc.Execute(context.Background(), amount, "BTC", "USD")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, result.ConvertedAmount.String())
+			assert.Equal(t, "67000.125", repoResult.ConvertedAmount.String(), "repository result must not be mutated")
+		})
+	}
+}
+
+// stubResolver resolves every symbol to a fixed currency
+type stubResolver struct {
+	currencies map[string]*domain.Currency
+}
+
+func (s *stubResolver) Resolve(ctx context.Context, symbol string) (*domain.Currency, error) {
+	if c, ok := s.currencies[symbol]; ok {
+		return c, nil
+	}
+	return nil, domain.ErrInvalidCurrency
+}
+
+func TestConvertCurrencyUseCase_ExecuteWithResolver(t *testing.T) {
+	gala := &domain.Currency{Symbol: "GALA", Kind: domain.CurrencyKindCrypto, Decimals: 8, CMCID: 7080, Pinned: true}
+	usd, _ := domain.NewCurrency("USD")
+	resolver := &stubResolver{currencies: map[string]*domain.Currency{"gala": gala, "usd": usd}}
+	amount := domain.MustParseAmount("10")
+
+	mockRepo := new(MockPriceRepository)
+	result := domain.NewConversionResult(amount, amount, domain.NewAmountFromInt(1), gala, usd, time.Now(), time.Now())
+	mockRepo.On("GetConversionPrice", mock.Anything, amount, "GALA", "USD").Return(result, nil)
+
+	uc := NewConvertCurrencyUseCase(mockRepo, WithCurrencyResolver(resolver))
+
+	got, err := uc.Execute(context.Background(), amount, "gala", "usd")
+	assert.NoError(t, err)
+	assert.Equal(t, 7080, got.FromCurrency.CMCID)
+
+	_, err = uc.Execute(context.Background(), amount, "nope", "usd")
+	assert.ErrorIs(t, err, domain.ErrInvalidCurrency)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConvertCurrencyUseCase_ExecuteUnknownPinnedTarget(t *testing.T) {
+	usd, _ := domain.NewCurrency("USD")
+	unknown, _ := domain.NewCurrency("id:99999")
+	amount := domain.MustParseAmount("100")
+
+	mockRepo := new(MockPriceRepository)
+	result := domain.NewConversionResult(amount, domain.MustParseAmount("0.0123456"), domain.MustParseAmount("0.000123456"), usd, unknown, time.Now(), time.Now())
+	mockRepo.On("GetConversionPrice", mock.Anything, amount, "USD", "id:99999").Return(result, nil)
+
+	uc := NewConvertCurrencyUseCase(mockRepo, WithRounding(domain.NewRoundingPolicy(domain.RoundHalfEven)))
+
+	got, err := uc.Execute(context.Background(), amount, "USD", "id:99999")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0123456", got.ConvertedAmount.Normalize().String())
+}
+
+// MockMultiPriceRepository is a mock implementation of domain.MultiPriceRepository
+type MockMultiPriceRepository struct {
+	MockPriceRepository
+}
+
+func (m *MockMultiPriceRepository) GetConversionPrices(ctx context.Context, requests []*domain.ConversionRequest) ([]*domain.ConversionResult, error) {
+	targets := make([]string, 0, len(requests))
+	for _, r := range requests {
+		targets = append(targets, r.ToCurrency.String())
+	}
+	args := m.Called(ctx, targets)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ConversionResult), args.Error(1)
+}
+
+func TestConvertCurrencyUseCase_ExecuteMulti(t *testing.T) {
+	amount := domain.MustParseAmount("1")
+	btc, _ := domain.NewCurrency("BTC")
+	usd, _ := domain.NewCurrency("USD")
+	eur, _ := domain.NewCurrency("EUR")
+	usdResult := domain.NewConversionResult(amount, domain.MustParseAmount("67000.123"), domain.MustParseAmount("67000.123"), btc, usd, time.Now(), time.Now())
+	eurResult := domain.NewConversionResult(amount, domain.MustParseAmount("61000.456"), domain.MustParseAmount("61000.456"), btc, eur, time.Now(), time.Now())
+
+	t.Run("batched by a multi-target repository", func(t *testing.T) {
+		mockRepo := new(MockMultiPriceRepository)
+		mockRepo.On("GetConversionPrices", mock.Anything, []string{"USD", "EUR"}).
+			Return([]*domain.ConversionResult{usdResult, eurResult}, nil).Once()
+
+		uc := NewConvertCurrencyUseCase(mockRepo, WithRounding(domain.NewRoundingPolicy(domain.RoundHalfEven)))
+		results, err := uc.ExecuteMulti(context.Background(), amount, "BTC", []string{"USD", "EUR"})
+
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+		assert.Equal(t, "67000.12", results[0].ConvertedAmount.String())
+		assert.Equal(t, "61000.46", results[1].ConvertedAmount.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("one call per target otherwise", func(t *testing.T) {
+		mockRepo := new(MockPriceRepository)
+		mockRepo.On("GetConversionPrice", mock.Anything, amount, "BTC", "USD").Return(usdResult, nil).Once()
+		mockRepo.On("GetConversionPrice", mock.Anything, amount, "BTC", "EUR").Return(eurResult, nil).Once()
+
+		uc := NewConvertCurrencyUseCase(mockRepo)
+		results, err := uc.ExecuteMulti(context.Background(), amount, "BTC", []string{"USD", "EUR"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*domain.ConversionResult{usdResult, eurResult}, results)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid target fails before any lookup", func(t *testing.T) {
+		mockRepo := new(MockMultiPriceRepository)
+
+		uc := NewConvertCurrencyUseCase(mockRepo)
+		_, err := uc.ExecuteMulti(context.Background(), amount, "BTC", []string{"USD", "Z"})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidCurrency)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestConvertCurrencyUseCase_ExecuteHistorical(t *testing.T) {
+	amount := domain.MustParseAmount("2")
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("as-of time is passed to the repository", func(t *testing.T) {
+		eth, _ := domain.NewCurrency("ETH")
+		eur, _ := domain.NewCurrency("EUR")
+		result := domain.NewConversionResult(amount, domain.MustParseAmount("6400.5"), domain.MustParseAmount("3200.25"), eth, eur, time.Now(), at)
+		result.AsOf = at
+
+		mockRepo := new(MockPriceRepository)
+		mockRepo.On("GetConversionPrice", mock.Anything, amount, "ETH", "EUR").Return(result, nil).Once()
+
+		var gotAt time.Time
+		repo := &capturingRepository{PriceRepository: mockRepo, at: &gotAt}
+		uc := NewConvertCurrencyUseCase(repo)
+		got, err := uc.Execute(context.Background(), amount, "ETH", "EUR", WithAsOf(at))
+
+		assert.NoError(t, err)
+		assert.Equal(t, at, gotAt)
+		assert.Equal(t, at, got.AsOf)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("future time is rejected", func(t *testing.T) {
+		mockRepo := new(MockPriceRepository)
+
+		uc := NewConvertCurrencyUseCase(mockRepo)
+		_, err := uc.Execute(context.Background(), amount, "ETH", "EUR", WithAsOf(time.Now().Add(time.Hour)))
+
+		assert.ErrorIs(t, err, domain.ErrInvalidTimestamp)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// capturingRepository records the as-of time of the last request it forwards
+type capturingRepository struct {
+	domain.PriceRepository
+	at *time.Time
+}
+
+func (r *capturingRepository) GetConversionPrice(ctx context.Context, request *domain.ConversionRequest) (*domain.ConversionResult, error) {
+	*r.at = request.At
+	return r.PriceRepository.GetConversionPrice(ctx, request)
+}
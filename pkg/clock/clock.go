@@ -0,0 +1,18 @@
+package clock
+
+import "time"
+
+// Clock abstracts time so code that waits can be tested without sleeping
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// System is the Clock backed by package time
+var System Clock = systemClock{}
+
+// systemClock is the Clock backed by package time
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
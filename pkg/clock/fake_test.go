@@ -0,0 +1,21 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+
+	c.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), c.Now())
+
+	fired := <-c.After(time.Second)
+	assert.Equal(t, start.Add(time.Minute+time.Second), fired)
+	assert.Equal(t, fired, c.Now())
+	assert.Equal(t, []time.Duration{time.Second}, c.Waits())
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/pkg/clock"
+)
+
+// TokenBucket is a token-bucket rate limiter. Tokens are added at a steady
+// rate up to the bucket size, and every call takes one. It is safe for
+// concurrent use.
+type TokenBucket struct {
+	interval time.Duration
+	burst    float64
+	clock    clock.Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a limiter allowing perMinute calls per minute on
+// average and up to burst calls at once. The bucket starts full.
+func NewTokenBucket(perMinute, burst int) *TokenBucket {
+	return NewTokenBucketWithClock(perMinute, burst, clock.System)
+}
+
+// NewTokenBucketWithClock creates a limiter like NewTokenBucket driven by clock
+func NewTokenBucketWithClock(perMinute, burst int, clock clock.Clock) *TokenBucket {
+	perMinute = max(perMinute, 1)
+	burst = max(burst, 1)
+	return &TokenBucket{
+		interval: time.Minute / time.Duration(perMinute),
+		burst:    float64(burst),
+		clock:    clock,
+		tokens:   float64(burst),
+		last:     clock.Now(),
+	}
+}
+
+// Wait blocks until a call is allowed or ctx is done
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		wait := b.take()
+		if wait == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("rate limiter: %w", ctx.Err())
+		case <-b.clock.After(wait):
+			// A token should be available now unless another caller took it
+		}
+	}
+}
+
+// Allow takes a token if one is available without waiting
+func (b *TokenBucket) Allow() bool {
+	return b.take() == 0
+}
+
+// take removes a token and returns zero, or returns how long until the next
+// token is added when the bucket is empty
+func (b *TokenBucket) take() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+float64(elapsed)/float64(b.interval))
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(b.interval))
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kerimovkk/currency-conversion-utility/pkg/clock"
+)
+
+func newTestBucket(perMinute, burst int) (*TokenBucket, *clock.Fake) {
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	return NewTokenBucketWithClock(perMinute, burst, fake), fake
+}
+
+func TestTokenBucket_Burst(t *testing.T) {
+	bucket, _ := newTestBucket(30, 3)
+
+	assert.True(t, bucket.Allow())
+	assert.True(t, bucket.Allow())
+	assert.True(t, bucket.Allow())
+	assert.False(t, bucket.Allow())
+}
+
+func TestTokenBucket_Refill(t *testing.T) {
+	bucket, clock := newTestBucket(30, 2)
+	bucket.Allow()
+	bucket.Allow()
+
+	clock.Advance(time.Second)
+	assert.False(t, bucket.Allow(), "half a token after 1s at 30/min")
+
+	clock.Advance(time.Second)
+	assert.True(t, bucket.Allow())
+
+	// Idle time never fills the bucket beyond its burst size
+	clock.Advance(time.Hour)
+	assert.True(t, bucket.Allow())
+	assert.True(t, bucket.Allow())
+	assert.False(t, bucket.Allow())
+}
+
+func TestTokenBucket_Wait(t *testing.T) {
+	bucket, clock := newTestBucket(60, 1)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, bucket.Wait(context.Background()))
+	}
+	assert.Equal(t, []time.Duration{time.Second, time.Second}, clock.Waits())
+}
+
+func TestTokenBucket_WaitCancelled(t *testing.T) {
+	bucket := NewTokenBucket(1, 1)
+	assert.NoError(t, bucket.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := bucket.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestTokenBucket_ConcurrentUse(t *testing.T) {
+	bucket := NewTokenBucket(6000, 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, bucket.Wait(context.Background()))
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,211 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/pkg/clock"
+)
+
+// ErrCircuitOpen is returned without calling the function while the breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State is the state of a CircuitBreaker
+type State int
+
+const (
+	// StateClosed lets every call through while counting failures
+	StateClosed State = iota
+	// StateOpen rejects every call until the cool-down has passed
+	StateOpen
+	// StateHalfOpen lets a limited number of trial calls through
+	StateHalfOpen
+)
+
+// String returns the name of the state
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerSettings defines circuit breaker behavior configuration
+type BreakerSettings struct {
+	// FailureThreshold is the failure rate, between 0 and 1, that opens the breaker
+	FailureThreshold float64
+	// MinRequests is how many calls a window needs before its failure rate counts
+	MinRequests int
+	// Window is how long failures are counted before the counts start over
+	Window time.Duration
+	// Cooldown is how long the breaker stays open before trial calls are let through
+	Cooldown time.Duration
+	// HalfOpenMaxCalls is how many trial calls must succeed to close the breaker again
+	HalfOpenMaxCalls int
+	// Clock tells the time of windows and cool-downs; nil uses the system clock
+	Clock clock.Clock
+}
+
+// DefaultBreakerSettings returns sensible default circuit breaker settings
+// Opens when at least half of 5 or more calls within a minute failed and
+// lets one trial call through after 30s
+func DefaultBreakerSettings() *BreakerSettings {
+	return &BreakerSettings{
+		FailureThreshold: 0.5,
+		MinRequests:      5,
+		Window:           time.Minute,
+		Cooldown:         30 * time.Second,
+		HalfOpenMaxCalls: 1,
+	}
+}
+
+// CircuitBreaker stops calling a failing upstream for a cool-down period
+// instead of letting every caller wait through its own retries. It is safe
+// for concurrent use.
+type CircuitBreaker struct {
+	settings  BreakerSettings
+	isFailure func(err error) bool
+	clock     clock.Clock
+
+	mu          sync.Mutex
+	state       State
+	generation  uint64
+	windowStart time.Time
+	openedAt    time.Time
+	calls       int
+	failures    int
+	trials      int
+	successes   int
+}
+
+// NewCircuitBreaker creates a closed circuit breaker. isFailure decides which
+// errors count against the upstream; nil counts every error.
+func NewCircuitBreaker(settings *BreakerSettings, isFailure func(err error) bool) *CircuitBreaker {
+	if isFailure == nil {
+		isFailure = func(error) bool { return true }
+	}
+	c := settings.Clock
+	if c == nil {
+		c = clock.System
+	}
+	return &CircuitBreaker{
+		settings:  *settings,
+		isFailure: isFailure,
+		clock:     c,
+	}
+}
+
+// State returns the current state, moving from open to half-open once the
+// cool-down has passed
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(b.clock.Now())
+	return b.state
+}
+
+// Do calls fn unless the breaker is open, in which case ErrCircuitOpen is
+// returned immediately, and records the outcome
+func (b *CircuitBreaker) Do(ctx context.Context, fn RetryableFunc) error {
+	generation, err := b.before()
+	if err != nil {
+		return err
+	}
+
+	err = fn(ctx)
+	b.after(generation, err)
+	return err
+}
+
+// before admits a call and returns the generation it belongs to
+func (b *CircuitBreaker) before() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(b.clock.Now())
+	switch b.state {
+	case StateOpen:
+		return 0, ErrCircuitOpen
+	case StateHalfOpen:
+		if b.trials >= b.settings.HalfOpenMaxCalls {
+			return 0, ErrCircuitOpen
+		}
+		b.trials++
+	}
+	return b.generation, nil
+}
+
+// after records the outcome of a call admitted in generation. Outcomes of
+// calls admitted before the last state change are ignored.
+func (b *CircuitBreaker) after(generation uint64, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	b.advance(now)
+	if generation != b.generation {
+		return
+	}
+
+	failed := err != nil && b.isFailure(err)
+	switch b.state {
+	case StateClosed:
+		b.calls++
+		if failed {
+			b.failures++
+		}
+		if b.calls >= b.settings.MinRequests &&
+			float64(b.failures)/float64(b.calls) >= b.settings.FailureThreshold {
+			b.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		if failed {
+			b.setState(StateOpen, now)
+			return
+		}
+		b.successes++
+		if b.successes >= b.settings.HalfOpenMaxCalls {
+			b.setState(StateClosed, now)
+		}
+	}
+}
+
+// advance applies time-based transitions; callers hold b.mu
+func (b *CircuitBreaker) advance(now time.Time) {
+	switch b.state {
+	case StateClosed:
+		if b.settings.Window > 0 && now.Sub(b.windowStart) >= b.settings.Window {
+			b.resetCounts(now)
+		}
+	case StateOpen:
+		if now.Sub(b.openedAt) >= b.settings.Cooldown {
+			b.setState(StateHalfOpen, now)
+		}
+	}
+}
+
+// setState switches to state and starts a new generation; callers hold b.mu
+func (b *CircuitBreaker) setState(state State, now time.Time) {
+	b.state = state
+	b.generation++
+	b.resetCounts(now)
+	if state == StateOpen {
+		b.openedAt = now
+	}
+}
+
+// resetCounts starts a new counting window; callers hold b.mu
+func (b *CircuitBreaker) resetCounts(now time.Time) {
+	b.windowStart = now
+	b.calls, b.failures = 0, 0
+	b.trials, b.successes = 0, 0
+}
@@ -0,0 +1,200 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kerimovkk/currency-conversion-utility/pkg/clock"
+)
+
+func newTestBreaker(isFailure func(error) bool) (*CircuitBreaker, *clock.Fake) {
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	breaker := NewCircuitBreaker(&BreakerSettings{
+		FailureThreshold: 0.5,
+		MinRequests:      4,
+		Window:           time.Minute,
+		Cooldown:         10 * time.Second,
+		HalfOpenMaxCalls: 2,
+		Clock:            fake,
+	}, isFailure)
+	return breaker, fake
+}
+
+func call(breaker *CircuitBreaker, err error) (bool, error) {
+	called := false
+	got := breaker.Do(context.Background(), func(ctx context.Context) error {
+		called = true
+		return err
+	})
+	return called, got
+}
+
+func TestCircuitBreaker_OpensAtFailureRate(t *testing.T) {
+	breaker, _ := newTestBreaker(nil)
+	testErr := errors.New("upstream down")
+
+	call(breaker, nil)
+	call(breaker, testErr)
+	call(breaker, nil)
+	assert.Equal(t, StateClosed, breaker.State(), "too few calls to judge")
+
+	call(breaker, testErr)
+	assert.Equal(t, StateOpen, breaker.State())
+
+	called, err := call(breaker, nil)
+	assert.False(t, called)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_StaysClosedBelowThreshold(t *testing.T) {
+	breaker, _ := newTestBreaker(nil)
+	testErr := errors.New("upstream down")
+
+	call(breaker, testErr)
+	for i := 0; i < 5; i++ {
+		call(breaker, nil)
+	}
+	assert.Equal(t, StateClosed, breaker.State())
+}
+
+func TestCircuitBreaker_IgnoresErrorsThatAreNotFailures(t *testing.T) {
+	badInput := errors.New("bad input")
+	breaker, _ := newTestBreaker(func(err error) bool { return err != badInput })
+
+	for i := 0; i < 10; i++ {
+		_, err := call(breaker, badInput)
+		assert.Equal(t, badInput, err)
+	}
+	assert.Equal(t, StateClosed, breaker.State())
+}
+
+func TestCircuitBreaker_WindowStartsOver(t *testing.T) {
+	breaker, clock := newTestBreaker(nil)
+	testErr := errors.New("upstream down")
+
+	call(breaker, testErr)
+	call(breaker, testErr)
+	clock.Advance(time.Minute)
+	call(breaker, testErr)
+	call(breaker, nil)
+	call(breaker, nil)
+	call(breaker, nil)
+
+	assert.Equal(t, StateClosed, breaker.State())
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	testErr := errors.New("upstream down")
+	open := func() (*CircuitBreaker, *clock.Fake) {
+		breaker, clock := newTestBreaker(nil)
+		for i := 0; i < 4; i++ {
+			call(breaker, testErr)
+		}
+		return breaker, clock
+	}
+
+	t.Run("closes after successful trials", func(t *testing.T) {
+		breaker, clock := open()
+		clock.Advance(9 * time.Second)
+		assert.Equal(t, StateOpen, breaker.State())
+
+		clock.Advance(time.Second)
+		assert.Equal(t, StateHalfOpen, breaker.State())
+
+		called, err := call(breaker, nil)
+		assert.True(t, called)
+		assert.NoError(t, err)
+		assert.Equal(t, StateHalfOpen, breaker.State())
+
+		call(breaker, nil)
+		assert.Equal(t, StateClosed, breaker.State())
+	})
+
+	t.Run("reopens on a failed trial", func(t *testing.T) {
+		breaker, clock := open()
+		clock.Advance(10 * time.Second)
+
+		call(breaker, testErr)
+		assert.Equal(t, StateOpen, breaker.State())
+
+		called, _ := call(breaker, nil)
+		assert.False(t, called)
+	})
+
+	t.Run("limits concurrent trials", func(t *testing.T) {
+		breaker, clock := open()
+		clock.Advance(10 * time.Second)
+
+		release := make(chan struct{})
+		var started sync.WaitGroup
+		var finished sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			started.Add(1)
+			finished.Add(1)
+			go func() {
+				defer finished.Done()
+				_ = breaker.Do(context.Background(), func(ctx context.Context) error {
+					started.Done()
+					<-release
+					return nil
+				})
+			}()
+		}
+		started.Wait()
+
+		called, err := call(breaker, nil)
+		assert.False(t, called)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+
+		close(release)
+		finished.Wait()
+		assert.Equal(t, StateClosed, breaker.State())
+	})
+}
+
+func TestCircuitBreaker_IgnoresOutcomesFromEarlierState(t *testing.T) {
+	breaker, _ := newTestBreaker(nil)
+	testErr := errors.New("upstream down")
+
+	// A slow call admitted while closed finishes after the breaker opened
+	_ = breaker.Do(context.Background(), func(ctx context.Context) error {
+		for i := 0; i < 4; i++ {
+			call(breaker, testErr)
+		}
+		return nil
+	})
+
+	assert.Equal(t, StateOpen, breaker.State())
+}
+
+func TestCircuitBreaker_ConcurrentUse(t *testing.T) {
+	breaker := NewCircuitBreaker(DefaultBreakerSettings(), nil)
+	testErr := errors.New("upstream down")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = breaker.Do(context.Background(), func(ctx context.Context) error {
+				if i%2 == 0 {
+					return testErr
+				}
+				return nil
+			})
+			_ = breaker.State()
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestState_String(t *testing.T) {
+	assert.Equal(t, "closed", StateClosed.String())
+	assert.Equal(t, "open", StateOpen.String())
+	assert.Equal(t, "half-open", StateHalfOpen.String())
+}
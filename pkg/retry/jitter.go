@@ -0,0 +1,67 @@
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Jitter selects how retry delays are randomized
+type Jitter int
+
+const (
+	// NoJitter waits exactly the exponential backoff
+	NoJitter Jitter = iota
+	// FullJitter waits a random delay between zero and the backoff
+	FullJitter
+	// EqualJitter waits half the backoff plus a random share of the other half
+	EqualJitter
+	// DecorrelatedJitter waits a random delay between InitialDelay and three
+	// times the previous delay, independent of the attempt number
+	DecorrelatedJitter
+)
+
+// String returns the name of the jitter mode
+func (j Jitter) String() string {
+	switch j {
+	case NoJitter:
+		return "none"
+	case FullJitter:
+		return "full"
+	case EqualJitter:
+		return "equal"
+	case DecorrelatedJitter:
+		return "decorrelated"
+	default:
+		return "unknown"
+	}
+}
+
+// nextDelay returns the delay before the attempt after attempt, given the
+// delay waited before it
+func (s *Strategy) nextDelay(attempt int, previous time.Duration) time.Duration {
+	switch s.Jitter {
+	case FullJitter:
+		return s.randomShare(s.calculateDelay(attempt))
+	case EqualJitter:
+		backoff := s.calculateDelay(attempt)
+		return backoff/2 + s.randomShare(backoff-backoff/2)
+	case DecorrelatedJitter:
+		previous = max(previous, s.InitialDelay)
+		upper := min(3*previous, s.MaxDelay)
+		if upper <= s.InitialDelay {
+			return upper
+		}
+		return s.InitialDelay + s.randomShare(upper-s.InitialDelay)
+	default:
+		return s.calculateDelay(attempt)
+	}
+}
+
+// randomShare returns a random duration between zero and d
+func (s *Strategy) randomShare(d time.Duration) time.Duration {
+	r := rand.Float64
+	if s.Rand != nil {
+		r = s.Rand
+	}
+	return time.Duration(float64(d) * r())
+}
@@ -0,0 +1,122 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kerimovkk/currency-conversion-utility/pkg/clock"
+)
+
+// sequence returns a random source yielding values in turn, repeating the last one
+func sequence(values ...float64) func() float64 {
+	i := 0
+	return func() float64 {
+		v := values[min(i, len(values)-1)]
+		i++
+		return v
+	}
+}
+
+func TestStrategy_nextDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		jitter Jitter
+		rand   []float64
+		want   []time.Duration
+	}{
+		{"none", NoJitter, []float64{0.5}, []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}},
+		{"full", FullJitter, []float64{0.5, 0, 0.25, 0.99, 0.5}, []time.Duration{500 * time.Millisecond, 0, time.Second, 7920 * time.Millisecond, 5 * time.Second}},
+		{"equal", EqualJitter, []float64{0, 1, 0.5, 0, 0.5}, []time.Duration{500 * time.Millisecond, 2 * time.Second, 3 * time.Second, 4 * time.Second, 7500 * time.Millisecond}},
+		{"decorrelated", DecorrelatedJitter, []float64{1, 1, 0, 0.5, 1}, []time.Duration{3 * time.Second, 9 * time.Second, 1 * time.Second, 2 * time.Second, 6 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := &Strategy{
+				InitialDelay: 1 * time.Second,
+				MaxDelay:     10 * time.Second,
+				Multiplier:   2.0,
+				Jitter:       tt.jitter,
+				Rand:         sequence(tt.rand...),
+			}
+
+			var previous time.Duration
+			for attempt, want := range tt.want {
+				previous = strategy.nextDelay(attempt, previous)
+				assert.Equal(t, want, previous, "attempt %d", attempt)
+			}
+		})
+	}
+}
+
+func TestStrategy_nextDelayStaysWithinBounds(t *testing.T) {
+	for _, jitter := range []Jitter{FullJitter, EqualJitter, DecorrelatedJitter} {
+		strategy := &Strategy{
+			InitialDelay: 100 * time.Millisecond,
+			MaxDelay:     time.Second,
+			Multiplier:   2.0,
+			Jitter:       jitter,
+		}
+
+		var previous time.Duration
+		for attempt := 0; attempt < 50; attempt++ {
+			previous = strategy.nextDelay(attempt, previous)
+			assert.GreaterOrEqual(t, previous, time.Duration(0), jitter.String())
+			assert.LessOrEqual(t, previous, strategy.MaxDelay, jitter.String())
+		}
+	}
+}
+
+func TestDo_ReportsRetries(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	testErr := errors.New("test error")
+
+	type retryEvent struct {
+		attempt int
+		err     error
+		delay   time.Duration
+	}
+	var fromStrategy, fromContext []retryEvent
+
+	strategy := &Strategy{
+		MaxAttempts:  3,
+		InitialDelay: time.Second,
+		MaxDelay:     time.Minute,
+		Multiplier:   2.0,
+		Jitter:       FullJitter,
+		Rand:         sequence(0.5),
+		Clock:        fake,
+		OnRetry: func(attempt int, err error, delay time.Duration) {
+			fromStrategy = append(fromStrategy, retryEvent{attempt, err, delay})
+		},
+	}
+	ctx := WithOnRetry(context.Background(), func(attempt int, err error, delay time.Duration) {
+		fromContext = append(fromContext, retryEvent{attempt, err, delay})
+	})
+
+	start := time.Now()
+	err := Do(ctx, strategy, func(err error) bool { return true }, func(ctx context.Context) error {
+		return testErr
+	})
+
+	assert.ErrorIs(t, err, testErr)
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "the fake clock does not sleep")
+	want := []retryEvent{
+		{1, testErr, 500 * time.Millisecond},
+		{2, testErr, time.Second},
+	}
+	assert.Equal(t, want, fromStrategy)
+	assert.Equal(t, want, fromContext)
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Second}, fake.Waits())
+}
+
+func TestJitter_String(t *testing.T) {
+	assert.Equal(t, "none", NoJitter.String())
+	assert.Equal(t, "full", FullJitter.String())
+	assert.Equal(t, "equal", EqualJitter.String())
+	assert.Equal(t, "decorrelated", DecorrelatedJitter.String())
+}
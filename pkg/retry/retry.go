@@ -2,9 +2,12 @@ package retry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"time"
+
+	"github.com/kerimovkk/currency-conversion-utility/pkg/clock"
 )
 
 // Strategy defines retry behavior configuration
@@ -13,6 +16,22 @@ type Strategy struct {
 	InitialDelay time.Duration
 	MaxDelay     time.Duration
 	Multiplier   float64
+
+	// Jitter randomizes delays so concurrent callers do not retry in lockstep
+	Jitter Jitter
+
+	// Rand returns a random number in [0, 1) for jitter; nil uses math/rand/v2
+	Rand func() float64
+
+	// Clock waits out the delays; nil uses the system clock
+	Clock clock.Clock
+
+	// OnRetry is called before waiting for the next attempt
+	OnRetry OnRetryFunc
+
+	// AttemptTimeout bounds every single attempt so one hung request leaves
+	// time for the others; zero lets attempts use the whole context
+	AttemptTimeout time.Duration
 }
 
 // DefaultStrategy returns a sensible default retry strategy
@@ -27,28 +46,94 @@ func DefaultStrategy() *Strategy {
 	}
 }
 
+// OnRetryFunc observes a failed attempt, numbered from 1, that is about to be
+// retried after delay
+type OnRetryFunc func(attempt int, err error, delay time.Duration)
+
+// onRetryKey is the context key of the observer set by WithOnRetry
+type onRetryKey struct{}
+
+// WithOnRetry returns a context whose retries are reported to fn in addition
+// to the strategy's own OnRetry, for callers that do not own the strategy
+func WithOnRetry(ctx context.Context, fn OnRetryFunc) context.Context {
+	return context.WithValue(ctx, onRetryKey{}, fn)
+}
+
 // RetryableFunc is a function that can be retried
 type RetryableFunc func(ctx context.Context) error
 
 // ShouldRetryFunc determines if an error should trigger a retry
 type ShouldRetryFunc func(err error) bool
 
+// DelayHinter is implemented by errors that carry a server-suggested delay
+// before the next attempt, such as one taken from a Retry-After header
+type DelayHinter interface {
+	RetryAfter() time.Duration
+}
+
+// After attaches a server-suggested delay to err. Do waits that long before
+// the next attempt instead of its computed backoff, capped by MaxDelay.
+func After(err error, delay time.Duration) error {
+	if err == nil || delay <= 0 {
+		return err
+	}
+	return &delayError{err: err, delay: delay}
+}
+
+// delayError is an error carrying a server-suggested delay
+type delayError struct {
+	err   error
+	delay time.Duration
+}
+
+// Error returns the message of the wrapped error
+func (e *delayError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *delayError) Unwrap() error {
+	return e.err
+}
+
+// RetryAfter returns the suggested delay
+func (e *delayError) RetryAfter() time.Duration {
+	return e.delay
+}
+
+// ValueFunc is a value-returning function that can be retried
+type ValueFunc[T any] func(ctx context.Context) (T, error)
+
+// ErrAttemptTimeout marks an attempt cut short by Strategy.AttemptTimeout
+var ErrAttemptTimeout = errors.New("attempt timed out")
+
 // Do executes the given function with retry logic
 func Do(ctx context.Context, strategy *Strategy, shouldRetry ShouldRetryFunc, fn RetryableFunc) error {
+	_, err := DoValue(ctx, strategy, shouldRetry, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// DoValue executes the given function with retry logic and returns the value
+// of the first successful attempt. Errors wrap the last attempt's error.
+func DoValue[T any](ctx context.Context, strategy *Strategy, shouldRetry ShouldRetryFunc, fn ValueFunc[T]) (T, error) {
+	var zero T
 	var lastErr error
+	var delay time.Duration
 
 	for attempt := 0; attempt < strategy.MaxAttempts; attempt++ {
 		// Execute the function
-		err := fn(ctx)
+		value, err := runAttempt(ctx, strategy, fn)
 		if err == nil {
-			return nil // Success
+			return value, nil // Success
 		}
 
 		lastErr = err
 
-		// Check if we should retry
-		if !shouldRetry(err) {
-			return err // Don't retry, return error immediately
+		// Check if we should retry; a timed out attempt always may
+		if !errors.Is(err, ErrAttemptTimeout) && !shouldRetry(err) {
+			return zero, err // Don't retry, return error immediately
 		}
 
 		// Don't sleep after the last attempt
@@ -56,29 +141,77 @@ func Do(ctx context.Context, strategy *Strategy, shouldRetry ShouldRetryFunc, fn
 			break
 		}
 
-		// Calculate delay with exponential backoff
-		delay := strategy.calculateDelay(attempt)
+		// Calculate delay with jittered exponential backoff unless the server suggested one
+		delay = strategy.nextDelay(attempt, delay)
+		if suggested, ok := suggestedDelay(err); ok {
+			delay = min(suggested, strategy.MaxDelay)
+		}
+		strategy.notify(ctx, attempt+1, err, delay)
 
 		// Check if context is cancelled before sleeping
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("retry cancelled: %w", ctx.Err())
-		case <-time.After(delay):
+			return zero, fmt.Errorf("retry cancelled: %w", errors.Join(ctx.Err(), lastErr))
+		case <-strategy.clock().After(delay):
 			// Continue to next attempt
 		}
 	}
 
-	return fmt.Errorf("max retry attempts (%d) exceeded: %w", strategy.MaxAttempts, lastErr)
+	return zero, fmt.Errorf("max retry attempts (%d) exceeded: %w", strategy.MaxAttempts, lastErr)
+}
+
+// runAttempt runs fn once, bounded by the strategy's AttemptTimeout when one is set
+func runAttempt[T any](ctx context.Context, s *Strategy, fn ValueFunc[T]) (T, error) {
+	if s.AttemptTimeout <= 0 {
+		return fn(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, s.AttemptTimeout)
+	defer cancel()
+
+	value, err := fn(attemptCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w after %s: %w", ErrAttemptTimeout, s.AttemptTimeout, err)
+	}
+	return value, err
+}
+
+// notify reports a retry to the strategy's and the context's observers
+func (s *Strategy) notify(ctx context.Context, attempt int, err error, delay time.Duration) {
+	if s.OnRetry != nil {
+		s.OnRetry(attempt, err, delay)
+	}
+	if fn, ok := ctx.Value(onRetryKey{}).(OnRetryFunc); ok && fn != nil {
+		fn(attempt, err, delay)
+	}
+}
+
+// clock returns the configured Clock or the system clock
+func (s *Strategy) clock() clock.Clock {
+	if s.Clock != nil {
+		return s.Clock
+	}
+	return clock.System
+}
+
+// suggestedDelay returns the server-suggested delay carried by err, if any
+func suggestedDelay(err error) (time.Duration, bool) {
+	var hinter DelayHinter
+	if !errors.As(err, &hinter) {
+		return 0, false
+	}
+	delay := hinter.RetryAfter()
+	return delay, delay > 0
 }
 
 // calculateDelay calculates the delay for a given attempt using exponential backoff
 func (s *Strategy) calculateDelay(attempt int) time.Duration {
 	delay := float64(s.InitialDelay) * math.Pow(s.Multiplier, float64(attempt))
-	delayDuration := time.Duration(delay)
 
-	if delayDuration > s.MaxDelay {
-		delayDuration = s.MaxDelay
+	// Compare before converting, as large attempts overflow time.Duration
+	if delay > float64(s.MaxDelay) {
+		return s.MaxDelay
 	}
 
-	return delayDuration
+	return time.Duration(delay)
 }
@@ -3,12 +3,23 @@ package retry
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func TestDefaultStrategy_IsDeterministic(t *testing.T) {
+	strategy := DefaultStrategy()
+
+	assert.Equal(t, NoJitter, strategy.Jitter)
+	assert.Zero(t, strategy.AttemptTimeout)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, []time.Duration{
+		strategy.nextDelay(0, 0), strategy.nextDelay(1, 0), strategy.nextDelay(2, 0),
+	})
+}
+
 func TestDo_SuccessFirstAttempt(t *testing.T) {
 	strategy := DefaultStrategy()
 	callCount := 0
@@ -143,3 +154,180 @@ func TestStrategy_calculateDelay(t *testing.T) {
 		assert.Equal(t, tt.want, got, "attempt %d", tt.attempt)
 	}
 }
+
+func TestDo_HonorsSuggestedDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		backoff   time.Duration
+		suggested time.Duration
+		minWait   time.Duration
+		maxWait   time.Duration
+	}{
+		{"longer than backoff", 10 * time.Millisecond, 60 * time.Millisecond, 60 * time.Millisecond, 150 * time.Millisecond},
+		{"capped by MaxDelay", 10 * time.Millisecond, time.Hour, 80 * time.Millisecond, 200 * time.Millisecond},
+		{"shorter than backoff", 80 * time.Millisecond, time.Millisecond, 0, 40 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := &Strategy{
+				MaxAttempts:  2,
+				InitialDelay: tt.backoff,
+				MaxDelay:     80 * time.Millisecond,
+				Multiplier:   2.0,
+			}
+
+			testErr := errors.New("rate limited")
+			callCount := 0
+			start := time.Now()
+
+			err := Do(context.Background(), strategy, func(err error) bool {
+				return errors.Is(err, testErr)
+			}, func(ctx context.Context) error {
+				callCount++
+				if callCount == 1 {
+					return After(testErr, tt.suggested)
+				}
+				return nil
+			})
+
+			elapsed := time.Since(start)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, callCount)
+			assert.GreaterOrEqual(t, elapsed, tt.minWait)
+			assert.Less(t, elapsed, tt.maxWait)
+		})
+	}
+}
+
+func TestAfter(t *testing.T) {
+	testErr := errors.New("rate limited")
+
+	err := After(testErr, 3*time.Second)
+	assert.ErrorIs(t, err, testErr)
+	assert.Equal(t, testErr.Error(), err.Error())
+
+	delay, ok := suggestedDelay(fmt.Errorf("wrapped: %w", err))
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, delay)
+
+	assert.Equal(t, testErr, After(testErr, 0))
+	assert.NoError(t, After(nil, time.Second))
+	_, ok = suggestedDelay(testErr)
+	assert.False(t, ok)
+}
+
+func TestDoValue(t *testing.T) {
+	strategy := &Strategy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+	testErr := errors.New("test error")
+
+	t.Run("returns the first successful value", func(t *testing.T) {
+		callCount := 0
+		value, err := DoValue(context.Background(), strategy, func(err error) bool {
+			return errors.Is(err, testErr)
+		}, func(ctx context.Context) (string, error) {
+			callCount++
+			if callCount < 2 {
+				return "partial", testErr
+			}
+			return "done", nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "done", value)
+		assert.Equal(t, 2, callCount)
+	})
+
+	t.Run("wraps the last error", func(t *testing.T) {
+		callCount := 0
+		value, err := DoValue(context.Background(), strategy, func(err error) bool {
+			return true
+		}, func(ctx context.Context) (int, error) {
+			callCount++
+			return callCount, fmt.Errorf("attempt %d: %w", callCount, testErr)
+		})
+
+		assert.ErrorIs(t, err, testErr)
+		assert.Contains(t, err.Error(), "attempt 3")
+		assert.Zero(t, value)
+	})
+
+	t.Run("keeps the last error when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		slow := &Strategy{MaxAttempts: 3, InitialDelay: time.Hour, MaxDelay: time.Hour, Multiplier: 1}
+
+		_, err := DoValue(ctx, slow, func(err error) bool {
+			return true
+		}, func(ctx context.Context) (int, error) {
+			cancel()
+			return 0, testErr
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, err, testErr)
+	})
+}
+
+func TestDo_AttemptTimeout(t *testing.T) {
+	strategy := &Strategy{
+		MaxAttempts:    3,
+		InitialDelay:   time.Millisecond,
+		MaxDelay:       time.Millisecond,
+		Multiplier:     1,
+		AttemptTimeout: 20 * time.Millisecond,
+	}
+	hangErr := errors.New("hung")
+
+	callCount := 0
+	value, err := DoValue(context.Background(), strategy, func(err error) bool {
+		return false // only the attempt timeout makes this retry
+	}, func(ctx context.Context) (string, error) {
+		callCount++
+		if callCount == 1 {
+			<-ctx.Done()
+			return "", fmt.Errorf("%w: %w", hangErr, ctx.Err())
+		}
+		return "answered", nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "answered", value)
+	assert.Equal(t, 2, callCount)
+
+	_, err = DoValue(context.Background(), strategy, func(err error) bool {
+		return false
+	}, func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", fmt.Errorf("%w: %w", hangErr, ctx.Err())
+	})
+
+	assert.ErrorIs(t, err, ErrAttemptTimeout)
+	assert.ErrorIs(t, err, hangErr)
+	assert.Contains(t, err.Error(), "max retry attempts (3) exceeded")
+}
+
+func TestDo_AttemptTimeoutLeavesParentDeadline(t *testing.T) {
+	strategy := &Strategy{
+		MaxAttempts:    3,
+		InitialDelay:   time.Millisecond,
+		MaxDelay:       time.Millisecond,
+		Multiplier:     1,
+		AttemptTimeout: time.Hour,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	callCount := 0
+	err := Do(ctx, strategy, func(err error) bool {
+		return false
+	}, func(ctx context.Context) error {
+		callCount++
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	// The overall deadline is not an attempt timeout, so nothing is retried
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrAttemptTimeout)
+	assert.Equal(t, 1, callCount)
+}